@@ -4,16 +4,17 @@ go 1.20
 
 require (
 	cloud.google.com/go/spanner v1.46.0
+	github.com/Jumpaku/go-assert v1.0.0
 	github.com/mattn/go-sqlite3 v1.14.16
+	github.com/samber/lo v1.38.1
 	golang.org/x/exp v0.0.0-20230519143937-03e91628a987
 	golang.org/x/sync v0.2.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	cloud.google.com/go/iam v0.13.0 // indirect
 	cloud.google.com/go/longrunning v0.4.1 // indirect
-	github.com/Jumpaku/go-assert v1.0.0 // indirect
-	github.com/samber/lo v1.38.1 // indirect
 	github.com/thoas/go-funk v0.9.3 // indirect
 	google.golang.org/api v0.125.0 // indirect
 )
@@ -43,7 +44,7 @@ require (
 	golang.org/x/text v0.9.0 // indirect
 	golang.org/x/xerrors v0.0.0-20220907171357-04be3eba64a2 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
-	google.golang.org/genproto v0.0.0-20230530153820-e85fd2cbaebc // indirect
+	google.golang.org/genproto v0.0.0-20230530153820-e85fd2cbaebc
 	google.golang.org/genproto/googleapis/api v0.0.0-20230530153820-e85fd2cbaebc // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20230530153820-e85fd2cbaebc // indirect
 	google.golang.org/grpc v1.55.0 // indirect
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/anonymize"
+	gotaface_spanner "github.com/Jumpaku/gotaface/old/spanner"
+	dbdump_spanner "github.com/Jumpaku/gotaface/old/spanner/cli/dbdump"
+	dbinsert_spanner "github.com/Jumpaku/gotaface/old/spanner/cli/dbinsert"
+	gotaface_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3"
+	dbdump_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3/cli/dbdump"
+	dbinsert_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3/cli/dbinsert"
+)
+
+//go:embed README.md
+var Usage string
+
+func main() {
+	cmd := flag.NewFlagSet("gf-dbcopy", flag.ExitOnError)
+	cmd.Usage = func() { fmt.Println(Usage) }
+
+	srcSchema := cmd.String(`src-schema`, ``, `path of schema cache file of source data source`)
+	dstSchema := cmd.String(`dst-schema`, ``, `path of schema cache file of destination data source`)
+	anonymizeConfig := cmd.String(`anonymize`, ``, `path of anonymization config file`)
+
+	if err := cmd.Parse(os.Args[1:]); err != nil {
+		log.Fatalf(`cannot parse command line arguments: %v`, err)
+	}
+
+	srcSchemaReader, srcSchemaWriter, closeSrc, err := OpenSchemaCache(*srcSchema)
+	if err != nil {
+		log.Fatalf(`fail to open schema cache of source: %v`, err)
+	}
+	defer closeSrc()
+
+	dstSchemaReader, dstSchemaWriter, closeDst, err := OpenSchemaCache(*dstSchema)
+	if err != nil {
+		log.Fatalf(`fail to open schema cache of destination: %v`, err)
+	}
+	defer closeDst()
+
+	args := cmd.Args()
+	if len(args) != 4 {
+		log.Fatalln(`positional arguments <src-driver>, <src-data-source>, <dst-driver>, and <dst-data-source> are required`)
+	}
+
+	var anonymizer Anonymizer
+	if *anonymizeConfig != `` {
+		anonymizer, err = LoadAnonymizer(*anonymizeConfig)
+		if err != nil {
+			log.Fatalf(`fail to load anonymization config: %v`, err)
+		}
+	}
+
+	err = Runner{
+		srcDriver:       args[0],
+		srcDataSource:   args[1],
+		srcSchemaReader: srcSchemaReader,
+		srcSchemaWriter: srcSchemaWriter,
+		dstDriver:       args[2],
+		dstDataSource:   args[3],
+		dstSchemaReader: dstSchemaReader,
+		dstSchemaWriter: dstSchemaWriter,
+		anonymizer:      anonymizer,
+	}.Run(context.Background(), os.Stdin, os.Stdout)
+	if err != nil {
+		log.Fatalf(`failed execution: %v`, err)
+	}
+}
+
+type DBDumpInput = []string
+type DBDumpOutput = map[string]dml.Rows
+type DBDumpFunc func(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer, input DBDumpInput) (DBDumpOutput, error)
+
+type InsertRows = interface {
+	Name() string
+	Rows() dml.Rows
+}
+type DBInsertInput = interface {
+	Len() int
+	Get(i int) InsertRows
+}
+type DBInsertFunc func(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer, input DBInsertInput) error
+
+// FromDBValueFunc converts a value dumped from the source into a plain Go value, which is converted into a value of the destination by the column type.
+type FromDBValueFunc func(src any) (any, error)
+
+type Anonymizer interface {
+	Anonymize(table string, rows dml.Rows) (dml.Rows, error)
+}
+
+type Runner struct {
+	srcDriver       string
+	srcDataSource   string
+	srcSchemaReader io.Reader
+	srcSchemaWriter io.Writer
+	dstDriver       string
+	dstDataSource   string
+	dstSchemaReader io.Reader
+	dstSchemaWriter io.Writer
+	anonymizer      Anonymizer
+}
+
+func LoadAnonymizer(config string) (Anonymizer, error) {
+	f, err := os.Open(config)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to open %s: %w`, config, err)
+	}
+	defer f.Close()
+
+	var c anonymize.Config
+	d := json.NewDecoder(f)
+	d.DisallowUnknownFields()
+	d.UseNumber()
+	if err := d.Decode(&c); err != nil {
+		return nil, fmt.Errorf(`fail to decode JSON from %s: %w`, config, err)
+	}
+
+	anonymizer, err := anonymize.NewAnonymizer(c)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid anonymization config %s: %w`, config, err)
+	}
+	return anonymizer, nil
+}
+
+// OpenSchemaCache returns a reader of the schema cache file if it exists, otherwise a writer to create the file.
+// If schema is empty, the schema is fetched without the cache file.
+func OpenSchemaCache(schema string) (io.Reader, io.Writer, func(), error) {
+	if schema == `` {
+		return nil, io.Discard, func() {}, nil
+	}
+
+	fi, err := os.Stat(schema)
+	if errors.Is(err, os.ErrNotExist) {
+		f, err := os.Create(schema)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf(`fail to create %s: %w`, schema, err)
+		}
+		return nil, f, func() { f.Close() }, nil
+	} else if err != nil {
+		return nil, nil, nil, fmt.Errorf(`fail to open %s: %w`, schema, err)
+	} else if fi.IsDir() {
+		return nil, nil, nil, fmt.Errorf(`%s must be a file`, schema)
+	}
+
+	b, err := os.ReadFile(schema)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf(`fail to read %s: %w`, schema, err)
+	}
+
+	return bytes.NewBuffer(b), nil, func() {}, nil
+}
+
+type dbInsertRows struct {
+	name string
+	rows dml.Rows
+}
+
+func (r dbInsertRows) Name() string {
+	return r.name
+}
+func (r dbInsertRows) Rows() dml.Rows {
+	return r.rows
+}
+
+type dbInsertInput []dbInsertRows
+
+func (i dbInsertInput) Len() int {
+	return len(i)
+}
+func (i dbInsertInput) Get(index int) InsertRows {
+	return i[index]
+}
+
+func (runner Runner) Run(ctx context.Context, stdin io.Reader, stdout io.Writer) error {
+	var dbDumpFunc DBDumpFunc
+	var fromDBValueFunc FromDBValueFunc
+	switch runner.srcDriver {
+	default:
+		return fmt.Errorf(`unsupported source driver %s`, runner.srcDriver)
+	case `spanner`:
+		dbDumpFunc = dbdump_spanner.DBDumpFunc
+		fromDBValueFunc = gotaface_spanner.FromDBValue
+	case `sqlite3`:
+		dbDumpFunc = dbdump_sqlite3.DBDumpFunc
+		fromDBValueFunc = gotaface_sqlite3.FromDBValue
+	}
+
+	var dbInsertFunc DBInsertFunc
+	switch runner.dstDriver {
+	default:
+		return fmt.Errorf(`unsupported destination driver %s`, runner.dstDriver)
+	case `spanner`:
+		dbInsertFunc = dbinsert_spanner.DBInsertFunc
+	case `sqlite3`:
+		dbInsertFunc = dbinsert_sqlite3.DBInsertFunc
+	}
+
+	var input DBDumpInput
+	d := json.NewDecoder(stdin)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&input); err != nil {
+		return fmt.Errorf(`fail to decode JSON from stdin: %w`, err)
+	}
+
+	dumped, err := dbDumpFunc(ctx, runner.srcDriver, runner.srcDataSource, runner.srcSchemaReader, runner.srcSchemaWriter, input)
+	if err != nil {
+		return fmt.Errorf(`fail to dump rows from source: %w`, err)
+	}
+
+	insertInput := dbInsertInput{}
+	for _, table := range input {
+		rows := dml.Rows{}
+		for _, dumpedRow := range dumped[table] {
+			row := dml.Row{}
+			for column, value := range dumpedRow {
+				row[column], err = fromDBValueFunc(value)
+				if err != nil {
+					return fmt.Errorf(`fail to convert value in column %s of table %s: %w`, column, table, err)
+				}
+			}
+			rows = append(rows, row)
+		}
+		if runner.anonymizer != nil {
+			rows, err = runner.anonymizer.Anonymize(table, rows)
+			if err != nil {
+				return fmt.Errorf(`fail to anonymize rows in table %s: %w`, table, err)
+			}
+		}
+		insertInput = append(insertInput, dbInsertRows{name: table, rows: rows})
+	}
+
+	if err := dbInsertFunc(ctx, runner.dstDriver, runner.dstDataSource, runner.dstSchemaReader, runner.dstSchemaWriter, insertInput); err != nil {
+		return fmt.Errorf(`fail to insert rows into destination: %w`, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	test_spanner "github.com/Jumpaku/gotaface/old/spanner/test"
+	test_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3/test"
+)
+
+func TestRunner_Run(t *testing.T) {
+	sqliteTestDir := os.Getenv(test_sqlite3.EnvSQLiteTestDir)
+	if sqliteTestDir == "" {
+		t.Skipf(`skipped because environment variable %s is not set`, test_sqlite3.EnvSQLiteTestDir)
+	}
+
+	srcPath := fmt.Sprintf(`%s/cmd_dbcopy_src_%d.db`, sqliteTestDir, time.Now().UnixNano())
+	src, tearDownSrc := test_sqlite3.Setup(t, srcPath, "")
+	defer tearDownSrc()
+	test_sqlite3.Init(t, src, []test_sqlite3.Statement{
+		{SQL: `CREATE TABLE User (id INTEGER, name TEXT, score INTEGER, PRIMARY KEY (id));`},
+		{SQL: `INSERT INTO User (id, name, score) VALUES (1, 'a', 10), (2, NULL, NULL);`},
+	})
+
+	dstPath := fmt.Sprintf(`%s/cmd_dbcopy_dst_%d.db`, sqliteTestDir, time.Now().UnixNano())
+	dst, tearDownDst := test_sqlite3.Setup(t, dstPath, "")
+	defer tearDownDst()
+	test_sqlite3.Init(t, dst, []test_sqlite3.Statement{
+		{SQL: `CREATE TABLE User (id INTEGER, name TEXT, score REAL, PRIMARY KEY (id));`},
+	})
+
+	runner := Runner{
+		srcDriver:       `sqlite3`,
+		srcDataSource:   srcPath,
+		srcSchemaWriter: io.Discard,
+		dstDriver:       `sqlite3`,
+		dstDataSource:   dstPath,
+		dstSchemaWriter: io.Discard,
+	}
+
+	// sut
+	err := runner.Run(context.Background(), bytes.NewBufferString(`["User"]`), bytes.NewBuffer(nil))
+	if err != nil {
+		t.Fatalf(`fail to run: %v`, err)
+	}
+
+	test_sqlite3.AssertTableRows(t, dst, `User`, dml.Rows{
+		{`id`: 1, `name`: `a`, `score`: 10.0},
+		{`id`: 2, `name`: nil, `score`: nil},
+	})
+}
+
+func TestRunner_Run_SQLite3ToSpanner(t *testing.T) {
+	sqliteTestDir := os.Getenv(test_sqlite3.EnvSQLiteTestDir)
+	if sqliteTestDir == "" {
+		t.Skipf(`skipped because environment variable %s is not set`, test_sqlite3.EnvSQLiteTestDir)
+	}
+
+	srcPath := fmt.Sprintf(`%s/cmd_dbcopy_src_%d.db`, sqliteTestDir, time.Now().UnixNano())
+	src, tearDownSrc := test_sqlite3.Setup(t, srcPath, "")
+	defer tearDownSrc()
+	test_sqlite3.Init(t, src, []test_sqlite3.Statement{
+		{SQL: `CREATE TABLE User (UserId INTEGER, Name TEXT, Score INTEGER, PRIMARY KEY (UserId));`},
+		{SQL: `INSERT INTO User (UserId, Name, Score) VALUES (1, 'a', 10), (2, NULL, NULL);`},
+	})
+
+	env := test_spanner.GetEnvSpanner()
+	database := fmt.Sprintf(`cmd_dbcopy_%d`, time.Now().UnixNano())
+	fullDatabase := fmt.Sprintf(`projects/%s/instances/%s/databases/%s`, env.Project, env.Instance, database)
+
+	adminClient, client, tearDownDst := test_spanner.Setup(t, database)
+	defer tearDownDst()
+	test_spanner.InitDDL(t, adminClient, fullDatabase, []string{`
+CREATE TABLE User (
+	UserId INT64,
+	Name STRING(MAX),
+	Score FLOAT64,
+) PRIMARY KEY (UserId)`,
+	})
+
+	runner := Runner{
+		srcDriver:       `sqlite3`,
+		srcDataSource:   srcPath,
+		srcSchemaWriter: io.Discard,
+		dstDriver:       `spanner`,
+		dstDataSource:   fullDatabase,
+		dstSchemaWriter: io.Discard,
+	}
+
+	// sut
+	err := runner.Run(context.Background(), bytes.NewBufferString(`["User"]`), bytes.NewBuffer(nil))
+	if err != nil {
+		t.Fatalf(`fail to run: %v`, err)
+	}
+
+	tx := client.ReadOnlyTransaction()
+	defer tx.Close()
+	test_spanner.AssertTableRows(t, tx, `User`, dml.Rows{
+		{`UserId`: 1, `Name`: `a`, `Score`: 10.0},
+		{`UserId`: 2, `Name`: nil, `Score`: nil},
+	})
+}
@@ -24,6 +24,9 @@ func main() {
 	cmd := flag.NewFlagSet("gf-dbdelete", flag.ExitOnError)
 	cmd.Usage = func() { fmt.Println(Usage) }
 
+	dryRun := cmd.Bool(`dry-run`, false, `print statements to stdout instead of executing them`)
+	order := cmd.String(`order`, `input`, `order of tables to delete rows: input or references`)
+
 	if err := cmd.Parse(os.Args[1:]); err != nil {
 		log.Fatalf(`cannot parse command line arguments: %v`, err)
 	}
@@ -33,7 +36,7 @@ func main() {
 		log.Fatalln(`positional arguments <driver> and <data-source> are required`)
 	}
 
-	err := Runner{driver: args[0], dataSource: args[1]}.Run(context.Background(), os.Stdin, os.Stdout)
+	err := Runner{driver: args[0], dataSource: args[1], dryRun: *dryRun, order: *order}.Run(context.Background(), os.Stdin, os.Stdout)
 	if err != nil {
 		log.Fatalf(`failed execution: %v`, err)
 	}
@@ -41,11 +44,14 @@ func main() {
 
 type DBDeleteInput = []string
 type DBDeleteFunc func(ctx context.Context, driver string, dataSource string, input DBDeleteInput) error
+type DBDeleteDryRunFunc func(ctx context.Context, driver string, dataSource string, input DBDeleteInput, output io.Writer) error
 
 type Runner struct {
 	driver     string
 	dataSource string
 	schemaJSON string
+	dryRun     bool
+	order      string
 }
 
 func LoadSchemaJSON(schemaJSON string) (io.Reader, error) {
@@ -72,14 +78,31 @@ func LoadSchemaJSON(schemaJSON string) (io.Reader, error) {
 }
 func (runner Runner) Run(ctx context.Context, stdin io.Reader, stdout io.Writer) error {
 	var dbDeleteFunc DBDeleteFunc
+	var dbDeleteDryRunFunc DBDeleteDryRunFunc
+
+	switch runner.order {
+	default:
+		return fmt.Errorf(`unsupported order %s`, runner.order)
+	case `input`, `references`:
+	}
 
 	switch runner.driver {
 	default:
 		return fmt.Errorf(`unsupported driver %s`, runner.driver)
 	case `spanner`:
 		dbDeleteFunc = dbdelete_spanner.DBDeleteFunc
+		dbDeleteDryRunFunc = dbdelete_spanner.DBDeleteDryRunFunc
+		if runner.order == `references` {
+			dbDeleteFunc = dbdelete_spanner.DBDeleteDeferredFunc
+			dbDeleteDryRunFunc = dbdelete_spanner.DBDeleteDeferredDryRunFunc
+		}
 	case `sqlite3`:
 		dbDeleteFunc = dbdelete_sqlite3.DBDeleteFunc
+		dbDeleteDryRunFunc = dbdelete_sqlite3.DBDeleteDryRunFunc
+		if runner.order == `references` {
+			dbDeleteFunc = dbdelete_sqlite3.DBDeleteDeferredFunc
+			dbDeleteDryRunFunc = dbdelete_sqlite3.DBDeleteDeferredDryRunFunc
+		}
 	}
 
 	var input DBDeleteInput
@@ -89,9 +112,14 @@ func (runner Runner) Run(ctx context.Context, stdin io.Reader, stdout io.Writer)
 		return fmt.Errorf(`fail to decode JSON from stdin`)
 	}
 
-	err := dbDeleteFunc(ctx, runner.driver, runner.dataSource, input)
+	var err error
+	if runner.dryRun {
+		err = dbDeleteDryRunFunc(ctx, runner.driver, runner.dataSource, input, stdout)
+	} else {
+		err = dbDeleteFunc(ctx, runner.driver, runner.dataSource, input)
+	}
 	if err != nil {
-		return fmt.Errorf(`fail to execute dbdelete`)
+		return fmt.Errorf(`fail to execute dbdelete: %w`, err)
 	}
 
 	return nil
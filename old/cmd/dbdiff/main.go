@@ -0,0 +1,449 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/diff"
+	"github.com/Jumpaku/gotaface/old/json/wrap"
+	gotaface_spanner "github.com/Jumpaku/gotaface/old/spanner"
+	dbdump_spanner "github.com/Jumpaku/gotaface/old/spanner/cli/dbdump"
+	gotaface_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3"
+	dbdump_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3/cli/dbdump"
+)
+
+//go:embed README.md
+var Usage string
+
+func main() {
+	cmd := flag.NewFlagSet("gf-dbdiff", flag.ExitOnError)
+	cmd.Usage = func() { fmt.Println(Usage) }
+
+	leftSchema := cmd.String(`left-schema`, ``, `path of schema cache file of left data source`)
+	rightSchema := cmd.String(`right-schema`, ``, `path of schema cache file of right data source`)
+
+	if err := cmd.Parse(os.Args[1:]); err != nil {
+		log.Fatalf(`cannot parse command line arguments: %v`, err)
+	}
+
+	args := cmd.Args()
+	if len(args) != 4 {
+		log.Fatalln(`positional arguments <left-driver>, <left-data-source>, <right-driver>, and <right-data-source> are required`)
+	}
+
+	leftSchemaReader, leftSchemaWriter, closeLeft, err := OpenSchemaCache(*leftSchema, args[0] == `dump`)
+	if err != nil {
+		log.Fatalf(`fail to open schema cache of left: %v`, err)
+	}
+	defer closeLeft()
+
+	rightSchemaReader, rightSchemaWriter, closeRight, err := OpenSchemaCache(*rightSchema, args[2] == `dump`)
+	if err != nil {
+		log.Fatalf(`fail to open schema cache of right: %v`, err)
+	}
+	defer closeRight()
+
+	err = Runner{
+		left:  Source{driver: args[0], dataSource: args[1], schemaReader: leftSchemaReader, schemaWriter: leftSchemaWriter},
+		right: Source{driver: args[2], dataSource: args[3], schemaReader: rightSchemaReader, schemaWriter: rightSchemaWriter},
+	}.Run(context.Background(), os.Stdin, os.Stdout)
+	if err != nil {
+		log.Fatalf(`failed execution: %v`, err)
+	}
+}
+
+type DBDumpInput = []string
+type DBDumpOutput = map[string]dml.Rows
+type DBDumpFunc func(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer, input DBDumpInput) (DBDumpOutput, error)
+
+type FromDBValueFunc func(src any) (any, error)
+
+type DBDiffInput = []string
+type DBDiffOutput = map[string]diff.TableDiff
+
+type Source struct {
+	driver       string
+	dataSource   string
+	schemaReader io.Reader
+	schemaWriter io.Writer
+}
+
+type Runner struct {
+	left  Source
+	right Source
+}
+
+// OpenSchemaCache returns a reader of the schema cache file if it exists, otherwise a writer to create the file.
+// If schema is empty, the schema is fetched without the cache file.
+// If readOnly is true, the cache file must exist since the schema cannot be fetched.
+func OpenSchemaCache(schema string, readOnly bool) (io.Reader, io.Writer, func(), error) {
+	if schema == `` {
+		return nil, io.Discard, func() {}, nil
+	}
+
+	fi, err := os.Stat(schema)
+	if errors.Is(err, os.ErrNotExist) && !readOnly {
+		f, err := os.Create(schema)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf(`fail to create %s: %w`, schema, err)
+		}
+		return nil, f, func() { f.Close() }, nil
+	} else if err != nil {
+		return nil, nil, nil, fmt.Errorf(`fail to open %s: %w`, schema, err)
+	} else if fi.IsDir() {
+		return nil, nil, nil, fmt.Errorf(`%s must be a file`, schema)
+	}
+
+	b, err := os.ReadFile(schema)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf(`fail to read %s: %w`, schema, err)
+	}
+
+	return bytes.NewBuffer(b), nil, func() {}, nil
+}
+
+// Schema returns the schema JSON of the source, which is fetched or read from the schema cache file.
+// The schema JSON is nil if the source is a dump file without a schema cache file.
+func (source Source) Schema(ctx context.Context) ([]byte, error) {
+	schema := bytes.NewBuffer(nil)
+
+	var dbDumpFunc DBDumpFunc
+	switch source.driver {
+	default:
+		return nil, fmt.Errorf(`unsupported driver %s`, source.driver)
+	case `dump`:
+		if source.schemaReader == nil {
+			return nil, nil
+		}
+		if _, err := io.Copy(schema, source.schemaReader); err != nil {
+			return nil, fmt.Errorf(`fail to read schema cache: %w`, err)
+		}
+		return schema.Bytes(), nil
+	case `spanner`:
+		dbDumpFunc = dbdump_spanner.DBDumpFunc
+	case `sqlite3`:
+		dbDumpFunc = dbdump_sqlite3.DBDumpFunc
+	}
+
+	schemaReader, schemaWriter := source.schemaReader, io.Writer(schema)
+	if schemaReader != nil {
+		schemaReader = io.TeeReader(schemaReader, schema)
+	} else if source.schemaWriter != nil {
+		schemaWriter = io.MultiWriter(source.schemaWriter, schema)
+	}
+	if _, err := dbDumpFunc(ctx, source.driver, source.dataSource, schemaReader, schemaWriter, DBDumpInput{}); err != nil {
+		return nil, fmt.Errorf(`fail to fetch schema: %w`, err)
+	}
+
+	return schema.Bytes(), nil
+}
+
+// Rows returns an iterator of rows of plain Go values in the table in the order of the source, and a function to close the iterator.
+// schema is the schema JSON of the source returned by Schema, and columnTypes is a mapping from column name to column type of the table used to unwrap nullable values in a dump file.
+// Rows in a database are dumped table by table, and rows in a dump file are read one by one without loading the whole file.
+func (source Source) Rows(ctx context.Context, schema []byte, columnTypes map[string]string, table string) (diff.RowIterator, func(), error) {
+	var dbDumpFunc DBDumpFunc
+	var fromDBValueFunc FromDBValueFunc
+	switch source.driver {
+	default:
+		return nil, nil, fmt.Errorf(`unsupported driver %s`, source.driver)
+	case `dump`:
+		return LoadDump(source.dataSource, table, columnTypes)
+	case `spanner`:
+		dbDumpFunc = dbdump_spanner.DBDumpFunc
+		fromDBValueFunc = gotaface_spanner.FromDBValue
+	case `sqlite3`:
+		dbDumpFunc = dbdump_sqlite3.DBDumpFunc
+		fromDBValueFunc = gotaface_sqlite3.FromDBValue
+	}
+
+	dumped, err := dbDumpFunc(ctx, source.driver, source.dataSource, bytes.NewReader(schema), io.Discard, DBDumpInput{table})
+	if err != nil {
+		return nil, nil, fmt.Errorf(`fail to dump rows: %w`, err)
+	}
+
+	rows := dml.Rows{}
+	for _, dumpedRow := range dumped[table] {
+		row := dml.Row{}
+		for column, value := range dumpedRow {
+			row[column], err = fromDBValueFunc(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf(`fail to convert value in column %s of table %s: %w`, column, table, err)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return diff.Iterate(rows), func() {}, nil
+}
+
+// LoadDump returns an iterator of rows in the table read one by one from a JSON file output by gf-dbdump, and a function to close the file.
+// Nullable values in the columns are unwrapped by UnwrapNull with the column types in columnTypes.
+func LoadDump(path string, table string, columnTypes map[string]string) (diff.RowIterator, func(), error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf(`fail to open %s: %w`, path, err)
+	}
+
+	tables, err := dumpTables(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf(`fail to decode JSON from %s: %w`, path, err)
+	}
+	if !tables[table] {
+		f.Close()
+		return nil, nil, fmt.Errorf(`table %s not found in %s`, table, path)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf(`fail to read %s: %w`, path, err)
+	}
+
+	return &dumpIterator{decoder: wrap.NewDecoder(f, 2), table: table, columnTypes: columnTypes}, func() { f.Close() }, nil
+}
+
+// dumpTables returns the names of the tables in a JSON file output by gf-dbdump without decoding the rows.
+func dumpTables(r io.Reader) (map[string]bool, error) {
+	d := json.NewDecoder(r)
+	tables := map[string]bool{}
+	depth := 0
+	for {
+		token, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return tables, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		default:
+			if name, ok := token.(string); ok && depth == 1 {
+				tables[name] = true
+			}
+		}
+	}
+}
+
+type dumpIterator struct {
+	decoder     *wrap.Decoder
+	table       string
+	columnTypes map[string]string
+}
+
+func (i *dumpIterator) Next() (dml.Row, error) {
+	for {
+		path, value, err := i.decoder.Next()
+		if err != nil {
+			return nil, err
+		}
+		if path.Get(0).String() != i.table {
+			continue
+		}
+
+		row, err := wrap.Decode[dml.Row](value)
+		if err != nil {
+			return nil, fmt.Errorf(`fail to decode row %s in table %s: %w`, path.Get(1), i.table, err)
+		}
+		for column, value := range row {
+			row[column] = UnwrapNull(i.columnTypes[column], value)
+		}
+		return row, nil
+	}
+}
+
+// UnwrapNull returns the value in a JSON object such as {"Int64":1,"Valid":true}, or nil if it is not valid.
+// Such an object is dumped from SQLite3 by gf-dbdump for a column whose type is scanned into a nullable type such as sql.NullInt64.
+// value is returned as is if columnType is not scanned into a nullable type or value is not such an object with the field of the nullable type.
+func UnwrapNull(columnType string, value any) any {
+	t := gotaface_sqlite3.GoType(columnType)
+	if t.Kind() != reflect.Struct || !strings.HasPrefix(t.Name(), `Null`) || t.NumField() != 2 {
+		return value
+	}
+
+	object, ok := value.(map[string]any)
+	if !ok || len(object) != 2 {
+		return value
+	}
+	valid, ok := object[`Valid`].(bool)
+	if !ok {
+		return value
+	}
+	v, ok := object[t.Field(0).Name]
+	if !ok {
+		return value
+	}
+	if !valid {
+		return nil
+	}
+	return v
+}
+
+// PrimaryKeys returns a mapping from table name to column names in the primary key from the schema JSON.
+func PrimaryKeys(schema []byte) (map[string][]string, error) {
+	var s struct {
+		Tables []struct {
+			Name    string `json:"name"`
+			Columns []struct {
+				Name string `json:"name"`
+			} `json:"columns"`
+			PrimaryKey []int `json:"primary_key"`
+		} `json:"tables"`
+	}
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return nil, fmt.Errorf(`fail to decode schema JSON: %w`, err)
+	}
+
+	primaryKeys := map[string][]string{}
+	for _, table := range s.Tables {
+		key := []string{}
+		for _, index := range table.PrimaryKey {
+			if index < 0 || index >= len(table.Columns) {
+				return nil, fmt.Errorf(`invalid primary key in table %s`, table.Name)
+			}
+			key = append(key, table.Columns[index].Name)
+		}
+		primaryKeys[table.Name] = key
+	}
+	return primaryKeys, nil
+}
+
+// ColumnTypes returns a mapping from table name to mappings from column name to column type from the schema JSON.
+func ColumnTypes(schema []byte) (map[string]map[string]string, error) {
+	var s struct {
+		Tables []struct {
+			Name    string `json:"name"`
+			Columns []struct {
+				Name string `json:"name"`
+				Type string `json:"type"`
+			} `json:"columns"`
+		} `json:"tables"`
+	}
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return nil, fmt.Errorf(`fail to decode schema JSON: %w`, err)
+	}
+
+	columnTypes := map[string]map[string]string{}
+	for _, table := range s.Tables {
+		types := map[string]string{}
+		for _, column := range table.Columns {
+			types[column.Name] = column.Type
+		}
+		columnTypes[table.Name] = types
+	}
+	return columnTypes, nil
+}
+
+func (runner Runner) Run(ctx context.Context, stdin io.Reader, stdout io.Writer) error {
+	var input DBDiffInput
+	d := json.NewDecoder(stdin)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&input); err != nil {
+		return fmt.Errorf(`fail to decode JSON from stdin: %w`, err)
+	}
+
+	leftSchema, err := runner.left.Schema(ctx)
+	if err != nil {
+		return fmt.Errorf(`fail to get schema of left: %w`, err)
+	}
+	rightSchema, err := runner.right.Schema(ctx)
+	if err != nil {
+		return fmt.Errorf(`fail to get schema of right: %w`, err)
+	}
+
+	schema := leftSchema
+	if schema == nil {
+		schema = rightSchema
+	}
+	if schema == nil {
+		return fmt.Errorf(`schema is required to compare dump files`)
+	}
+	primaryKeys, err := PrimaryKeys(schema)
+	if err != nil {
+		return fmt.Errorf(`fail to get primary keys: %w`, err)
+	}
+	columnTypes, err := ColumnTypes(schema)
+	if err != nil {
+		return fmt.Errorf(`fail to get column types: %w`, err)
+	}
+
+	output := DBDiffOutput{}
+	for _, table := range input {
+		primaryKey, ok := primaryKeys[table]
+		if !ok {
+			return fmt.Errorf(`table %s not found in schema`, table)
+		}
+		output[table], err = runner.diff(ctx, leftSchema, rightSchema, columnTypes[table], table, primaryKey)
+		if err != nil {
+			return fmt.Errorf(`fail to compare rows in table %s: %w`, table, err)
+		}
+	}
+
+	e := json.NewEncoder(stdout)
+	if err := e.Encode(output); err != nil {
+		return fmt.Errorf(`fail to encode JSON to stdout: %w`, err)
+	}
+
+	return nil
+}
+
+func (runner Runner) diff(ctx context.Context, leftSchema []byte, rightSchema []byte, columnTypes map[string]string, table string, primaryKey []string) (diff.TableDiff, error) {
+	left, closeLeft, err := runner.left.Rows(ctx, leftSchema, columnTypes, table)
+	if err != nil {
+		return diff.TableDiff{}, fmt.Errorf(`fail to dump rows from left: %w`, err)
+	}
+	defer closeLeft()
+
+	right, closeRight, err := runner.right.Rows(ctx, rightSchema, columnTypes, table)
+	if err != nil {
+		return diff.TableDiff{}, fmt.Errorf(`fail to dump rows from right: %w`, err)
+	}
+	defer closeRight()
+
+	left, err = SortedRows(primaryKey, left)
+	if err != nil {
+		return diff.TableDiff{}, fmt.Errorf(`fail to sort rows from left: %w`, err)
+	}
+	right, err = SortedRows(primaryKey, right)
+	if err != nil {
+		return diff.TableDiff{}, fmt.Errorf(`fail to sort rows from right: %w`, err)
+	}
+
+	return diff.DiffRows(primaryKey, left, right)
+}
+
+// SortedRows reads all the rows from the iterator and returns an iterator of them sorted by diff.SortRows.
+// Rows ordered by the primary key in a database may not be ordered as required by diff.DiffRows, e.g., TIMESTAMP keys are formatted with trailing zeros trimmed, BYTES keys are encoded in base64, and keys may be in descending order or compared with collations.
+func SortedRows(primaryKey []string, rows diff.RowIterator) (diff.RowIterator, error) {
+	all := dml.Rows{}
+	for {
+		row, err := rows.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, row)
+	}
+
+	sorted, err := diff.SortRows(primaryKey, all)
+	if err != nil {
+		return nil, err
+	}
+	return diff.Iterate(sorted), nil
+}
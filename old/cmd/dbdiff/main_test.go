@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/diff"
+	gotaface_spanner "github.com/Jumpaku/gotaface/old/spanner"
+	"github.com/Jumpaku/gotaface/old/sqlite3/test"
+)
+
+func TestUnwrapNull(t *testing.T) {
+	testcases := []struct {
+		name       string
+		columnType string
+		value      any
+		want       any
+	}{
+		{name: `valid INTEGER`, columnType: `INTEGER`, value: map[string]any{`Int64`: json.Number(`1`), `Valid`: true}, want: json.Number(`1`)},
+		{name: `invalid TEXT`, columnType: `TEXT`, value: map[string]any{`String`: ``, `Valid`: false}, want: nil},
+		{name: `field of another type`, columnType: `TEXT`, value: map[string]any{`Int64`: json.Number(`1`), `Valid`: true}, want: map[string]any{`Int64`: json.Number(`1`), `Valid`: true}},
+		{name: `BLOB`, columnType: `BLOB`, value: map[string]any{`String`: `a`, `Valid`: true}, want: map[string]any{`String`: `a`, `Valid`: true}},
+		{name: `plain value`, columnType: `INTEGER`, value: json.Number(`1`), want: json.Number(`1`)},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			got, _ := json.Marshal(UnwrapNull(testcase.columnType, testcase.value))
+			want, _ := json.Marshal(testcase.want)
+			if !bytes.Equal(got, want) {
+				t.Errorf("got != want\n  got  = %s\n  want = %s", got, want)
+			}
+		})
+	}
+}
+
+func TestRunner_Run(t *testing.T) {
+	sqliteTestDir := os.Getenv(test.EnvSQLiteTestDir)
+	if sqliteTestDir == "" {
+		t.Skipf(`skipped because environment variable %s is not set`, test.EnvSQLiteTestDir)
+	}
+
+	dbPath := fmt.Sprintf(`%s/cmd_dbdiff_%d.db`, sqliteTestDir, time.Now().UnixNano())
+	db, tearDown := test.Setup(t, dbPath, "")
+	defer tearDown()
+
+	test.Init(t, db, []test.Statement{
+		{SQL: `CREATE TABLE User (id INTEGER, name TEXT, score REAL, PRIMARY KEY (id));`},
+		{SQL: `INSERT INTO User (id, name, score) VALUES (1, "a", 1), (2, "b", NULL), (4, NULL, 2.5);`},
+	})
+
+	dumpPath := filepath.Join(t.TempDir(), `dump.json`)
+	dump := `{"Other": [{"id": 1}], "User": [` +
+		`{"id": {"Int64": 1, "Valid": true}, "name": {"String": "a", "Valid": true}, "score": {"Float64": 1.0, "Valid": true}},` +
+		`{"id": {"Int64": 3, "Valid": true}, "name": {"String": "c", "Valid": true}, "score": {"Float64": 0, "Valid": false}},` +
+		`{"id": {"Int64": 4, "Valid": true}, "name": {"String": "", "Valid": false}, "score": {"Float64": 3, "Valid": true}}` +
+		`]}`
+	if err := os.WriteFile(dumpPath, []byte(dump), 0644); err != nil {
+		t.Fatalf(`fail to write dump file: %v`, err)
+	}
+
+	runner := Runner{
+		left:  Source{driver: `dump`, dataSource: dumpPath},
+		right: Source{driver: `sqlite3`, dataSource: dbPath, schemaWriter: bytes.NewBuffer(nil)},
+	}
+	stdout := bytes.NewBuffer(nil)
+
+	// sut
+	err := runner.Run(context.Background(), bytes.NewBufferString(`["User"]`), stdout)
+	if err != nil {
+		t.Fatalf(`fail to run: %v`, err)
+	}
+
+	var got, want any
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf(`fail to decode output: %v`, err)
+	}
+	json.Unmarshal([]byte(`{"User": {`+
+		`"inserted": [{"id": 2, "name": "b", "score": null}],`+
+		`"deleted": [{"id": 3, "name": "c", "score": null}],`+
+		`"changed": [{"key": {"id": 4}, "columns": [{"column": "score", "left": 3, "right": 2.5}]}]`+
+		`}}`), &want)
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if !bytes.Equal(gotJSON, wantJSON) {
+		t.Errorf("output not match\n  got  = %s\n  want = %s", gotJSON, wantJSON)
+	}
+}
+
+func TestSortedRows(t *testing.T) {
+	testcases := []struct {
+		name   string
+		values []any
+	}{
+		{
+			name: `TIMESTAMP`,
+			values: []any{
+				spanner.NullTime{Valid: true, Time: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+				spanner.NullTime{Valid: true, Time: time.Date(2023, 1, 1, 0, 0, 0, 500_000_000, time.UTC)},
+			},
+		},
+		{
+			name:   `BYTES`,
+			values: []any{[]byte{0x00}, []byte{0xf8}},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// rows in the order of the primary key in Spanner
+			rows := dml.Rows{}
+			for i, value := range testcase.values {
+				key, err := gotaface_spanner.FromDBValue(value)
+				if err != nil {
+					t.Fatalf(`fail to convert key: %v`, err)
+				}
+				rows = append(rows, dml.Row{`id`: key, `n`: int64(i)})
+			}
+
+			left, err := SortedRows([]string{`id`}, diff.Iterate(rows))
+			if err != nil {
+				t.Fatalf(`fail to sort left: %v`, err)
+			}
+			right, err := SortedRows([]string{`id`}, diff.Iterate(rows))
+			if err != nil {
+				t.Fatalf(`fail to sort right: %v`, err)
+			}
+
+			got, err := diff.DiffRows([]string{`id`}, left, right)
+			if err != nil {
+				t.Fatalf(`fail to compare: %v`, err)
+			}
+			if !got.Empty() {
+				t.Errorf(`same rows must not differ: %#v`, got)
+			}
+		})
+	}
+}
+
+func TestRunner_Run_Keys(t *testing.T) {
+	sqliteTestDir := os.Getenv(test.EnvSQLiteTestDir)
+	if sqliteTestDir == "" {
+		t.Skipf(`skipped because environment variable %s is not set`, test.EnvSQLiteTestDir)
+	}
+
+	statements := []test.Statement{
+		{SQL: `CREATE TABLE Blob (id BLOB, n INTEGER, PRIMARY KEY (id));`},
+		{SQL: `INSERT INTO Blob (id, n) VALUES (X'00', 1), (X'F8', 2);`},
+		{SQL: `CREATE TABLE NoCase (id TEXT COLLATE NOCASE, n INTEGER, PRIMARY KEY (id));`},
+		{SQL: `INSERT INTO NoCase (id, n) VALUES ('a', 1), ('B', 2);`},
+	}
+	leftPath := fmt.Sprintf(`%s/cmd_dbdiff_left_%d.db`, sqliteTestDir, time.Now().UnixNano())
+	leftDB, tearDownLeft := test.Setup(t, leftPath, "")
+	defer tearDownLeft()
+	test.Init(t, leftDB, statements)
+
+	rightPath := fmt.Sprintf(`%s/cmd_dbdiff_right_%d.db`, sqliteTestDir, time.Now().UnixNano())
+	rightDB, tearDownRight := test.Setup(t, rightPath, "")
+	defer tearDownRight()
+	test.Init(t, rightDB, append(statements, test.Statement{SQL: `UPDATE NoCase SET n = 3 WHERE id = 'B';`}))
+
+	runner := Runner{
+		left:  Source{driver: `sqlite3`, dataSource: leftPath, schemaWriter: bytes.NewBuffer(nil)},
+		right: Source{driver: `sqlite3`, dataSource: rightPath, schemaWriter: bytes.NewBuffer(nil)},
+	}
+	stdout := bytes.NewBuffer(nil)
+
+	// sut
+	err := runner.Run(context.Background(), bytes.NewBufferString(`["Blob", "NoCase"]`), stdout)
+	if err != nil {
+		t.Fatalf(`fail to run: %v`, err)
+	}
+
+	var got, want any
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf(`fail to decode output: %v`, err)
+	}
+	json.Unmarshal([]byte(`{`+
+		`"Blob": {"inserted": [], "deleted": [], "changed": []},`+
+		`"NoCase": {"inserted": [], "deleted": [], "changed": [{"key": {"id": "B"}, "columns": [{"column": "n", "left": 2, "right": 3}]}]}`+
+		`}`), &want)
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if !bytes.Equal(gotJSON, wantJSON) {
+		t.Errorf("output not match\n  got  = %s\n  want = %s", gotJSON, wantJSON)
+	}
+}
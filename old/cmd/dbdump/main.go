@@ -11,9 +11,16 @@ import (
 	"io"
 	"log"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/anonymize"
+	"github.com/Jumpaku/gotaface/old/json/jsonschema"
+	"github.com/Jumpaku/gotaface/old/json/wrap"
+	gotaface_spanner "github.com/Jumpaku/gotaface/old/spanner"
 	dbdump_spanner "github.com/Jumpaku/gotaface/old/spanner/cli/dbdump"
+	gotaface_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3"
 	dbdump_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3/cli/dbdump"
 )
 
@@ -25,6 +32,9 @@ func main() {
 	cmd.Usage = func() { fmt.Println(Usage) }
 
 	schema := cmd.String(`schema`, `.gf-schema.json`, `path of schema cache file`)
+	anonymizeConfig := cmd.String(`anonymize`, ``, `path of anonymization config file`)
+	format := cmd.String(`format`, `json`, `format of output to stdout: json or sql`)
+	validate := cmd.String(`validate`, ``, `path of JSON Schema file to validate input`)
 
 	if err := cmd.Parse(os.Args[1:]); err != nil {
 		log.Fatalf(`cannot parse command line arguments: %v`, err)
@@ -51,7 +61,23 @@ func main() {
 		log.Fatalln(`positional arguments <driver> and <data-source> are required`)
 	}
 
-	err = Runner{driver: args[0], dataSource: args[1], schemaReader: schemaReader, schemaWriter: schemaWriter}.Run(context.Background(), os.Stdin, os.Stdout)
+	var anonymizer Anonymizer
+	if *anonymizeConfig != `` {
+		anonymizer, err = LoadAnonymizer(*anonymizeConfig)
+		if err != nil {
+			log.Fatalf(`fail to load anonymization config: %v`, err)
+		}
+	}
+
+	var jsonSchema *jsonschema.Schema
+	if *validate != `` {
+		jsonSchema, err = jsonschema.ParseFile(*validate)
+		if err != nil {
+			log.Fatalf(`fail to load JSON Schema: %v`, err)
+		}
+	}
+
+	err = Runner{driver: args[0], dataSource: args[1], schemaReader: schemaReader, schemaWriter: schemaWriter, anonymizer: anonymizer, format: *format, jsonSchema: jsonSchema}.Run(context.Background(), os.Stdin, os.Stdout)
 	if err != nil {
 		log.Fatalf(`failed execution: %v`, err)
 	}
@@ -61,11 +87,45 @@ type DBDumpInput = []string
 type DBDumpOutput = map[string]dml.Rows
 type DBDumpFunc func(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer, input DBDumpInput) (DBDumpOutput, error)
 
+type FromDBValueFunc func(src any) (any, error)
+type ToDBValueFunc func(columnType string, src any) (any, error)
+type ToLiteralFunc func(src any) (string, error)
+type QuoteIdentifierFunc func(name string) string
+
+type Anonymizer interface {
+	Anonymize(table string, rows dml.Rows) (dml.Rows, error)
+}
+
 type Runner struct {
 	driver       string
 	dataSource   string
 	schemaReader io.Reader
 	schemaWriter io.Writer
+	anonymizer   Anonymizer
+	format       string
+	jsonSchema   *jsonschema.Schema
+}
+
+func LoadAnonymizer(config string) (Anonymizer, error) {
+	f, err := os.Open(config)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to open %s: %w`, config, err)
+	}
+	defer f.Close()
+
+	var c anonymize.Config
+	d := json.NewDecoder(f)
+	d.DisallowUnknownFields()
+	d.UseNumber()
+	if err := d.Decode(&c); err != nil {
+		return nil, fmt.Errorf(`fail to decode JSON from %s: %w`, config, err)
+	}
+
+	anonymizer, err := anonymize.NewAnonymizer(c)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid anonymization config %s: %w`, config, err)
+	}
+	return anonymizer, nil
 }
 
 func LoadSchemaCache(schema string) (io.Reader, error) {
@@ -94,28 +154,96 @@ func LoadSchemaCache(schema string) (io.Reader, error) {
 
 func (runner Runner) Run(ctx context.Context, stdin io.Reader, stdout io.Writer) error {
 	var dbDumpFunc DBDumpFunc
+	var fromDBValueFunc FromDBValueFunc
+	var toDBValueFunc ToDBValueFunc
+	var toLiteralFunc ToLiteralFunc
+	var quoteIdentifierFunc QuoteIdentifierFunc
 
 	switch runner.driver {
 	default:
 		return fmt.Errorf(`unsupported driver %s`, runner.driver)
 	case `spanner`:
 		dbDumpFunc = dbdump_spanner.DBDumpFunc
+		fromDBValueFunc = gotaface_spanner.FromDBValue
+		toDBValueFunc = gotaface_spanner.ToDBValue
+		toLiteralFunc = gotaface_spanner.ToLiteral
+		quoteIdentifierFunc = gotaface_spanner.QuoteIdentifier
 	case `sqlite3`:
 		dbDumpFunc = dbdump_sqlite3.DBDumpFunc
+		fromDBValueFunc = gotaface_sqlite3.FromDBValue
+		toDBValueFunc = gotaface_sqlite3.ToDBValue
+		toLiteralFunc = gotaface_sqlite3.ToLiteral
+		quoteIdentifierFunc = gotaface_sqlite3.QuoteIdentifier
+	}
+
+	switch runner.format {
+	default:
+		return fmt.Errorf(`unsupported format %s`, runner.format)
+	case `json`, `sql`:
+	}
+
+	b, err := io.ReadAll(stdin)
+	if err != nil {
+		return fmt.Errorf(`fail to read input from stdin: %w`, err)
+	}
+
+	if runner.jsonSchema != nil {
+		v := wrap.Null()
+		if err := v.UnmarshalJSON(b); err != nil {
+			return fmt.Errorf(`fail to decode JSON from stdin: %w`, err)
+		}
+		if err := runner.jsonSchema.Validate(v); err != nil {
+			return fmt.Errorf(`invalid input from stdin: %w`, err)
+		}
 	}
 
 	var input DBDumpInput
-	d := json.NewDecoder(stdin)
+	d := json.NewDecoder(bytes.NewBuffer(b))
 	d.DisallowUnknownFields()
 	if err := d.Decode(&input); err != nil {
 		return fmt.Errorf(`fail to decode JSON from stdin`)
 	}
 
-	output, err := dbDumpFunc(ctx, runner.driver, runner.dataSource, runner.schemaReader, runner.schemaWriter, input)
+	// The schema is kept to convert anonymized values back into DB values of the column types.
+	schema := bytes.NewBuffer(nil)
+	schemaReader, schemaWriter := runner.schemaReader, runner.schemaWriter
+	if schemaReader != nil {
+		schemaReader = io.TeeReader(schemaReader, schema)
+	} else if schemaWriter != nil {
+		schemaWriter = io.MultiWriter(schemaWriter, schema)
+	} else {
+		schemaWriter = schema
+	}
+
+	output, err := dbDumpFunc(ctx, runner.driver, runner.dataSource, schemaReader, schemaWriter, input)
 	if err != nil {
 		return fmt.Errorf(`fail to execute dbdump`)
 	}
 
+	if runner.anonymizer != nil {
+		output, err = Anonymize(runner.anonymizer, fromDBValueFunc, output)
+		if err != nil {
+			return fmt.Errorf(`fail to anonymize dumped rows: %w`, err)
+		}
+	}
+
+	if runner.format == `sql` {
+		if runner.anonymizer != nil {
+			columnTypes, err := ColumnTypes(schema.Bytes())
+			if err != nil {
+				return fmt.Errorf(`fail to get column types: %w`, err)
+			}
+			output, err = ToDBRows(toDBValueFunc, columnTypes, output)
+			if err != nil {
+				return fmt.Errorf(`fail to convert anonymized rows: %w`, err)
+			}
+		}
+		if err := WriteSQL(stdout, quoteIdentifierFunc, toLiteralFunc, input, output); err != nil {
+			return fmt.Errorf(`fail to write SQL to stdout: %w`, err)
+		}
+		return nil
+	}
+
 	e := json.NewEncoder(stdout)
 	if err := e.Encode(output); err != nil {
 		return fmt.Errorf(`fail to encode JSON to stdout`)
@@ -123,3 +251,115 @@ func (runner Runner) Run(ctx context.Context, stdin io.Reader, stdout io.Writer)
 
 	return nil
 }
+
+// WriteSQL writes INSERT statements of the dumped rows in the order of tables with the table and column names quoted.
+func WriteSQL(writer io.Writer, quoteIdentifierFunc QuoteIdentifierFunc, toLiteralFunc ToLiteralFunc, tables []string, output DBDumpOutput) error {
+	for _, table := range tables {
+		for _, row := range output[table] {
+			columns := []string{}
+			for column := range row {
+				columns = append(columns, column)
+			}
+			sort.Strings(columns)
+
+			quotedColumns := []string{}
+			values := []string{}
+			for _, column := range columns {
+				quotedColumns = append(quotedColumns, quoteIdentifierFunc(column))
+				value, err := toLiteralFunc(row[column])
+				if err != nil {
+					return fmt.Errorf(`fail to convert value in column %s of table %s to literal: %w`, column, table, err)
+				}
+				values = append(values, value)
+			}
+
+			stmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);\n", quoteIdentifierFunc(table), strings.Join(quotedColumns, ", "), strings.Join(values, ", "))
+			if _, err := io.WriteString(writer, stmt); err != nil {
+				return fmt.Errorf(`fail to write statement: %w`, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Anonymize converts the dumped values into plain Go values and transforms them by the anonymizer.
+func Anonymize(anonymizer Anonymizer, fromDBValueFunc FromDBValueFunc, output DBDumpOutput) (DBDumpOutput, error) {
+	anonymized := DBDumpOutput{}
+	for table, dumpedRows := range output {
+		rows := dml.Rows{}
+		for _, dumpedRow := range dumpedRows {
+			row := dml.Row{}
+			for column, value := range dumpedRow {
+				var err error
+				row[column], err = fromDBValueFunc(value)
+				if err != nil {
+					return nil, fmt.Errorf(`fail to convert value in column %s of table %s: %w`, column, table, err)
+				}
+			}
+			rows = append(rows, row)
+		}
+
+		rows, err := anonymizer.Anonymize(table, rows)
+		if err != nil {
+			return nil, fmt.Errorf(`fail to anonymize rows in table %s: %w`, table, err)
+		}
+		anonymized[table] = rows
+	}
+	return anonymized, nil
+}
+
+// ColumnTypes returns a mapping from table name to mappings from column name to column type from the schema JSON.
+func ColumnTypes(schema []byte) (map[string]map[string]string, error) {
+	var s struct {
+		Tables []struct {
+			Name    string `json:"name"`
+			Columns []struct {
+				Name string `json:"name"`
+				Type string `json:"type"`
+			} `json:"columns"`
+		} `json:"tables"`
+	}
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return nil, fmt.Errorf(`fail to decode schema JSON: %w`, err)
+	}
+
+	columnTypes := map[string]map[string]string{}
+	for _, table := range s.Tables {
+		types := map[string]string{}
+		for _, column := range table.Columns {
+			types[column.Name] = column.Type
+		}
+		columnTypes[table.Name] = types
+	}
+	return columnTypes, nil
+}
+
+// ToDBRows converts the plain Go values in the anonymized rows back into DB values of the column types, which toLiteralFunc accepts.
+func ToDBRows(toDBValueFunc ToDBValueFunc, columnTypes map[string]map[string]string, output DBDumpOutput) (DBDumpOutput, error) {
+	converted := DBDumpOutput{}
+	for table, rows := range output {
+		types, ok := columnTypes[table]
+		if !ok {
+			return nil, fmt.Errorf(`table %s not found in schema`, table)
+		}
+
+		dbRows := dml.Rows{}
+		for _, row := range rows {
+			dbRow := dml.Row{}
+			for column, value := range row {
+				columnType, ok := types[column]
+				if !ok {
+					return nil, fmt.Errorf(`column %s not found in table %s`, column, table)
+				}
+				var err error
+				dbRow[column], err = toDBValueFunc(columnType, value)
+				if err != nil {
+					return nil, fmt.Errorf(`fail to convert value in column %s of table %s: %w`, column, table, err)
+				}
+			}
+			dbRows = append(dbRows, dbRow)
+		}
+		converted[table] = dbRows
+	}
+	return converted, nil
+}
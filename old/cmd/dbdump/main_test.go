@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"cloud.google.com/go/spanner"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/anonymize"
+	gotaface_spanner "github.com/Jumpaku/gotaface/old/spanner"
+	"github.com/Jumpaku/gotaface/old/sqlite3/test"
+)
+
+func TestRunner_Run_AnonymizeSQL(t *testing.T) {
+	sqliteTestDir := os.Getenv(test.EnvSQLiteTestDir)
+	if sqliteTestDir == "" {
+		t.Skipf(`skipped because environment variable %s is not set`, test.EnvSQLiteTestDir)
+	}
+
+	dbPath := fmt.Sprintf(`%s/cmd_dbdump_%d.db`, sqliteTestDir, time.Now().UnixNano())
+	db, tearDown := test.Setup(t, dbPath, "")
+	defer tearDown()
+
+	test.Init(t, db, []test.Statement{
+		{SQL: `CREATE TABLE User (id INTEGER, name TEXT, score REAL, PRIMARY KEY (id));`},
+		{SQL: `INSERT INTO User (id, name, score) VALUES (1, 'a', 1.5);`},
+	})
+
+	anonymizer, err := anonymize.NewAnonymizer(anonymize.Config{Tables: map[string]map[string]anonymize.Rule{
+		`User`: {`name`: {Type: anonymize.RuleNull}},
+	}})
+	if err != nil {
+		t.Fatalf(`fail to create anonymizer: %v`, err)
+	}
+	runner := Runner{driver: `sqlite3`, dataSource: dbPath, schemaWriter: bytes.NewBuffer(nil), anonymizer: anonymizer, format: `sql`}
+	stdout := bytes.NewBuffer(nil)
+
+	// sut
+	err = runner.Run(context.Background(), bytes.NewBufferString(`["User"]`), stdout)
+	if err != nil {
+		t.Fatalf(`fail to run: %v`, err)
+	}
+
+	want := "INSERT INTO \"User\" (\"id\", \"name\", \"score\") VALUES (1, NULL, 1.5);\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("output not match\n  got  = %s\n  want = %s", got, want)
+	}
+}
+
+func TestWriteSQL(t *testing.T) {
+	output := DBDumpOutput{
+		`Order`: dml.Rows{{`Group`: spanner.NullString{Valid: true, StringVal: `a`}, `id`: spanner.NullInt64{Valid: true, Int64: 1}}},
+	}
+	buffer := bytes.NewBuffer(nil)
+
+	// sut
+	err := WriteSQL(buffer, gotaface_spanner.QuoteIdentifier, gotaface_spanner.ToLiteral, []string{`Order`}, output)
+	if err != nil {
+		t.Fatalf(`fail to write SQL: %v`, err)
+	}
+
+	want := "INSERT INTO `Order` (`Group`, `id`) VALUES (\"a\", 1);\n"
+	if got := buffer.String(); got != want {
+		t.Errorf("output not match\n  got  = %s\n  want = %s", got, want)
+	}
+}
+
+func TestAnonymize_HashTimestamp(t *testing.T) {
+	anonymizer, err := anonymize.NewAnonymizer(anonymize.Config{Tables: map[string]map[string]anonymize.Rule{
+		`Event`: {`At`: {Type: anonymize.RuleHash}, `Tags`: {Type: anonymize.RuleHash}},
+	}})
+	if err != nil {
+		t.Fatalf(`fail to create anonymizer: %v`, err)
+	}
+	output := DBDumpOutput{`Event`: dml.Rows{{
+		`At`:   spanner.NullTime{Valid: true, Time: time.Date(2023, 6, 11, 1, 23, 45, 0, time.UTC)},
+		`Tags`: []spanner.NullDate{{Valid: true, Date: civil.Date{Year: 2023, Month: 6, Day: 11}}},
+	}}}
+	columnTypes := map[string]map[string]string{`Event`: {`At`: `TIMESTAMP`, `Tags`: `ARRAY<DATE>`}}
+
+	// sut
+	anonymized, err := Anonymize(anonymizer, gotaface_spanner.FromDBValue, output)
+	if err != nil {
+		t.Fatalf(`fail to anonymize: %v`, err)
+	}
+	converted, err := ToDBRows(gotaface_spanner.ToDBValue, columnTypes, anonymized)
+	if err != nil {
+		t.Fatalf(`fail to convert anonymized rows: %v`, err)
+	}
+	buffer := bytes.NewBuffer(nil)
+	if err := WriteSQL(buffer, gotaface_spanner.QuoteIdentifier, gotaface_spanner.ToLiteral, []string{`Event`}, converted); err != nil {
+		t.Fatalf(`fail to write SQL: %v`, err)
+	}
+
+	pattern := regexp.MustCompile("^INSERT INTO `Event` \\(`At`, `Tags`\\) VALUES \\(TIMESTAMP \"[^\"]+\", \\[DATE \"\\d{4}-\\d{2}-\\d{2}\"\\]\\);\n$")
+	got := buffer.String()
+	if !pattern.MatchString(got) {
+		t.Errorf("output not match\n  got     = %s\n  pattern = %s", got, pattern)
+	}
+	if strings.Contains(got, `2023-06-11`) {
+		t.Errorf(`values are not anonymized: %s`, got)
+	}
+}
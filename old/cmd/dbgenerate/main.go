@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	dbgenerate_spanner "github.com/Jumpaku/gotaface/old/spanner/cli/dbgenerate"
+	"github.com/Jumpaku/gotaface/spanner/generate"
+)
+
+//go:embed README.md
+var Usage string
+
+func main() {
+	cmd := flag.NewFlagSet("gf-dbgenerate", flag.ExitOnError)
+	cmd.Usage = func() { fmt.Println(Usage) }
+
+	rows := cmd.Int(`rows`, 10, `number of rows generated for each table`)
+	seed := cmd.Int64(`seed`, 0, `seed of random values, which is determined by the current time if 0`)
+
+	if err := cmd.Parse(os.Args[1:]); err != nil {
+		log.Fatalf(`cannot parse command line arguments: %v`, err)
+	}
+
+	args := cmd.Args()
+	if len(args) != 2 {
+		log.Fatalln(`positional arguments <driver> and <data-source> are required`)
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
+	err := Runner{driver: args[0], dataSource: args[1], rows: *rows, seed: *seed}.Run(context.Background(), os.Stdin, os.Stdout)
+	if err != nil {
+		log.Fatalf(`failed execution: %v`, err)
+	}
+}
+
+type DBGenerateInput = []string
+type DBGenerateOutput = []generate.TableRows
+type DBGenerateFunc func(ctx context.Context, driver string, dataSource string, rows int, seed int64, input DBGenerateInput) (DBGenerateOutput, error)
+
+type Runner struct {
+	driver     string
+	dataSource string
+	rows       int
+	seed       int64
+}
+
+func (runner Runner) Run(ctx context.Context, stdin io.Reader, stdout io.Writer) error {
+	var dbGenerateFunc DBGenerateFunc
+
+	switch runner.driver {
+	default:
+		return fmt.Errorf(`unsupported driver %s`, runner.driver)
+	case `spanner`:
+		dbGenerateFunc = dbgenerate_spanner.DBGenerateFunc
+	}
+
+	var input DBGenerateInput
+	d := json.NewDecoder(stdin)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&input); err != nil {
+		return fmt.Errorf(`fail to decode JSON from stdin: %w`, err)
+	}
+
+	output, err := dbGenerateFunc(ctx, runner.driver, runner.dataSource, runner.rows, runner.seed, input)
+	if err != nil {
+		return fmt.Errorf(`fail to execute dbgenerate: %w`, err)
+	}
+
+	e := json.NewEncoder(stdout)
+	if err := e.Encode(output); err != nil {
+		return fmt.Errorf(`fail to encode JSON to stdout: %w`, err)
+	}
+
+	return nil
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -13,8 +12,11 @@ import (
 	"os"
 
 	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/fixture"
+	"github.com/Jumpaku/gotaface/old/json/jsonschema"
+	"github.com/Jumpaku/gotaface/old/json/wrap"
 	dbinsert_spanner "github.com/Jumpaku/gotaface/old/spanner/cli/dbinsert"
-	//dbinsert_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3/cli/dbdump"
+	dbinsert_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3/cli/dbinsert"
 )
 
 //go:embed README.md
@@ -25,6 +27,10 @@ func main() {
 	cmd.Usage = func() { fmt.Println(Usage) }
 
 	schema := cmd.String(`schema`, `.gf-schema.json`, `path of schema cache file`)
+	format := cmd.String(`format`, `json`, `format of input from stdin: json or yaml`)
+	dryRun := cmd.Bool(`dry-run`, false, `print statements to stdout instead of executing them`)
+	validate := cmd.String(`validate`, ``, `path of JSON Schema file to validate input`)
+	order := cmd.String(`order`, `input`, `order of tables to insert rows: input or references`)
 
 	if err := cmd.Parse(os.Args[1:]); err != nil {
 		log.Fatalf(`cannot parse command line arguments: %v`, err)
@@ -46,12 +52,20 @@ func main() {
 		schemaWriter = f
 	}
 
+	var jsonSchema *jsonschema.Schema
+	if *validate != `` {
+		jsonSchema, err = jsonschema.ParseFile(*validate)
+		if err != nil {
+			log.Fatalf(`fail to load JSON Schema: %v`, err)
+		}
+	}
+
 	args := cmd.Args()
 	if len(args) != 2 {
 		log.Fatalln(`positional arguments <driver> and <data-source> are required`)
 	}
 
-	err = Runner{driver: args[0], dataSource: args[1], schemaReader: schemaReader, schemaWriter: schemaWriter}.Run(context.Background(), os.Stdin, os.Stdout)
+	err = Runner{driver: args[0], dataSource: args[1], format: *format, dryRun: *dryRun, order: *order, schemaReader: schemaReader, schemaWriter: schemaWriter, jsonSchema: jsonSchema}.Run(context.Background(), os.Stdin, os.Stdout)
 	if err != nil {
 		log.Fatalf(`failed execution: %v`, err)
 	}
@@ -66,12 +80,17 @@ type DBInsertInput = interface {
 	Get(i int) InsertRows
 }
 type DBInsertFunc func(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer, input DBInsertInput) error
+type DBInsertDryRunFunc func(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer, input DBInsertInput, output io.Writer) error
 
 type Runner struct {
 	driver       string
 	dataSource   string
+	format       string
+	dryRun       bool
+	order        string
 	schemaReader io.Reader
 	schemaWriter io.Writer
+	jsonSchema   *jsonschema.Schema
 }
 
 func LoadSchemaCache(schema string) (io.Reader, error) {
@@ -99,8 +118,8 @@ func LoadSchemaCache(schema string) (io.Reader, error) {
 }
 
 type dbInsertRows struct {
-	NameVal string   `json:"name"`
-	RowsVal dml.Rows `json:"rows"`
+	NameVal string   `json:"name" yaml:"name"`
+	RowsVal dml.Rows `json:"rows" yaml:"rows"`
 }
 
 func (r dbInsertRows) Name() string {
@@ -121,28 +140,93 @@ func (i dbInsertInput) Get(index int) InsertRows {
 
 func (runner Runner) Run(ctx context.Context, stdin io.Reader, stdout io.Writer) error {
 	var dbInsertFunc DBInsertFunc
+	var dbInsertDryRunFunc DBInsertDryRunFunc
+
+	switch runner.order {
+	default:
+		return fmt.Errorf(`unsupported order %s`, runner.order)
+	case `input`, `references`:
+	}
 
 	switch runner.driver {
 	default:
 		return fmt.Errorf(`unsupported driver %s`, runner.driver)
 	case `spanner`:
 		dbInsertFunc = dbinsert_spanner.DBInsertFunc
+		dbInsertDryRunFunc = dbinsert_spanner.DBInsertDryRunFunc
+		if runner.order == `references` {
+			dbInsertFunc = dbinsert_spanner.DBInsertDeferredFunc
+			dbInsertDryRunFunc = dbinsert_spanner.DBInsertDeferredDryRunFunc
+		}
 	case `sqlite3`:
-		//dbInsertFunc = dbdump_sqlite3.DBInsertFunc
+		dbInsertFunc = dbinsert_sqlite3.DBInsertFunc
+		dbInsertDryRunFunc = dbinsert_sqlite3.DBInsertDryRunFunc
+		if runner.order == `references` {
+			dbInsertFunc = dbinsert_sqlite3.DBInsertDeferredFunc
+			dbInsertDryRunFunc = dbinsert_sqlite3.DBInsertDeferredDryRunFunc
+		}
+	}
+
+	b, err := io.ReadAll(stdin)
+	if err != nil {
+		return fmt.Errorf(`fail to read input from stdin: %w`, err)
 	}
 
-	var input dbInsertInput
-	d := json.NewDecoder(stdin)
-	d.DisallowUnknownFields()
-	d.UseNumber()
-	if err := d.Decode(&input); err != nil {
-		return fmt.Errorf(`fail to decode JSON from stdin`)
+	if runner.jsonSchema != nil {
+		if err := ValidateInput(runner.jsonSchema, runner.format, b); err != nil {
+			return fmt.Errorf(`invalid input from stdin: %w`, err)
+		}
 	}
 
-	err := dbInsertFunc(ctx, runner.driver, runner.dataSource, runner.schemaReader, runner.schemaWriter, input)
+	input, err := DecodeInput(runner.format, bytes.NewBuffer(b))
 	if err != nil {
-		return fmt.Errorf(`fail to execute dbdump`)
+		return fmt.Errorf(`fail to decode input from stdin: %w`, err)
+	}
+
+	if runner.dryRun {
+		err = dbInsertDryRunFunc(ctx, runner.driver, runner.dataSource, runner.schemaReader, runner.schemaWriter, input, stdout)
+	} else {
+		err = dbInsertFunc(ctx, runner.driver, runner.dataSource, runner.schemaReader, runner.schemaWriter, input)
+	}
+	if err != nil {
+		return fmt.Errorf(`fail to execute dbinsert: %w`, err)
 	}
 
 	return nil
 }
+
+func ValidateInput(jsonSchema *jsonschema.Schema, format string, input []byte) error {
+	v := wrap.Null()
+	switch format {
+	default:
+		return fmt.Errorf(`unsupported format %s`, format)
+	case `json`:
+		if err := v.UnmarshalJSON(input); err != nil {
+			return fmt.Errorf(`fail to decode JSON: %w`, err)
+		}
+	case `yaml`:
+		b, err := fixture.YAMLToJSON(input)
+		if err != nil {
+			return fmt.Errorf(`fail to decode YAML: %w`, err)
+		}
+		if err := v.UnmarshalJSON(b); err != nil {
+			return fmt.Errorf(`fail to decode JSON: %w`, err)
+		}
+	}
+	return jsonSchema.Validate(v)
+}
+
+// DecodeInput decodes the input in format json or yaml.
+// Values in YAML input are decoded in the same way as JSON input, where timestamps remain strings and numbers are decoded as json.Number.
+func DecodeInput(format string, reader io.Reader) (dbInsertInput, error) {
+	tables, err := fixture.Decode(format, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	input := dbInsertInput{}
+	for _, table := range tables {
+		input = append(input, dbInsertRows{NameVal: table.Name, RowsVal: table.Rows})
+	}
+	return input, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/gotaface/old/json/jsonschema"
+	gotaface_spanner "github.com/Jumpaku/gotaface/old/spanner"
+	gotaface_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3"
+)
+
+func TestDecodeInput(t *testing.T) {
+	testcases := []struct {
+		name       string
+		toDBValue  func(columnType string, src any) (any, error)
+		columnType string
+		json       string
+		yaml       string
+		want       any
+	}{
+		{
+			name:       `spanner DATE`,
+			toDBValue:  gotaface_spanner.ToDBValue,
+			columnType: `DATE`,
+			json:       `"2023-01-01"`,
+			yaml:       `2023-01-01`,
+			want:       spanner.NullDate{Valid: true, Date: civil.Date{Year: 2023, Month: 1, Day: 1}},
+		},
+		{
+			name:       `spanner TIMESTAMP`,
+			toDBValue:  gotaface_spanner.ToDBValue,
+			columnType: `TIMESTAMP`,
+			json:       `"2023-01-01T00:00:00Z"`,
+			yaml:       `2023-01-01T00:00:00Z`,
+			want:       spanner.NullTime{Valid: true, Time: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:       `spanner STRING with date`,
+			toDBValue:  gotaface_spanner.ToDBValue,
+			columnType: `STRING(MAX)`,
+			json:       `"2023-01-01"`,
+			yaml:       `2023-01-01`,
+			want:       spanner.NullString{Valid: true, StringVal: `2023-01-01`},
+		},
+		{
+			name:       `spanner FLOAT64 with integer`,
+			toDBValue:  gotaface_spanner.ToDBValue,
+			columnType: `FLOAT64`,
+			json:       `1`,
+			yaml:       `1`,
+			want:       spanner.NullFloat64{Valid: true, Float64: 1},
+		},
+		{
+			name:       `spanner FLOAT64 with exponent`,
+			toDBValue:  gotaface_spanner.ToDBValue,
+			columnType: `FLOAT64`,
+			json:       `1.5e2`,
+			yaml:       `1.5e+2`,
+			want:       spanner.NullFloat64{Valid: true, Float64: 150},
+		},
+		{
+			name:       `sqlite3 TEXT with date`,
+			toDBValue:  gotaface_sqlite3.ToDBValue,
+			columnType: `TEXT`,
+			json:       `"2023-01-01"`,
+			yaml:       `2023-01-01`,
+			want:       sql.NullString{Valid: true, String: `2023-01-01`},
+		},
+		{
+			name:       `sqlite3 TEXT with timestamp`,
+			toDBValue:  gotaface_sqlite3.ToDBValue,
+			columnType: `TEXT`,
+			json:       `"2023-01-01T09:00:00+09:00"`,
+			yaml:       `2023-01-01T09:00:00+09:00`,
+			want:       sql.NullString{Valid: true, String: `2023-01-01T09:00:00+09:00`},
+		},
+		{
+			name:       `sqlite3 REAL with integer`,
+			toDBValue:  gotaface_sqlite3.ToDBValue,
+			columnType: `REAL`,
+			json:       `1`,
+			yaml:       `1`,
+			want:       sql.NullFloat64{Valid: true, Float64: 1},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			inputs := map[string]string{
+				`json`: `[{"name":"t","rows":[{"c":` + testcase.json + `}]}]`,
+				`yaml`: "- name: t\n  rows:\n    - c: " + testcase.yaml + "\n",
+			}
+			for format, input := range inputs {
+				decoded, err := DecodeInput(format, bytes.NewBufferString(input))
+				if err != nil {
+					t.Fatalf(`fail to decode %s input: %v`, format, err)
+				}
+				if decoded.Len() != 1 || len(decoded.Get(0).Rows()) != 1 {
+					t.Fatalf(`unexpected %s input: %#v`, format, decoded)
+				}
+
+				got, err := testcase.toDBValue(testcase.columnType, decoded.Get(0).Rows()[0]["c"])
+				if err != nil {
+					t.Fatalf(`fail to convert %s input: %v`, format, err)
+				}
+				if got != testcase.want {
+					t.Errorf("%s input not match\n  got  = %#v\n  want = %#v", format, got, testcase.want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateInput_YAMLTimestamp(t *testing.T) {
+	jsonSchema, err := jsonschema.Parse([]byte(`{"type":"array","items":{"type":"object","properties":{"rows":{"type":"array","items":{"type":"object","properties":{"d":{"type":"string","pattern":"^[0-9]{4}-[0-9]{2}-[0-9]{2}$"}}}}}}}`))
+	if err != nil {
+		t.Fatalf(`fail to parse JSON Schema: %v`, err)
+	}
+
+	if err := ValidateInput(jsonSchema, `yaml`, []byte("- name: t\n  rows:\n    - d: 2023-01-01\n")); err != nil {
+		t.Errorf(`YAML date must be validated as written: %v`, err)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	dbrestore_spanner "github.com/Jumpaku/gotaface/old/spanner/cli/dbrestore"
+	dbrestore_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3/cli/dbrestore"
+)
+
+//go:embed README.md
+var Usage string
+
+func main() {
+	cmd := flag.NewFlagSet("gf-dbrestore", flag.ExitOnError)
+	cmd.Usage = func() { fmt.Println(Usage) }
+
+	schema := cmd.String(`schema`, `.gf-schema.json`, `path of schema cache file`)
+
+	if err := cmd.Parse(os.Args[1:]); err != nil {
+		log.Fatalf(`cannot parse command line arguments: %v`, err)
+	}
+
+	schemaReader, err := LoadSchemaCache(*schema)
+	if err != nil {
+		log.Fatalf(`fail to load schema cache: %v`, err)
+	}
+
+	var schemaWriter io.Writer
+	if schemaReader == nil {
+		f, err := os.Create(*schema)
+		if err != nil {
+			log.Fatalf(`fail to open schema cache: %v`, err)
+		}
+		defer f.Close()
+
+		schemaWriter = f
+	}
+
+	args := cmd.Args()
+	if len(args) != 3 {
+		log.Fatalln(`positional arguments <driver>, <data-source>, and <directory> are required`)
+	}
+
+	err = Runner{driver: args[0], dataSource: args[1], directory: args[2], schemaReader: schemaReader, schemaWriter: schemaWriter}.Run(context.Background(), os.Stdin, os.Stdout)
+	if err != nil {
+		log.Fatalf(`failed execution: %v`, err)
+	}
+}
+
+type DBRestoreInput = map[string]dml.Rows
+type DBRestoreFunc func(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer, input DBRestoreInput) error
+
+type Runner struct {
+	driver       string
+	dataSource   string
+	directory    string
+	schemaReader io.Reader
+	schemaWriter io.Writer
+}
+
+func LoadSchemaCache(schema string) (io.Reader, error) {
+	fi, err := os.Stat(schema)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf(`fail to open %s: %w`, schema, err)
+	} else if fi.IsDir() {
+		return nil, fmt.Errorf(`%s must be a file`, schema)
+	}
+
+	f, err := os.Open(schema)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to open %s: %w`, schema, err)
+	}
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to read %s: %w`, schema, err)
+	}
+
+	return bytes.NewBuffer(b), nil
+}
+
+func (runner Runner) Run(ctx context.Context, stdin io.Reader, stdout io.Writer) error {
+	var dbRestoreFunc DBRestoreFunc
+
+	switch runner.driver {
+	default:
+		return fmt.Errorf(`unsupported driver %s`, runner.driver)
+	case `spanner`:
+		dbRestoreFunc = dbrestore_spanner.DBRestoreFunc
+	case `sqlite3`:
+		dbRestoreFunc = dbrestore_sqlite3.DBRestoreFunc
+	}
+
+	paths, err := filepath.Glob(filepath.Join(runner.directory, `*.json`))
+	if err != nil {
+		return fmt.Errorf(`fail to list files in %s: %w`, runner.directory, err)
+	}
+
+	input := DBRestoreInput{}
+	for _, path := range paths {
+		rows, err := readRows(path)
+		if err != nil {
+			return fmt.Errorf(`fail to read rows from %s: %w`, path, err)
+		}
+
+		input[strings.TrimSuffix(filepath.Base(path), `.json`)] = rows
+	}
+
+	if err := dbRestoreFunc(ctx, runner.driver, runner.dataSource, runner.schemaReader, runner.schemaWriter, input); err != nil {
+		return fmt.Errorf(`fail to execute dbrestore: %w`, err)
+	}
+
+	return nil
+}
+
+func readRows(path string) (dml.Rows, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to open %s: %w`, path, err)
+	}
+	defer f.Close()
+
+	var rows dml.Rows
+	d := json.NewDecoder(f)
+	d.UseNumber()
+	if err := d.Decode(&rows); err != nil {
+		return nil, fmt.Errorf(`fail to decode JSON from %s: %w`, path, err)
+	}
+
+	return rows, nil
+}
@@ -11,7 +11,10 @@ import (
 	"os"
 
 	"github.com/Jumpaku/gotaface/old/ddl/schema"
+	"github.com/Jumpaku/gotaface/old/json/jsonschema"
+	gotaface_spanner "github.com/Jumpaku/gotaface/old/spanner"
 	dbschema_spanner "github.com/Jumpaku/gotaface/old/spanner/cli/dbschema"
+	gotaface_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3"
 	dbschema_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3/cli/dbschema"
 )
 
@@ -22,6 +25,8 @@ func main() {
 	cmd := flag.NewFlagSet("gf-dbschema", flag.ExitOnError)
 	cmd.Usage = func() { fmt.Println(Usage) }
 
+	format := cmd.String(`format`, `json`, `format of output to stdout: json or json-schema`)
+
 	if err := cmd.Parse(os.Args[1:]); err != nil {
 		log.Fatalf(`cannot parse command line arguments: %v`, err)
 	}
@@ -31,7 +36,7 @@ func main() {
 		log.Fatalln(`positional arguments <driver> and <data-source> are required`)
 	}
 
-	runner := Runner{driver: args[0], dataSource: args[1]}
+	runner := Runner{driver: args[0], dataSource: args[1], format: *format}
 	err := runner.Run(context.Background(), os.Stdin, os.Stdout)
 	if err != nil {
 		log.Fatalf(`failed execution: %v`, err)
@@ -47,18 +52,28 @@ type DBSchemaFunc func(ctx context.Context, driver string, dataSource string) (D
 type Runner struct {
 	driver     string
 	dataSource string
+	format     string
 }
 
 func (runner Runner) Run(ctx context.Context, stdin io.Reader, stdout io.Writer) error {
 	var bdSchemaFunc DBSchemaFunc
+	var columnSchemaFunc jsonschema.ColumnSchemaFunc
 
 	switch runner.driver {
 	default:
 		return fmt.Errorf(`unsupported driver %s`, runner.driver)
 	case `spanner`:
 		bdSchemaFunc = dbschema_spanner.DBSchemaFunc
+		columnSchemaFunc = gotaface_spanner.ColumnJSONSchema
 	case `sqlite3`:
 		bdSchemaFunc = dbschema_sqlite3.DBSchemaFunc
+		columnSchemaFunc = gotaface_sqlite3.ColumnJSONSchema
+	}
+
+	switch runner.format {
+	default:
+		return fmt.Errorf(`unsupported format %s`, runner.format)
+	case `json`, `json-schema`:
 	}
 
 	o, err := bdSchemaFunc(ctx, runner.driver, runner.dataSource)
@@ -66,9 +81,17 @@ func (runner Runner) Run(ctx context.Context, stdin io.Reader, stdout io.Writer)
 		return fmt.Errorf(`fail to execute dbschema: %w`, err)
 	}
 
-	b, err := o.MarshalJSON()
-	if err != nil {
-		return fmt.Errorf(`fail to marshal schema to JSON: %w`, err)
+	var b []byte
+	if runner.format == `json-schema` {
+		b, err = json.MarshalIndent(jsonschema.DBInsertInput(o, columnSchemaFunc), "", "    ")
+		if err != nil {
+			return fmt.Errorf(`fail to marshal JSON Schema to JSON: %w`, err)
+		}
+	} else {
+		b, err = o.MarshalJSON()
+		if err != nil {
+			return fmt.Errorf(`fail to marshal schema to JSON: %w`, err)
+		}
 	}
 
 	if _, err = stdout.Write(b); err != nil {
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	dbsnapshot_spanner "github.com/Jumpaku/gotaface/old/spanner/cli/dbsnapshot"
+	dbsnapshot_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3/cli/dbsnapshot"
+)
+
+//go:embed README.md
+var Usage string
+
+func main() {
+	cmd := flag.NewFlagSet("gf-dbsnapshot", flag.ExitOnError)
+	cmd.Usage = func() { fmt.Println(Usage) }
+
+	schema := cmd.String(`schema`, `.gf-schema.json`, `path of schema cache file`)
+
+	if err := cmd.Parse(os.Args[1:]); err != nil {
+		log.Fatalf(`cannot parse command line arguments: %v`, err)
+	}
+
+	schemaReader, err := LoadSchemaCache(*schema)
+	if err != nil {
+		log.Fatalf(`fail to load schema cache: %v`, err)
+	}
+
+	var schemaWriter io.Writer
+	if schemaReader == nil {
+		f, err := os.Create(*schema)
+		if err != nil {
+			log.Fatalf(`fail to open schema cache: %v`, err)
+		}
+		defer f.Close()
+
+		schemaWriter = f
+	}
+
+	args := cmd.Args()
+	if len(args) != 3 {
+		log.Fatalln(`positional arguments <driver>, <data-source>, and <directory> are required`)
+	}
+
+	err = Runner{driver: args[0], dataSource: args[1], directory: args[2], schemaReader: schemaReader, schemaWriter: schemaWriter}.Run(context.Background(), os.Stdin, os.Stdout)
+	if err != nil {
+		log.Fatalf(`failed execution: %v`, err)
+	}
+}
+
+type DBSnapshotOutput = map[string]dml.Rows
+type DBSnapshotFunc func(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer) (DBSnapshotOutput, error)
+
+type Runner struct {
+	driver       string
+	dataSource   string
+	directory    string
+	schemaReader io.Reader
+	schemaWriter io.Writer
+}
+
+func LoadSchemaCache(schema string) (io.Reader, error) {
+	fi, err := os.Stat(schema)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf(`fail to open %s: %w`, schema, err)
+	} else if fi.IsDir() {
+		return nil, fmt.Errorf(`%s must be a file`, schema)
+	}
+
+	f, err := os.Open(schema)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to open %s: %w`, schema, err)
+	}
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to read %s: %w`, schema, err)
+	}
+
+	return bytes.NewBuffer(b), nil
+}
+
+func (runner Runner) Run(ctx context.Context, stdin io.Reader, stdout io.Writer) error {
+	var dbSnapshotFunc DBSnapshotFunc
+
+	switch runner.driver {
+	default:
+		return fmt.Errorf(`unsupported driver %s`, runner.driver)
+	case `spanner`:
+		dbSnapshotFunc = dbsnapshot_spanner.DBSnapshotFunc
+	case `sqlite3`:
+		dbSnapshotFunc = dbsnapshot_sqlite3.DBSnapshotFunc
+	}
+
+	output, err := dbSnapshotFunc(ctx, runner.driver, runner.dataSource, runner.schemaReader, runner.schemaWriter)
+	if err != nil {
+		return fmt.Errorf(`fail to execute dbsnapshot: %w`, err)
+	}
+
+	if err := os.MkdirAll(runner.directory, 0755); err != nil {
+		return fmt.Errorf(`fail to create directory %s: %w`, runner.directory, err)
+	}
+
+	for table, rows := range output {
+		if err := writeRows(filepath.Join(runner.directory, table+`.json`), rows); err != nil {
+			return fmt.Errorf(`fail to write rows in table %s: %w`, table, err)
+		}
+	}
+
+	return nil
+}
+
+func writeRows(path string, rows dml.Rows) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf(`fail to create %s: %w`, path, err)
+	}
+	defer f.Close()
+
+	if err := json.NewEncoder(f).Encode(rows); err != nil {
+		return fmt.Errorf(`fail to encode JSON to %s: %w`, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package dbsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type dryRunExecer struct {
+	writer io.Writer
+}
+
+var _ Execer = dryRunExecer{}
+
+// NewDryRunExecer returns an Execer which writes statements with parameters to writer instead of executing them.
+func NewDryRunExecer(writer io.Writer) dryRunExecer {
+	return dryRunExecer{writer: writer}
+}
+
+func (execer dryRunExecer) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	params := []any{}
+	for _, arg := range args {
+		if valuer, ok := arg.(driver.Valuer); ok {
+			value, err := valuer.Value()
+			if err != nil {
+				return nil, fmt.Errorf(`fail to get value of parameter %#v: %w`, arg, err)
+			}
+			arg = value
+		}
+		params = append(params, arg)
+	}
+
+	b, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to marshal parameters to JSON: %w`, err)
+	}
+	if _, err := fmt.Fprintf(execer.writer, "%s;\n-- params: %s\n", query, b); err != nil {
+		return nil, fmt.Errorf(`fail to write statement: %w`, err)
+	}
+
+	return dryRunResult{}, nil
+}
+
+type dryRunResult struct{}
+
+func (dryRunResult) LastInsertId() (int64, error) {
+	return 0, nil
+}
+
+func (dryRunResult) RowsAffected() (int64, error) {
+	return 0, nil
+}
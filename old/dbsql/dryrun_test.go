@@ -0,0 +1,25 @@
+package dbsql_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/dbsql"
+)
+
+func TestDryRunExecer_ExecContext(t *testing.T) {
+	output := bytes.NewBuffer(nil)
+	sut := dbsql.NewDryRunExecer(output)
+
+	_, err := sut.ExecContext(context.Background(), `INSERT INTO t (a,b,c) VALUES (?,?,?)`, sql.NullInt64{Valid: true, Int64: 1}, sql.NullString{}, "x")
+	if err != nil {
+		t.Fatalf(`fail to exec: %v`, err)
+	}
+
+	want := "INSERT INTO t (a,b,c) VALUES (?,?,?);\n-- params: [1,null,\"x\"]\n"
+	if got := output.String(); got != want {
+		t.Errorf("got != want\n  got  = %q\n  want = %q", got, want)
+	}
+}
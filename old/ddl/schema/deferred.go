@@ -0,0 +1,119 @@
+package schema
+
+import (
+	"github.com/Jumpaku/gotaface/old/topological"
+	"golang.org/x/exp/slices"
+)
+
+// ForeignKey is a reference from columns of a table to another table.
+type ForeignKey struct {
+	// Table is the name of the referencing table.
+	Table string
+	// Columns is the names of the referencing columns.
+	Columns []string
+	// ReferencedTable is the name of the referenced table.
+	ReferencedTable string
+	// Nullable is true if all the referencing columns accept NULL.
+	Nullable bool
+}
+
+// DeferredInsertOrder returns the indices of the tables in the schema in an order to insert rows tolerating cycles of references, and the names of the deferred columns for each table name.
+// The references between tables in the same strongly connected component are deferred if all the foreign keys of the references are nullable and do not contain primary key columns.
+// Rows can be inserted into the tables in the returned order with the deferred columns set to NULL, and then the deferred columns can be updated with their values.
+// The order is InsertOrder of the schema without the deferred references.
+// A *CycleError is returned if the references which cannot be deferred have a cycle.
+func DeferredInsertOrder(schema Schema, foreignKeys []ForeignKey) ([]int, map[string][]string, error) {
+	remaining, deferred := deferReferences(schema, foreignKeys)
+	indices, err := InsertOrder(remaining)
+	if err != nil {
+		return nil, nil, err
+	}
+	return indices, deferred, nil
+}
+
+// DeferredDeleteOrder returns the indices of the tables in the schema in the reversed order of DeferredInsertOrder, and the names of the deferred columns for each table name.
+// Rows can be deleted from the tables in the returned order after the deferred columns are updated to NULL.
+func DeferredDeleteOrder(schema Schema, foreignKeys []ForeignKey) ([]int, map[string][]string, error) {
+	remaining, deferred := deferReferences(schema, foreignKeys)
+	indices, err := DeleteOrder(remaining)
+	if err != nil {
+		return nil, nil, err
+	}
+	return indices, deferred, nil
+}
+
+// referencesSchema is a schema whose references are replaced.
+type referencesSchema struct {
+	Schema
+	references [][]int
+}
+
+func (s referencesSchema) References() [][]int {
+	return s.references
+}
+
+// deferReferences returns the schema without the deferrable references, and the names of the deferred columns for each table name.
+func deferReferences(schema Schema, foreignKeys []ForeignKey) (Schema, map[string][]string) {
+	tables := schema.Tables()
+	references := schema.References()
+
+	tableIndex := map[string]int{}
+	for index, table := range tables {
+		tableIndex[table.Name()] = index
+	}
+
+	component := make([]int, len(tables))
+	for c, vertices := range topological.StronglyConnectedComponents(references) {
+		for _, u := range vertices {
+			component[u] = c
+		}
+	}
+
+	type edge struct{ from, to int }
+	deferrable := map[edge]bool{}
+	deferredKeys := map[edge][]ForeignKey{}
+	for _, fk := range foreignKeys {
+		from, okFrom := tableIndex[fk.Table]
+		to, okTo := tableIndex[fk.ReferencedTable]
+		if !okFrom || !okTo || component[from] != component[to] {
+			continue
+		}
+		e := edge{from: from, to: to}
+		ok, exists := deferrable[e]
+		deferrable[e] = (ok || !exists) && fk.Nullable && !containsPrimaryKey(tables[from], fk.Columns)
+		deferredKeys[e] = append(deferredKeys[e], fk)
+	}
+
+	remaining := make([][]int, len(references))
+	deferred := map[string][]string{}
+	for from, tos := range references {
+		remaining[from] = []int{}
+		for _, to := range tos {
+			e := edge{from: from, to: to}
+			if !deferrable[e] {
+				remaining[from] = append(remaining[from], to)
+				continue
+			}
+			name := tables[from].Name()
+			for _, fk := range deferredKeys[e] {
+				for _, column := range fk.Columns {
+					if !slices.Contains(deferred[name], column) {
+						deferred[name] = append(deferred[name], column)
+					}
+				}
+			}
+		}
+	}
+
+	return referencesSchema{Schema: schema, references: remaining}, deferred
+}
+
+func containsPrimaryKey(table Table, columns []string) bool {
+	tableColumns := table.Columns()
+	for _, key := range table.PrimaryKey() {
+		if slices.Contains(columns, tableColumns[key].Name()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package schema_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/ddl/schema"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+func cyclicSchema() schema.SchemaFormat {
+	return schema.SchemaFormat{
+		TablesVal: []schema.TableFormat{
+			{NameVal: "Comment", ColumnsVal: []schema.ColumnFormat{{NameVal: "id"}, {NameVal: "user_id"}}, PrimaryKeyVal: []int{0}},
+			{NameVal: "User", ColumnsVal: []schema.ColumnFormat{{NameVal: "id"}, {NameVal: "team_id"}}, PrimaryKeyVal: []int{0}},
+			{NameVal: "Team", ColumnsVal: []schema.ColumnFormat{{NameVal: "id"}, {NameVal: "owner_id"}}, PrimaryKeyVal: []int{0}},
+			{NameVal: "Node", ColumnsVal: []schema.ColumnFormat{{NameVal: "id"}, {NameVal: "parent_id"}}, PrimaryKeyVal: []int{0}},
+		},
+		ReferencesVal: [][]int{{1}, {2}, {1}, {3}},
+	}
+}
+
+func TestDeferredInsertOrder(t *testing.T) {
+	s := cyclicSchema()
+	foreignKeys := []schema.ForeignKey{
+		{Table: "Comment", Columns: []string{"user_id"}, ReferencedTable: "User", Nullable: false},
+		{Table: "User", Columns: []string{"team_id"}, ReferencedTable: "Team", Nullable: true},
+		{Table: "Team", Columns: []string{"owner_id"}, ReferencedTable: "User", Nullable: false},
+		{Table: "Node", Columns: []string{"parent_id"}, ReferencedTable: "Node", Nullable: true},
+	}
+
+	got, gotDeferred, err := schema.DeferredInsertOrder(s, foreignKeys)
+	if err != nil {
+		t.Fatalf(`fail to get deferred insert order: %v`, err)
+	}
+
+	want := []int{1, 0, 2, 3}
+	if !slices.Equal(got, want) {
+		t.Errorf("got != want\n  got  = %#v\n  want = %#v", got, want)
+	}
+	wantDeferred := map[string][]string{"User": {"team_id"}, "Node": {"parent_id"}}
+	if !maps.EqualFunc(gotDeferred, wantDeferred, slices.Equal[string]) {
+		t.Errorf("got != want\n  got  = %#v\n  want = %#v", gotDeferred, wantDeferred)
+	}
+}
+
+func TestDeferredInsertOrder_Acyclic(t *testing.T) {
+	s := schema.SchemaFormat{
+		TablesVal: []schema.TableFormat{
+			{NameVal: "Comment"},
+			{NameVal: "User"},
+			{NameVal: "Team"},
+			{NameVal: "Like"},
+		},
+		ReferencesVal: [][]int{{1}, {2}, {}, {0, 1}},
+	}
+	foreignKeys := []schema.ForeignKey{
+		{Table: "Comment", Columns: []string{"user_id"}, ReferencedTable: "User", Nullable: true},
+		{Table: "User", Columns: []string{"team_id"}, ReferencedTable: "Team", Nullable: true},
+	}
+
+	got, gotDeferred, err := schema.DeferredInsertOrder(s, foreignKeys)
+	if err != nil {
+		t.Fatalf(`fail to get deferred insert order: %v`, err)
+	}
+
+	want, _ := schema.InsertOrder(s)
+	if !slices.Equal(got, want) {
+		t.Errorf("got != want\n  got  = %#v\n  want = %#v", got, want)
+	}
+	if len(gotDeferred) != 0 {
+		t.Errorf("references without cycles must not be deferred: %#v", gotDeferred)
+	}
+}
+
+func TestDeferredInsertOrder_CycleError(t *testing.T) {
+	s := cyclicSchema()
+	foreignKeys := []schema.ForeignKey{
+		{Table: "Comment", Columns: []string{"user_id"}, ReferencedTable: "User", Nullable: false},
+		{Table: "User", Columns: []string{"team_id"}, ReferencedTable: "Team", Nullable: false},
+		{Table: "Team", Columns: []string{"owner_id"}, ReferencedTable: "User", Nullable: false},
+		{Table: "Node", Columns: []string{"parent_id"}, ReferencedTable: "Node", Nullable: true},
+	}
+
+	_, _, err := schema.DeferredInsertOrder(s, foreignKeys)
+	var cycleErr *schema.CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf(`cycle not detected: %v`, err)
+	}
+
+	want := []string{"User", "Team", "User"}
+	if !slices.Equal(cycleErr.Cycle, want) {
+		t.Errorf("got != want\n  got  = %#v\n  want = %#v", cycleErr.Cycle, want)
+	}
+	wantComponents := [][]string{{"User", "Team"}}
+	if !slices.EqualFunc(cycleErr.Components, wantComponents, slices.Equal[string]) {
+		t.Errorf("got != want\n  got  = %#v\n  want = %#v", cycleErr.Components, wantComponents)
+	}
+}
+
+func TestDeferredInsertOrder_PrimaryKey(t *testing.T) {
+	s := schema.SchemaFormat{
+		TablesVal: []schema.TableFormat{
+			{NameVal: "Node", ColumnsVal: []schema.ColumnFormat{{NameVal: "id"}, {NameVal: "parent_id"}}, PrimaryKeyVal: []int{0, 1}},
+		},
+		ReferencesVal: [][]int{{0}},
+	}
+	foreignKeys := []schema.ForeignKey{
+		{Table: "Node", Columns: []string{"parent_id"}, ReferencedTable: "Node", Nullable: true},
+	}
+
+	_, _, err := schema.DeferredInsertOrder(s, foreignKeys)
+	var cycleErr *schema.CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf(`references by primary key columns must not be deferred: %v`, err)
+	}
+}
+
+func TestDeferredDeleteOrder(t *testing.T) {
+	s := cyclicSchema()
+	foreignKeys := []schema.ForeignKey{
+		{Table: "Comment", Columns: []string{"user_id"}, ReferencedTable: "User", Nullable: false},
+		{Table: "User", Columns: []string{"team_id"}, ReferencedTable: "Team", Nullable: true},
+		{Table: "Team", Columns: []string{"owner_id"}, ReferencedTable: "User", Nullable: false},
+		{Table: "Node", Columns: []string{"parent_id"}, ReferencedTable: "Node", Nullable: true},
+	}
+
+	got, _, err := schema.DeferredDeleteOrder(s, foreignKeys)
+	if err != nil {
+		t.Fatalf(`fail to get deferred delete order: %v`, err)
+	}
+
+	want := []int{3, 2, 0, 1}
+	if !slices.Equal(got, want) {
+		t.Errorf("got != want\n  got  = %#v\n  want = %#v", got, want)
+	}
+}
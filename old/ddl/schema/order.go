@@ -0,0 +1,84 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Jumpaku/gotaface/old/topological"
+)
+
+// InsertOrder returns the indices of the tables in the schema in an order such that each table precedes the tables referencing it.
+// Rows can be inserted into the tables in the returned order and deleted from the tables in the reversed order without violating references.
+// A *CycleError is returned if the references have a cycle.
+func InsertOrder(schema Schema) ([]int, error) {
+	references := schema.References()
+	order, ok := topological.Sort(references)
+	if !ok {
+		if err := NewCycleError(schema); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf(`references between tables have a cycle`)
+	}
+
+	indices := make([]int, len(references))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return order[indices[i]] > order[indices[j]]
+	})
+
+	return indices, nil
+}
+
+// DeleteOrder returns the indices of the tables in the schema in the reversed order of InsertOrder.
+func DeleteOrder(schema Schema) ([]int, error) {
+	indices, err := InsertOrder(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(indices)-1; i < j; i, j = i+1, j-1 {
+		indices[i], indices[j] = indices[j], indices[i]
+	}
+
+	return indices, nil
+}
+
+// CycleError is an error representing a cycle of references between tables.
+type CycleError struct {
+	// Cycle is the names of the tables in the cycle starting and ending at the same table, where each table references the next table.
+	Cycle []string
+	// Components is the names of the tables in each strongly connected component having cycles.
+	Components [][]string
+}
+
+// NewCycleError returns a *CycleError with a cycle of the references in the schema, or nil if the references have no cycles.
+func NewCycleError(schema Schema) *CycleError {
+	tables := schema.Tables()
+	references := schema.References()
+	cycle, ok := topological.FindCycle(references)
+	if !ok {
+		return nil
+	}
+
+	err := &CycleError{}
+	for _, index := range cycle {
+		err.Cycle = append(err.Cycle, tables[index].Name())
+	}
+	for _, component := range topological.CyclicComponents(references) {
+		names := []string{}
+		for _, index := range component {
+			names = append(names, tables[index].Name())
+		}
+		err.Components = append(err.Components, names)
+	}
+	return err
+}
+
+func (e *CycleError) Error() string {
+	n := len(e.Cycle)
+	return fmt.Sprintf(`references between tables have a cycle %s: consider deferring the reference from %s to %s`,
+		strings.Join(e.Cycle, ` → `), e.Cycle[n-2], e.Cycle[n-1])
+}
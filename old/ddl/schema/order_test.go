@@ -0,0 +1,99 @@
+package schema_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/ddl/schema"
+	"golang.org/x/exp/slices"
+)
+
+func TestInsertOrder(t *testing.T) {
+	s := schema.SchemaFormat{
+		TablesVal: []schema.TableFormat{
+			{NameVal: "Comment"},
+			{NameVal: "User"},
+			{NameVal: "Team"},
+			{NameVal: "Like"},
+		},
+		ReferencesVal: [][]int{{1}, {2}, {}, {0, 1}},
+	}
+
+	got, err := schema.InsertOrder(s)
+	if err != nil {
+		t.Fatalf(`fail to get insert order: %v`, err)
+	}
+
+	want := []int{2, 1, 0, 3}
+	if !slices.Equal(got, want) {
+		t.Errorf("got != want\n  got  = %#v\n  want = %#v", got, want)
+	}
+}
+
+func TestInsertOrder_Cycle(t *testing.T) {
+	s := schema.SchemaFormat{
+		TablesVal: []schema.TableFormat{
+			{NameVal: "User"},
+			{NameVal: "Team"},
+		},
+		ReferencesVal: [][]int{{1}, {0}},
+	}
+
+	_, err := schema.InsertOrder(s)
+	if err == nil {
+		t.Errorf(`cycle not detected`)
+	}
+}
+
+func TestInsertOrder_CycleError(t *testing.T) {
+	s := schema.SchemaFormat{
+		TablesVal: []schema.TableFormat{
+			{NameVal: "Comment"},
+			{NameVal: "User"},
+			{NameVal: "Team"},
+			{NameVal: "Node"},
+		},
+		ReferencesVal: [][]int{{1}, {2}, {1}, {3}},
+	}
+
+	_, err := schema.InsertOrder(s)
+	var cycleErr *schema.CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf(`cycle not detected: %v`, err)
+	}
+
+	want := []string{"User", "Team", "User"}
+	if !slices.Equal(cycleErr.Cycle, want) {
+		t.Errorf("got != want\n  got  = %#v\n  want = %#v", cycleErr.Cycle, want)
+	}
+	wantComponents := [][]string{{"User", "Team"}, {"Node"}}
+	if !slices.EqualFunc(cycleErr.Components, wantComponents, slices.Equal[string]) {
+		t.Errorf("got != want\n  got  = %#v\n  want = %#v", cycleErr.Components, wantComponents)
+	}
+	wantMessage := `references between tables have a cycle User → Team → User: consider deferring the reference from Team to User`
+	if err.Error() != wantMessage {
+		t.Errorf("got != want\n  got  = %s\n  want = %s", err.Error(), wantMessage)
+	}
+}
+
+func TestDeleteOrder(t *testing.T) {
+	s := schema.SchemaFormat{
+		TablesVal: []schema.TableFormat{
+			{NameVal: "Comment"},
+			{NameVal: "User"},
+			{NameVal: "Team"},
+			{NameVal: "Like"},
+		},
+		ReferencesVal: [][]int{{1}, {2}, {}, {0, 1}},
+	}
+
+	got, err := schema.DeleteOrder(s)
+	if err != nil {
+		t.Fatalf(`fail to get delete order: %v`, err)
+	}
+
+	want := []int{3, 0, 1, 2}
+	if !slices.Equal(got, want) {
+		t.Errorf("got != want\n  got  = %#v\n  want = %#v", got, want)
+	}
+}
@@ -0,0 +1,209 @@
+package anonymize
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+)
+
+const (
+	// RuleKeep keeps the value as it is.
+	RuleKeep = `keep`
+	// RuleNull replaces the value with NULL.
+	RuleNull = `null`
+	// RuleConstant replaces the value with the value of the rule.
+	RuleConstant = `constant`
+	// RuleHash replaces the value with a deterministic hash of the value, which has the same type as the value.
+	// Strings in the formats of TIMESTAMP, DATE, and NUMERIC values are replaced with strings in the same formats, and arrays and objects are hashed element by element.
+	RuleHash = `hash`
+	// RuleFakeName replaces the value with a fake name determined by the hash of the value.
+	RuleFakeName = `fake_name`
+	// RuleFakeEmail replaces the value with a fake email address determined by the hash of the value.
+	RuleFakeEmail = `fake_email`
+)
+
+type Rule struct {
+	Type  string `json:"type"`
+	Value any    `json:"value,omitempty"`
+}
+
+type Config struct {
+	// Salt is prepended to values before hashing.
+	Salt string `json:"salt"`
+	// Tables is a mapping from table name to mapping from column name to rule.
+	// Columns without rules are kept as they are.
+	Tables map[string]map[string]Rule `json:"tables"`
+}
+
+type anonymizer struct {
+	config Config
+}
+
+// NewAnonymizer returns an anonymizer which transforms values in rows according to the rules in config.
+// Since the same values are transformed into the same values by hash, fake_name, and fake_email rules, foreign keys are kept consistent if the key columns in the referencing and referenced tables have the same rules.
+func NewAnonymizer(config Config) (anonymizer, error) {
+	for table, columns := range config.Tables {
+		for column, rule := range columns {
+			switch rule.Type {
+			default:
+				return anonymizer{}, fmt.Errorf(`unsupported rule type %q for column %s in table %s`, rule.Type, column, table)
+			case RuleKeep, RuleNull, RuleConstant, RuleHash, RuleFakeName, RuleFakeEmail:
+			}
+		}
+	}
+	return anonymizer{config: config}, nil
+}
+
+// Anonymize returns rows transformed according to the rules for the table.
+// The values in rows must be plain Go values such as those returned by FromDBValue of each driver.
+func (a anonymizer) Anonymize(table string, rows dml.Rows) (dml.Rows, error) {
+	rules, ok := a.config.Tables[table]
+	if !ok {
+		return rows, nil
+	}
+
+	anonymized := dml.Rows{}
+	for _, row := range rows {
+		anonymizedRow := dml.Row{}
+		for column, value := range row {
+			rule, ok := rules[column]
+			if !ok {
+				anonymizedRow[column] = value
+				continue
+			}
+
+			var err error
+			anonymizedRow[column], err = a.apply(rule, value)
+			if err != nil {
+				return nil, fmt.Errorf(`fail to apply rule %s to column %s in table %s: %w`, rule.Type, column, table, err)
+			}
+		}
+		anonymized = append(anonymized, anonymizedRow)
+	}
+
+	return anonymized, nil
+}
+
+func (a anonymizer) apply(rule Rule, value any) (any, error) {
+	switch rule.Type {
+	case RuleNull:
+		return nil, nil
+	case RuleConstant:
+		return rule.Value, nil
+	}
+
+	if value == nil || rule.Type == RuleKeep {
+		return value, nil
+	}
+	if rule.Type == RuleHash {
+		return a.hash(value)
+	}
+
+	sum, err := a.sum(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch rule.Type {
+	default:
+		return nil, fmt.Errorf(`unsupported rule type %q`, rule.Type)
+	case RuleFakeName:
+		first := fakeFirstNames[int(sum[0])%len(fakeFirstNames)]
+		last := fakeLastNames[int(sum[1])%len(fakeLastNames)]
+		return fmt.Sprintf(`%s %s`, first, last), nil
+	case RuleFakeEmail:
+		return fmt.Sprintf(`user-%s@example.com`, hex.EncodeToString(sum[:6])), nil
+	}
+}
+
+// hash returns a deterministic hash of the value of the same type, so that it can be converted into the type of the column of the value.
+func (a anonymizer) hash(value any) (any, error) {
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		hashed := []any{}
+		for _, element := range value {
+			h, err := a.hash(element)
+			if err != nil {
+				return nil, err
+			}
+			hashed = append(hashed, h)
+		}
+		return hashed, nil
+	case map[string]any:
+		hashed := map[string]any{}
+		for key, element := range value {
+			h, err := a.hash(element)
+			if err != nil {
+				return nil, err
+			}
+			hashed[key] = h
+		}
+		return hashed, nil
+	}
+
+	sum, err := a.sum(value)
+	if err != nil {
+		return nil, err
+	}
+	high, low := binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:16])
+
+	switch value := value.(type) {
+	default:
+		return hex.EncodeToString(sum[:16]), nil
+	case int64:
+		return int64(high >> 1), nil
+	case float64:
+		// an integer which is exactly representable in float64.
+		return float64(high >> 11), nil
+	case bool:
+		return sum[0]&1 == 1, nil
+	case []byte:
+		return sum[:16], nil
+	case string:
+		if _, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			// a timestamp before 2100-01-01.
+			return time.Unix(int64(high%4_102_444_800), int64(low%1_000_000_000)).UTC().Format(time.RFC3339Nano), nil
+		}
+		if _, err := time.Parse(time.DateOnly, value); err == nil {
+			return time.Unix(int64(high%4_102_444_800), 0).UTC().Format(time.DateOnly), nil
+		}
+		if integerPattern.MatchString(value) {
+			return fmt.Sprintf(`%d`, high%1_000_000_000_000), nil
+		}
+		if decimalPattern.MatchString(value) {
+			return fmt.Sprintf(`%d.%09d`, high%1_000_000_000_000, low%1_000_000_000), nil
+		}
+		return hex.EncodeToString(sum[:16]), nil
+	}
+}
+
+var (
+	integerPattern = regexp.MustCompile(`^[+-]?\d+$`)
+	decimalPattern = regexp.MustCompile(`^[+-]?(\d+\.\d*|\.\d+)$`)
+)
+
+func (a anonymizer) sum(value any) ([32]byte, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf(`fail to marshal %v to JSON: %w`, value, err)
+	}
+	return sha256.Sum256(append([]byte(a.config.Salt+"\x00"), b...)), nil
+}
+
+var fakeFirstNames = []string{
+	`Alice`, `Bob`, `Carol`, `Dave`, `Ellen`, `Frank`, `Grace`, `Heidi`,
+	`Ivan`, `Judy`, `Kevin`, `Linda`, `Mallory`, `Nancy`, `Oscar`, `Peggy`,
+}
+
+var fakeLastNames = []string{
+	`Smith`, `Johnson`, `Williams`, `Brown`, `Jones`, `Garcia`, `Miller`, `Davis`,
+	`Wilson`, `Moore`, `Taylor`, `Anderson`, `Thomas`, `Jackson`, `White`, `Harris`,
+}
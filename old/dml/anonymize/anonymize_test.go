@@ -0,0 +1,177 @@
+package anonymize_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/anonymize"
+)
+
+func TestAnonymizer_Anonymize(t *testing.T) {
+	sut, err := anonymize.NewAnonymizer(anonymize.Config{
+		Salt: "salt",
+		Tables: map[string]map[string]anonymize.Rule{
+			"User": {
+				"id":      {Type: anonymize.RuleHash},
+				"name":    {Type: anonymize.RuleFakeName},
+				"email":   {Type: anonymize.RuleFakeEmail},
+				"phone":   {Type: anonymize.RuleNull},
+				"country": {Type: anonymize.RuleConstant, Value: "JP"},
+				"isGuest": {Type: anonymize.RuleKeep},
+			},
+			"Comment": {
+				"userId": {Type: anonymize.RuleHash},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf(`fail to create anonymizer: %v`, err)
+	}
+
+	users, err := sut.Anonymize("User", dml.Rows{
+		{"id": int64(1), "name": "Jumpaku", "email": "jumpaku@example.org", "phone": "000-0000-0000", "country": "US", "isGuest": false, "note": "abc"},
+		{"id": int64(2), "name": nil, "email": nil, "phone": nil, "country": nil, "isGuest": true, "note": nil},
+	})
+	if err != nil {
+		t.Fatalf(`fail to anonymize: %v`, err)
+	}
+	comments, err := sut.Anonymize("Comment", dml.Rows{
+		{"id": int64(1), "userId": int64(1)},
+	})
+	if err != nil {
+		t.Fatalf(`fail to anonymize: %v`, err)
+	}
+	others, err := sut.Anonymize("Other", dml.Rows{
+		{"id": int64(1)},
+	})
+	if err != nil {
+		t.Fatalf(`fail to anonymize: %v`, err)
+	}
+
+	got := users[0]
+	if id, ok := got["id"].(int64); !ok || id == 1 || id < 0 {
+		t.Errorf(`id is not hashed: %#v`, got["id"])
+	}
+	if name, ok := got["name"].(string); !ok || name == "Jumpaku" || !strings.Contains(name, " ") {
+		t.Errorf(`name is not fake: %#v`, got["name"])
+	}
+	if email, ok := got["email"].(string); !ok || !strings.HasSuffix(email, "@example.com") {
+		t.Errorf(`email is not fake: %#v`, got["email"])
+	}
+	if got["phone"] != nil {
+		t.Errorf(`phone is not null: %#v`, got["phone"])
+	}
+	if got["country"] != "JP" {
+		t.Errorf(`country is not constant: %#v`, got["country"])
+	}
+	if got["isGuest"] != false {
+		t.Errorf(`isGuest is not kept: %#v`, got["isGuest"])
+	}
+	if got["note"] != "abc" {
+		t.Errorf(`note is not kept: %#v`, got["note"])
+	}
+
+	got = users[1]
+	for _, column := range []string{"name", "email", "phone"} {
+		if got[column] != nil {
+			t.Errorf(`%s must be null: %#v`, column, got[column])
+		}
+	}
+	if got["country"] != "JP" {
+		t.Errorf(`country is not constant: %#v`, got["country"])
+	}
+
+	if comments[0]["userId"] != users[0]["id"] {
+		t.Errorf("hashed keys are inconsistent\n  Comment.userId = %#v\n  User.id        = %#v", comments[0]["userId"], users[0]["id"])
+	}
+	if others[0]["id"] != int64(1) {
+		t.Errorf(`rows without rules are changed: %#v`, others[0])
+	}
+}
+
+func TestAnonymizer_Anonymize_Hash(t *testing.T) {
+	sut, err := anonymize.NewAnonymizer(anonymize.Config{
+		Tables: map[string]map[string]anonymize.Rule{"t": {"c": {Type: anonymize.RuleHash}}},
+	})
+	if err != nil {
+		t.Fatalf(`fail to create anonymizer: %v`, err)
+	}
+
+	for i, value := range []any{int64(123), float64(1.5), "abc", []byte("abc"), true} {
+		got, err := sut.Anonymize("t", dml.Rows{{"c": value}, {"c": value}})
+		if err != nil {
+			t.Fatalf(`%d: fail to anonymize: %v`, i, err)
+		}
+		switch value := value.(type) {
+		case []byte:
+			a, ok := got[0]["c"].([]byte)
+			b, _ := got[1]["c"].([]byte)
+			if !ok || string(a) != string(b) || string(a) == string(value) {
+				t.Errorf("%d: not hashed deterministically: %#v", i, got)
+			}
+		case bool:
+			if _, ok := got[0]["c"].(bool); !ok || got[0]["c"] != got[1]["c"] {
+				t.Errorf("%d: not hashed deterministically: %#v", i, got)
+			}
+		default:
+			if got[0]["c"] != got[1]["c"] || got[0]["c"] == value {
+				t.Errorf("%d: not hashed deterministically: %#v", i, got)
+			}
+		}
+	}
+}
+
+func TestAnonymizer_Anonymize_HashFormat(t *testing.T) {
+	sut, err := anonymize.NewAnonymizer(anonymize.Config{
+		Tables: map[string]map[string]anonymize.Rule{"t": {"c": {Type: anonymize.RuleHash}}},
+	})
+	if err != nil {
+		t.Fatalf(`fail to create anonymizer: %v`, err)
+	}
+
+	testCases := []struct {
+		name    string
+		value   any
+		pattern string
+	}{
+		{name: "TIMESTAMP", value: "2023-06-11T01:23:45.5Z", pattern: `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?Z$`},
+		{name: "DATE", value: "2023-06-11", pattern: `^\d{4}-\d{2}-\d{2}$`},
+		{name: "NUMERIC integer", value: "-123", pattern: `^\d+$`},
+		{name: "NUMERIC decimal", value: "1.5", pattern: `^\d+\.\d{9}$`},
+		{name: "STRING", value: "abc", pattern: `^[0-9a-f]{32}$`},
+		{name: "ARRAY", value: []any{"2023-06-11", nil}, pattern: `^\["\d{4}-\d{2}-\d{2}",null\]$`},
+		{name: "JSON", value: map[string]any{"n": int64(1)}, pattern: `^\{"n":\d+\}$`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := sut.Anonymize("t", dml.Rows{{"c": testCase.value}})
+			if err != nil {
+				t.Fatalf(`fail to anonymize: %v`, err)
+			}
+			b, err := json.Marshal(got[0]["c"])
+			if err != nil {
+				t.Fatalf(`fail to marshal: %v`, err)
+			}
+			var s string
+			if json.Unmarshal(b, &s) != nil {
+				s = string(b)
+			}
+			if !regexp.MustCompile(testCase.pattern).MatchString(s) {
+				t.Errorf("hash not match format\n  got     = %s\n  pattern = %s", s, testCase.pattern)
+			}
+		})
+	}
+}
+
+func TestNewAnonymizer_UnsupportedRule(t *testing.T) {
+	_, err := anonymize.NewAnonymizer(anonymize.Config{
+		Tables: map[string]map[string]anonymize.Rule{"t": {"c": {Type: "unknown"}}},
+	})
+	if err == nil {
+		t.Errorf(`unsupported rule is not detected`)
+	}
+}
@@ -0,0 +1,56 @@
+package delete
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Jumpaku/gotaface/old/ddl/schema"
+)
+
+// Clearer sets the columns of all rows in a table to NULL.
+type Clearer interface {
+	Clear(ctx context.Context, table string, columns []string) error
+}
+
+// DeleteDeferred deletes all rows in the tables in the order of schema.DeferredDeleteOrder.
+// The deferred columns of the tables are set to NULL before any rows are deleted so that the rows in the tables referencing each other in cycles can be deleted.
+func DeleteDeferred(ctx context.Context, s schema.Schema, foreignKeys []schema.ForeignKey, deleter Deleter, clearer Clearer, tables []string) error {
+	order, deferred, err := schema.DeferredDeleteOrder(s, foreignKeys)
+	if err != nil {
+		return fmt.Errorf(`fail to get delete order: %w`, err)
+	}
+
+	tableNames := map[string]bool{}
+	for _, table := range s.Tables() {
+		tableNames[table.Name()] = true
+	}
+	targets := map[string]bool{}
+	for _, table := range tables {
+		if !tableNames[table] {
+			return fmt.Errorf(`table %s not found in schema`, table)
+		}
+		targets[table] = true
+	}
+
+	ordered := []string{}
+	for _, index := range order {
+		if name := s.Tables()[index].Name(); targets[name] {
+			ordered = append(ordered, name)
+		}
+	}
+
+	for _, table := range ordered {
+		if columns := deferred[table]; len(columns) > 0 {
+			if err := clearer.Clear(ctx, table, columns); err != nil {
+				return fmt.Errorf(`fail to clear deferred columns in table %s: %w`, table, err)
+			}
+		}
+	}
+	for _, table := range ordered {
+		if err := deleter.Delete(ctx, table); err != nil {
+			return fmt.Errorf(`fail to delete rows in table %s: %w`, table, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package delete_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/ddl/schema"
+	"github.com/Jumpaku/gotaface/old/dml/delete"
+	"golang.org/x/exp/slices"
+)
+
+type recorder struct {
+	calls []string
+}
+
+func (r *recorder) Delete(ctx context.Context, table string) error {
+	r.calls = append(r.calls, fmt.Sprintf(`delete %s`, table))
+	return nil
+}
+
+func (r *recorder) Clear(ctx context.Context, table string, columns []string) error {
+	r.calls = append(r.calls, fmt.Sprintf(`clear %s %v`, table, columns))
+	return nil
+}
+
+func TestDeleteDeferred(t *testing.T) {
+	s := schema.SchemaFormat{
+		TablesVal: []schema.TableFormat{
+			{NameVal: "User", ColumnsVal: []schema.ColumnFormat{{NameVal: "id"}, {NameVal: "team_id"}}, PrimaryKeyVal: []int{0}},
+			{NameVal: "Team", ColumnsVal: []schema.ColumnFormat{{NameVal: "id"}, {NameVal: "owner_id"}}, PrimaryKeyVal: []int{0}},
+			{NameVal: "Comment", ColumnsVal: []schema.ColumnFormat{{NameVal: "id"}, {NameVal: "reply_to"}}, PrimaryKeyVal: []int{0}},
+		},
+		ReferencesVal: [][]int{{1}, {0}, {2}},
+	}
+	foreignKeys := []schema.ForeignKey{
+		{Table: "User", Columns: []string{"team_id"}, ReferencedTable: "Team", Nullable: true},
+		{Table: "Team", Columns: []string{"owner_id"}, ReferencedTable: "User", Nullable: false},
+		{Table: "Comment", Columns: []string{"reply_to"}, ReferencedTable: "Comment", Nullable: true},
+	}
+
+	r := &recorder{}
+	if err := delete.DeleteDeferred(context.Background(), s, foreignKeys, r, r, []string{"User", "Team", "Comment"}); err != nil {
+		t.Fatalf(`fail to delete rows: %v`, err)
+	}
+
+	want := []string{
+		`clear Comment [reply_to]`,
+		`clear User [team_id]`,
+		`delete Comment`,
+		`delete Team`,
+		`delete User`,
+	}
+	if !slices.Equal(r.calls, want) {
+		t.Errorf("got != want\n  got  = %#v\n  want = %#v", r.calls, want)
+	}
+}
+
+func TestDeleteDeferred_UnknownTable(t *testing.T) {
+	s := schema.SchemaFormat{TablesVal: []schema.TableFormat{{NameVal: "User"}}, ReferencesVal: [][]int{{}}}
+
+	r := &recorder{}
+	if err := delete.DeleteDeferred(context.Background(), s, nil, r, r, []string{"Team"}); err == nil {
+		t.Errorf(`unknown table is not detected`)
+	}
+	if len(r.calls) != 0 {
+		t.Errorf(`rows must not be deleted: %v`, r.calls)
+	}
+}
@@ -0,0 +1,307 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/json/wrap"
+)
+
+type ColumnDiff struct {
+	Column string `json:"column"`
+	Left   any    `json:"left"`
+	Right  any    `json:"right"`
+}
+
+type ChangedRow struct {
+	// Key is the values in the primary key of the changed row.
+	Key     dml.Row      `json:"key"`
+	Columns []ColumnDiff `json:"columns"`
+}
+
+type TableDiff struct {
+	// Inserted is rows which exist only in the right.
+	Inserted dml.Rows `json:"inserted"`
+	// Deleted is rows which exist only in the left.
+	Deleted dml.Rows `json:"deleted"`
+	// Changed is rows which exist in both but have different values.
+	Changed []ChangedRow `json:"changed"`
+}
+
+// Empty returns true if there are no differences.
+func (d TableDiff) Empty() bool {
+	return len(d.Inserted) == 0 && len(d.Deleted) == 0 && len(d.Changed) == 0
+}
+
+// RowIterator yields rows in the ascending order of the primary key.
+type RowIterator interface {
+	// Next returns the next row, or io.EOF if there are no more rows.
+	Next() (dml.Row, error)
+}
+
+type rowsIterator struct {
+	rows  dml.Rows
+	index int
+}
+
+// Iterate returns a RowIterator which yields rows in the order of rows.
+func Iterate(rows dml.Rows) RowIterator {
+	return &rowsIterator{rows: rows}
+}
+
+func (i *rowsIterator) Next() (dml.Row, error) {
+	if i.index >= len(i.rows) {
+		return nil, io.EOF
+	}
+	row := i.rows[i.index]
+	i.index++
+	return row, nil
+}
+
+// Diff compares rows in the left and the right keyed by the primary key and returns the differences.
+// It is equivalent to DiffRows with the rows iterated by Iterate.
+func Diff(primaryKey []string, left dml.Rows, right dml.Rows) (TableDiff, error) {
+	return DiffRows(primaryKey, Iterate(left), Iterate(right))
+}
+
+// DiffRows compares rows yielded by the left and the right keyed by the primary key and returns the differences.
+// The values in rows must be plain Go values such as those returned by FromDBValue of each driver or those decoded from JSON.
+// Values are normalized through JSON before comparison so that, e.g., []byte from a database and its base64 string in a dump file are equal, and numbers are compared by their numeric values.
+// Rows are merged one by one without loading all of them, so they must be yielded in the ascending order of the primary key as normalized values, where NULL < BOOL < number < string and strings are compared byte by byte.
+// Rows dumped from a database are not always in this order, e.g., rows with TIMESTAMP or BYTES keys, so they should be sorted by SortRows.
+// Rows out of the order or with a duplicated primary key are reported as an error.
+func DiffRows(primaryKey []string, left RowIterator, right RowIterator) (TableDiff, error) {
+	if len(primaryKey) == 0 {
+		return TableDiff{}, fmt.Errorf(`primary key is required`)
+	}
+
+	l := &cursor{primaryKey: primaryKey, rows: left}
+	if err := l.next(); err != nil {
+		return TableDiff{}, fmt.Errorf(`fail to read rows in left: %w`, err)
+	}
+	r := &cursor{primaryKey: primaryKey, rows: right}
+	if err := r.next(); err != nil {
+		return TableDiff{}, fmt.Errorf(`fail to read rows in right: %w`, err)
+	}
+
+	diff := TableDiff{Inserted: dml.Rows{}, Deleted: dml.Rows{}, Changed: []ChangedRow{}}
+	for l.row != nil || r.row != nil {
+		var c int
+		switch {
+		case l.row == nil:
+			c = 1
+		case r.row == nil:
+			c = -1
+		default:
+			var err error
+			c, err = compareKeys(primaryKey, l.row, r.row)
+			if err != nil {
+				return TableDiff{}, fmt.Errorf(`fail to compare rows: %w`, err)
+			}
+		}
+
+		switch {
+		case c < 0:
+			diff.Deleted = append(diff.Deleted, l.row)
+		case c > 0:
+			diff.Inserted = append(diff.Inserted, r.row)
+		default:
+			if columns := diffColumns(l.row, r.row); len(columns) > 0 {
+				key := dml.Row{}
+				for _, column := range primaryKey {
+					key[column] = l.row[column]
+				}
+				diff.Changed = append(diff.Changed, ChangedRow{Key: key, Columns: columns})
+			}
+		}
+
+		if c <= 0 {
+			if err := l.next(); err != nil {
+				return TableDiff{}, fmt.Errorf(`fail to read rows in left: %w`, err)
+			}
+		}
+		if c >= 0 {
+			if err := r.next(); err != nil {
+				return TableDiff{}, fmt.Errorf(`fail to read rows in right: %w`, err)
+			}
+		}
+	}
+
+	return diff, nil
+}
+
+// SortRows returns the normalized rows sorted in the ascending order of the primary key, so that rows which are not ordered, such as rows written by hand, can be compared by DiffRows.
+func SortRows(primaryKey []string, rows dml.Rows) (dml.Rows, error) {
+	sorted := dml.Rows{}
+	for _, row := range rows {
+		normalized, err := normalizeRow(row)
+		if err != nil {
+			return nil, err
+		}
+		sorted = append(sorted, normalized)
+	}
+
+	var err error
+	sort.SliceStable(sorted, func(i, j int) bool {
+		c, e := compareKeys(primaryKey, sorted[i], sorted[j])
+		if e != nil && err == nil {
+			err = e
+		}
+		return c < 0
+	})
+	if err != nil {
+		return nil, fmt.Errorf(`fail to compare rows: %w`, err)
+	}
+	return sorted, nil
+}
+
+// cursor holds the current normalized row of rows, which is nil after the last row.
+type cursor struct {
+	primaryKey []string
+	rows       RowIterator
+	row        dml.Row
+}
+
+// next advances the cursor and checks that the rows are in the ascending order of the primary key.
+func (c *cursor) next() error {
+	row, err := c.rows.Next()
+	if errors.Is(err, io.EOF) {
+		c.row = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	normalized, err := normalizeRow(row)
+	if err != nil {
+		return err
+	}
+	if c.row != nil {
+		order, err := compareKeys(c.primaryKey, c.row, normalized)
+		if err != nil {
+			return fmt.Errorf(`fail to compare rows: %w`, err)
+		}
+		if order >= 0 {
+			b, _ := json.Marshal(normalized)
+			if order == 0 {
+				return fmt.Errorf(`primary key is duplicated in row %s`, string(b))
+			}
+			return fmt.Errorf(`rows must be ordered by primary key but row %s is out of order`, string(b))
+		}
+	}
+	c.row = normalized
+	return nil
+}
+
+func normalizeRow(row dml.Row) (dml.Row, error) {
+	b, err := json.Marshal(row)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to marshal row %v: %w`, row, err)
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var normalized dml.Row
+	if err := d.Decode(&normalized); err != nil {
+		return nil, fmt.Errorf(`fail to unmarshal row %s: %w`, string(b), err)
+	}
+	return normalized, nil
+}
+
+func compareKeys(primaryKey []string, a dml.Row, b dml.Row) (int, error) {
+	for _, column := range primaryKey {
+		c, err := compareValues(a[column], b[column])
+		if err != nil {
+			return 0, fmt.Errorf(`fail to compare values in column %s: %w`, column, err)
+		}
+		if c != 0 {
+			return c, nil
+		}
+	}
+	return 0, nil
+}
+
+// compareValues compares normalized values, where NULL < BOOL < number < string.
+func compareValues(a any, b any) (int, error) {
+	rankA, err := rank(a)
+	if err != nil {
+		return 0, err
+	}
+	rankB, err := rank(b)
+	if err != nil {
+		return 0, err
+	}
+	if rankA != rankB {
+		return rankA - rankB, nil
+	}
+
+	switch a := a.(type) {
+	default:
+		return 0, nil
+	case bool:
+		switch {
+		case a == b.(bool):
+			return 0, nil
+		case !a:
+			return -1, nil
+		default:
+			return 1, nil
+		}
+	case json.Number:
+		x, ok := new(big.Float).SetString(a.String())
+		if !ok {
+			return 0, fmt.Errorf(`fail to parse number %s`, a)
+		}
+		y, ok := new(big.Float).SetString(b.(json.Number).String())
+		if !ok {
+			return 0, fmt.Errorf(`fail to parse number %s`, b)
+		}
+		return x.Cmp(y), nil
+	case string:
+		return strings.Compare(a, b.(string)), nil
+	}
+}
+
+func rank(v any) (int, error) {
+	switch v.(type) {
+	default:
+		return 0, fmt.Errorf(`unsupported value in primary key: %#v`, v)
+	case nil:
+		return 0, nil
+	case bool:
+		return 1, nil
+	case json.Number:
+		return 2, nil
+	case string:
+		return 3, nil
+	}
+}
+
+// diffColumns returns the columns having different normalized values, where numbers are compared by their numeric values.
+func diffColumns(left dml.Row, right dml.Row) []ColumnDiff {
+	columns := []string{}
+	for column := range left {
+		columns = append(columns, column)
+	}
+	for column := range right {
+		if _, ok := left[column]; !ok {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+
+	diffs := []ColumnDiff{}
+	for _, column := range columns {
+		if !wrap.Equal(wrap.Encode(left[column]), wrap.Encode(right[column])) {
+			diffs = append(diffs, ColumnDiff{Column: column, Left: left[column], Right: right[column]})
+		}
+	}
+	return diffs
+}
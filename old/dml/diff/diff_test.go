@@ -0,0 +1,94 @@
+package diff_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/diff"
+)
+
+func TestDiff(t *testing.T) {
+	left := dml.Rows{
+		{"id": int64(1), "name": "a", "icon": []byte("x")},
+		{"id": int64(2), "name": "b", "icon": nil},
+		{"id": int64(3), "name": "c", "icon": nil},
+	}
+	right := dml.Rows{
+		{"id": json.Number("1"), "name": "a", "icon": "eA=="},
+		{"id": json.Number("3"), "name": "C", "icon": "eA=="},
+		{"id": json.Number("4"), "name": "d", "icon": nil},
+	}
+
+	got, err := diff.Diff([]string{"id"}, left, right)
+	if err != nil {
+		t.Fatalf(`fail to diff: %v`, err)
+	}
+
+	b, _ := json.Marshal(got)
+	want := `{"inserted":[{"icon":null,"id":4,"name":"d"}],` +
+		`"deleted":[{"icon":null,"id":2,"name":"b"}],` +
+		`"changed":[{"key":{"id":3},"columns":[{"column":"icon","left":null,"right":"eA=="},{"column":"name","left":"c","right":"C"}]}]}`
+	if string(b) != want {
+		t.Errorf("diff not match\n  got  = %s\n  want = %s", string(b), want)
+	}
+	if got.Empty() {
+		t.Errorf(`diff must not be empty`)
+	}
+}
+
+func TestDiff_Same(t *testing.T) {
+	rows := dml.Rows{
+		{"k1": "a", "k2": int64(1), "v": 1.5},
+		{"k1": "a", "k2": int64(2), "v": nil},
+		{"k1": "b", "k2": int64(1), "v": true},
+	}
+
+	got, err := diff.Diff([]string{"k1", "k2"}, rows, rows)
+	if err != nil {
+		t.Fatalf(`fail to diff: %v`, err)
+	}
+	if !got.Empty() {
+		t.Errorf(`diff must be empty: %v`, got)
+	}
+}
+
+func TestDiff_DuplicatedKey(t *testing.T) {
+	_, err := diff.Diff([]string{"id"}, dml.Rows{{"id": int64(1)}, {"id": int64(1)}}, dml.Rows{})
+	if err == nil {
+		t.Errorf(`duplicated primary key is not detected`)
+	}
+}
+
+func TestDiff_Number(t *testing.T) {
+	left := dml.Rows{{"id": int64(1), "score": float64(1)}}
+	right := dml.Rows{{"id": json.Number("1.0"), "score": json.Number("1e0")}}
+
+	got, err := diff.Diff([]string{"id"}, left, right)
+	if err != nil {
+		t.Fatalf(`fail to diff: %v`, err)
+	}
+	if !got.Empty() {
+		t.Errorf(`numbers must be compared by their numeric values: %v`, got)
+	}
+}
+
+func TestDiff_Unordered(t *testing.T) {
+	_, err := diff.Diff([]string{"id"}, dml.Rows{}, dml.Rows{{"id": int64(2)}, {"id": int64(1)}})
+	if err == nil {
+		t.Errorf(`rows out of order are not detected`)
+	}
+}
+
+func TestSortRows(t *testing.T) {
+	rows, err := diff.SortRows([]string{"id"}, dml.Rows{{"id": int64(2)}, {"id": json.Number("10")}, {"id": 1.5}})
+	if err != nil {
+		t.Fatalf(`fail to sort: %v`, err)
+	}
+
+	b, _ := json.Marshal(rows)
+	want := `[{"id":1.5},{"id":2},{"id":10}]`
+	if string(b) != want {
+		t.Errorf("sorted rows not match\n  got  = %s\n  want = %s", string(b), want)
+	}
+}
@@ -0,0 +1,223 @@
+package fixture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	"gopkg.in/yaml.v3"
+)
+
+// Table is rows in a table in the input format of gf-dbinsert.
+type Table struct {
+	Name string   `json:"name" yaml:"name"`
+	Rows dml.Rows `json:"rows" yaml:"rows"`
+}
+
+// Read reads tables from a fixture file in the input format of gf-dbinsert.
+// The file is decoded as YAML if its extension is .yaml or .yml, otherwise as JSON.
+func Read(path string) ([]Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to open %s: %w`, path, err)
+	}
+	defer f.Close()
+
+	format := `json`
+	switch filepath.Ext(path) {
+	case `.yaml`, `.yml`:
+		format = `yaml`
+	}
+
+	tables, err := Decode(format, f)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to decode %s: %w`, path, err)
+	}
+	return tables, nil
+}
+
+// Decode decodes tables in the input format of gf-dbinsert from reader in format json or yaml.
+// YAML input is converted into JSON by YAMLToJSON before decoding, so that values are decoded in the same way as JSON input.
+func Decode(format string, reader io.Reader) ([]Table, error) {
+	switch format {
+	default:
+		return nil, fmt.Errorf(`unsupported format %s`, format)
+	case `json`:
+	case `yaml`:
+		b, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf(`fail to read YAML: %w`, err)
+		}
+		b, err = YAMLToJSON(b)
+		if err != nil {
+			return nil, fmt.Errorf(`fail to decode YAML: %w`, err)
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	var tables []Table
+	d := json.NewDecoder(reader)
+	d.DisallowUnknownFields()
+	d.UseNumber()
+	if err := d.Decode(&tables); err != nil {
+		return nil, fmt.Errorf(`fail to decode JSON: %w`, err)
+	}
+	return tables, nil
+}
+
+// YAMLToJSON converts a YAML document into JSON.
+// Scalars are converted according to their YAML tags except that timestamps remain strings as written, and integers and floats are converted into JSON numbers without loss of precision.
+func YAMLToJSON(b []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(b, &node); err != nil {
+		return nil, fmt.Errorf(`fail to unmarshal YAML: %w`, err)
+	}
+
+	v, err := fromYAMLNode(&node)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err = json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to marshal JSON: %w`, err)
+	}
+	return b, nil
+}
+
+func fromYAMLNode(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	default:
+		return nil, fmt.Errorf(`unsupported YAML node at line %d`, node.Line)
+	case 0:
+		return nil, nil
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return fromYAMLNode(node.Content[0])
+	case yaml.AliasNode:
+		return fromYAMLNode(node.Alias)
+	case yaml.SequenceNode:
+		array := []any{}
+		for _, elm := range node.Content {
+			v, err := fromYAMLNode(elm)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, v)
+		}
+		return array, nil
+	case yaml.MappingNode:
+		merged := map[string]any{}
+		object := map[string]any{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			v, err := fromYAMLNode(value)
+			if err != nil {
+				return nil, err
+			}
+			if key.ShortTag() == `!!merge` {
+				if err := mergeYAML(merged, v, key.Line); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if key.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf(`mapping key at line %d must be a scalar`, key.Line)
+			}
+			object[key.Value] = v
+		}
+		for k, v := range merged {
+			if _, ok := object[k]; !ok {
+				object[k] = v
+			}
+		}
+		return object, nil
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		default:
+			// strings, timestamps, and binaries in base64 remain as written
+			return node.Value, nil
+		case `!!null`:
+			return nil, nil
+		case `!!bool`:
+			var b bool
+			if err := node.Decode(&b); err != nil {
+				return nil, fmt.Errorf(`fail to decode boolean at line %d: %w`, node.Line, err)
+			}
+			return b, nil
+		case `!!int`, `!!float`:
+			return yamlNumber(node)
+		}
+	}
+}
+
+func mergeYAML(dst map[string]any, src any, line int) error {
+	switch src := src.(type) {
+	default:
+		return fmt.Errorf(`merge value at line %d must be a mapping or a sequence of mappings`, line)
+	case map[string]any:
+		for k, v := range src {
+			if _, ok := dst[k]; !ok {
+				dst[k] = v
+			}
+		}
+	case []any:
+		for _, elm := range src {
+			elm, ok := elm.(map[string]any)
+			if !ok {
+				return fmt.Errorf(`merge value at line %d must be a mapping or a sequence of mappings`, line)
+			}
+			if err := mergeYAML(dst, elm, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func yamlNumber(node *yaml.Node) (json.Number, error) {
+	if node.Value != "" && (node.Value[0] == '-' || ('0' <= node.Value[0] && node.Value[0] <= '9')) && json.Valid([]byte(node.Value)) {
+		return json.Number(node.Value), nil
+	}
+
+	if node.ShortTag() == `!!int` {
+		var i int64
+		if err := node.Decode(&i); err == nil {
+			return json.Number(strconv.FormatInt(i, 10)), nil
+		}
+		var u uint64
+		if err := node.Decode(&u); err == nil {
+			return json.Number(strconv.FormatUint(u, 10)), nil
+		}
+	}
+	var f float64
+	if err := node.Decode(&f); err != nil {
+		return "", fmt.Errorf(`fail to decode number at line %d: %w`, node.Line, err)
+	}
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return "", fmt.Errorf(`number at line %d cannot be represented in JSON: %s`, node.Line, node.Value)
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+}
+
+// Merge returns rows in the tables grouped by table name and the table names in order of first appearance.
+func Merge(tables []Table) ([]string, map[string]dml.Rows) {
+	names := []string{}
+	rows := map[string]dml.Rows{}
+	for _, table := range tables {
+		if _, ok := rows[table.Name]; !ok {
+			names = append(names, table.Name)
+			rows[table.Name] = dml.Rows{}
+		}
+		rows[table.Name] = append(rows[table.Name], table.Rows...)
+	}
+	return names, rows
+}
@@ -0,0 +1,83 @@
+package fixture_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/dml/fixture"
+)
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, `users.json`)
+	if err := os.WriteFile(jsonPath, []byte(`[{"name":"User","rows":[{"id":1,"name":"a"}]},{"name":"User","rows":[{"id":2,"name":null}]}]`), 0644); err != nil {
+		t.Fatalf(`fail to write fixture: %v`, err)
+	}
+	yamlPath := filepath.Join(dir, `users.yaml`)
+	if err := os.WriteFile(yamlPath, []byte("- name: User\n  rows:\n    - { id: 1, name: a }\n- name: User\n  rows:\n    - { id: 2, name: null }\n"), 0644); err != nil {
+		t.Fatalf(`fail to write fixture: %v`, err)
+	}
+
+	for _, path := range []string{jsonPath, yamlPath} {
+		tables, err := fixture.Read(path)
+		if err != nil {
+			t.Fatalf(`fail to read %s: %v`, path, err)
+		}
+
+		names, rows := fixture.Merge(tables)
+		if len(names) != 1 || names[0] != `User` {
+			t.Errorf("names not match: %v", names)
+		}
+		b, _ := json.Marshal(rows)
+		want := `{"User":[{"id":1,"name":"a"},{"id":2,"name":null}]}`
+		if string(b) != want {
+			t.Errorf("rows in %s not match\n  got  = %s\n  want = %s", path, string(b), want)
+		}
+	}
+}
+
+func TestRead_UnknownField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `users.json`)
+	if err := os.WriteFile(path, []byte(`[{"name":"User","values":[]}]`), 0644); err != nil {
+		t.Fatalf(`fail to write fixture: %v`, err)
+	}
+
+	if _, err := fixture.Read(path); err == nil {
+		t.Errorf(`unknown field is not detected`)
+	}
+}
+
+func TestYAMLToJSON(t *testing.T) {
+	testcases := []struct {
+		yaml string
+		want string
+	}{
+		{yaml: `d: 2023-01-01`, want: `{"d":"2023-01-01"}`},
+		{yaml: `t: 2023-01-01T00:00:00.123456789Z`, want: `{"t":"2023-01-01T00:00:00.123456789Z"}`},
+		{yaml: `n: 12345678901234567890123`, want: `{"n":12345678901234567890123}`},
+		{yaml: `n: 0x1F`, want: `{"n":31}`},
+		{yaml: `f: 1.50`, want: `{"f":1.50}`},
+		{yaml: `f: .5`, want: `{"f":0.5}`},
+		{yaml: `b: true`, want: `{"b":true}`},
+		{yaml: `s: "1"`, want: `{"s":"1"}`},
+		{yaml: "base: &base {a: 1, b: 2}\nv: {<<: *base, b: 3}", want: `{"base":{"a":1,"b":2},"v":{"a":1,"b":3}}`},
+	}
+	for _, testcase := range testcases {
+		got, err := fixture.YAMLToJSON([]byte(testcase.yaml))
+		if err != nil {
+			t.Errorf(`fail to convert %q: %v`, testcase.yaml, err)
+			continue
+		}
+		if string(got) != testcase.want {
+			t.Errorf("got != want\n  got  = %s\n  want = %s", got, testcase.want)
+		}
+	}
+}
+
+func TestYAMLToJSON_Infinity(t *testing.T) {
+	if _, err := fixture.YAMLToJSON([]byte(`f: .inf`)); err == nil {
+		t.Errorf(`infinity is not detected`)
+	}
+}
@@ -0,0 +1,134 @@
+package insert
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/Jumpaku/gotaface/old/ddl/schema"
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/update"
+)
+
+// SplitDeferred splits rows into the rows to be inserted with the deferred columns set to NULL and the rows to be updated afterward with the values of the key columns and the deferred columns.
+// Rows in which all the deferred columns are NULL or absent are not included in the rows to be updated.
+// An error is returned if a row needs to be updated but key is empty, e.g., rows of a table without a declared primary key, since such rows cannot be identified.
+func SplitDeferred(rows dml.Rows, key []string, deferred []string) (inserted dml.Rows, updated dml.Rows, err error) {
+	inserted, updated = dml.Rows{}, dml.Rows{}
+	for i, row := range rows {
+		insertedRow, updatedRow := dml.Row{}, dml.Row{}
+		for column, value := range row {
+			insertedRow[column] = value
+		}
+		for _, column := range deferred {
+			value, ok := row[column]
+			if !ok {
+				continue
+			}
+			insertedRow[column] = nil
+			if value != nil {
+				updatedRow[column] = value
+			}
+		}
+		inserted = append(inserted, insertedRow)
+
+		if len(updatedRow) == 0 {
+			continue
+		}
+		if len(key) == 0 {
+			return nil, nil, fmt.Errorf(`row %d cannot be updated to set deferred columns %v without primary key`, i, deferred)
+		}
+		for _, column := range key {
+			value, ok := row[column]
+			if !ok {
+				return nil, nil, fmt.Errorf(`row %d must have key column %s to update deferred columns`, i, column)
+			}
+			updatedRow[column] = value
+		}
+		updated = append(updated, updatedRow)
+	}
+
+	return inserted, updated, nil
+}
+
+// ToDBRowsFunc converts values in rows into values of the column types of the table.
+type ToDBRowsFunc func(table string, rows dml.Rows) (dml.Rows, error)
+
+// InsertDeferred inserts rows, which map table names to rows, into the tables in the order of schema.DeferredInsertOrder.
+// The rows are inserted with the deferred columns set to NULL and converted by toDBRows, and then the deferred columns are updated by the primary keys after all rows are inserted.
+// The row counts per table are written to output as SQL comments if output is not nil.
+func InsertDeferred(ctx context.Context, s schema.Schema, foreignKeys []schema.ForeignKey, inserter Inserter, updater update.Updater, toDBRows ToDBRowsFunc, rows map[string]dml.Rows, output io.Writer) error {
+	order, deferred, err := schema.DeferredInsertOrder(s, foreignKeys)
+	if err != nil {
+		return fmt.Errorf(`fail to get insert order: %w`, err)
+	}
+
+	tables := s.Tables()
+	tableNames := map[string]bool{}
+	for _, table := range tables {
+		tableNames[table.Name()] = true
+	}
+	for name := range rows {
+		if !tableNames[name] {
+			return fmt.Errorf(`table %s not found in schema`, name)
+		}
+	}
+
+	type deferredRows struct {
+		table string
+		key   []string
+		rows  dml.Rows
+	}
+	updates := []deferredRows{}
+	for _, index := range order {
+		table := tables[index]
+		tableRows := rows[table.Name()]
+		if len(tableRows) == 0 {
+			continue
+		}
+
+		columns := table.Columns()
+		key := []string{}
+		for _, k := range table.PrimaryKey() {
+			key = append(key, columns[k].Name())
+		}
+		insertedRows, updatedRows, err := SplitDeferred(tableRows, key, deferred[table.Name()])
+		if err != nil {
+			return fmt.Errorf(`fail to defer columns in table %s: %w`, table.Name(), err)
+		}
+
+		insertedRows, err = toDBRows(table.Name(), insertedRows)
+		if err != nil {
+			return fmt.Errorf(`fail to convert rows in table %s: %w`, table.Name(), err)
+		}
+		if output != nil {
+			if _, err := fmt.Fprintf(output, "-- %s: %d rows\n", table.Name(), len(insertedRows)); err != nil {
+				return fmt.Errorf(`fail to write row count: %w`, err)
+			}
+		}
+		if err := inserter.Insert(ctx, table.Name(), insertedRows); err != nil {
+			return fmt.Errorf(`fail to insert rows in table %s: %w`, table.Name(), err)
+		}
+
+		if len(updatedRows) > 0 {
+			updatedRows, err = toDBRows(table.Name(), updatedRows)
+			if err != nil {
+				return fmt.Errorf(`fail to convert rows in table %s: %w`, table.Name(), err)
+			}
+			updates = append(updates, deferredRows{table: table.Name(), key: key, rows: updatedRows})
+		}
+	}
+
+	for _, u := range updates {
+		if output != nil {
+			if _, err := fmt.Fprintf(output, "-- %s: %d deferred rows\n", u.table, len(u.rows)); err != nil {
+				return fmt.Errorf(`fail to write row count: %w`, err)
+			}
+		}
+		if err := updater.Update(ctx, u.table, u.key, u.rows); err != nil {
+			return fmt.Errorf(`fail to update deferred columns in table %s: %w`, u.table, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package insert_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/ddl/schema"
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/insert"
+	"github.com/davecgh/go-spew/spew"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+func TestSplitDeferred(t *testing.T) {
+	rows := dml.Rows{
+		{"id": 1, "name": "Jumpaku", "team_id": 10},
+		{"id": 2, "name": "Alice", "team_id": nil},
+		{"id": 3, "name": "Bob"},
+	}
+
+	gotInserted, gotUpdated, err := insert.SplitDeferred(rows, []string{"id"}, []string{"team_id"})
+	if err != nil {
+		t.Fatalf(`fail to split rows: %v`, err)
+	}
+
+	wantInserted := dml.Rows{
+		{"id": 1, "name": "Jumpaku", "team_id": nil},
+		{"id": 2, "name": "Alice", "team_id": nil},
+		{"id": 3, "name": "Bob"},
+	}
+	if !slices.EqualFunc(gotInserted, wantInserted, maps.Equal[dml.Row, dml.Row]) {
+		t.Errorf("got != want\n  got  = %v\n  want = %v", spew.Sdump(gotInserted), spew.Sdump(wantInserted))
+	}
+	wantUpdated := dml.Rows{
+		{"id": 1, "team_id": 10},
+	}
+	if !slices.EqualFunc(gotUpdated, wantUpdated, maps.Equal[dml.Row, dml.Row]) {
+		t.Errorf("got != want\n  got  = %v\n  want = %v", spew.Sdump(gotUpdated), spew.Sdump(wantUpdated))
+	}
+}
+
+func TestSplitDeferred_MissingKey(t *testing.T) {
+	rows := dml.Rows{
+		{"name": "Jumpaku", "team_id": 10},
+	}
+
+	_, _, err := insert.SplitDeferred(rows, []string{"id"}, []string{"team_id"})
+	if err == nil {
+		t.Errorf(`missing key column not detected`)
+	}
+}
+
+func TestSplitDeferred_NoKey(t *testing.T) {
+	rows := dml.Rows{
+		{"id": 1, "parent_id": nil},
+		{"id": 2, "parent_id": 1},
+	}
+
+	_, _, err := insert.SplitDeferred(rows, []string{}, []string{"parent_id"})
+	if err == nil {
+		t.Errorf(`update without key not detected`)
+	}
+
+	_, _, err = insert.SplitDeferred(rows[:1], []string{}, []string{"parent_id"})
+	if err != nil {
+		t.Errorf(`rows without deferred values must not need key: %v`, err)
+	}
+}
+
+type recorder struct {
+	calls []string
+}
+
+func (r *recorder) Insert(ctx context.Context, table string, rows dml.Rows) error {
+	r.calls = append(r.calls, fmt.Sprintf(`insert %s %v`, table, rows))
+	return nil
+}
+
+func (r *recorder) Update(ctx context.Context, table string, key []string, rows dml.Rows) error {
+	r.calls = append(r.calls, fmt.Sprintf(`update %s %v %v`, table, key, rows))
+	return nil
+}
+
+func TestInsertDeferred(t *testing.T) {
+	s := schema.SchemaFormat{
+		TablesVal: []schema.TableFormat{
+			{NameVal: "User", ColumnsVal: []schema.ColumnFormat{{NameVal: "id"}, {NameVal: "team_id"}}, PrimaryKeyVal: []int{0}},
+			{NameVal: "Team", ColumnsVal: []schema.ColumnFormat{{NameVal: "id"}, {NameVal: "owner_id"}}, PrimaryKeyVal: []int{0}},
+		},
+		ReferencesVal: [][]int{{1}, {0}},
+	}
+	foreignKeys := []schema.ForeignKey{
+		{Table: "User", Columns: []string{"team_id"}, ReferencedTable: "Team", Nullable: true},
+		{Table: "Team", Columns: []string{"owner_id"}, ReferencedTable: "User", Nullable: false},
+	}
+	rows := map[string]dml.Rows{
+		"Team": {{"id": 10, "owner_id": 1}},
+		"User": {{"id": 1, "team_id": 10}},
+	}
+	toDBRows := func(table string, rows dml.Rows) (dml.Rows, error) { return rows, nil }
+
+	r := &recorder{}
+	output := bytes.NewBuffer(nil)
+	if err := insert.InsertDeferred(context.Background(), s, foreignKeys, r, r, toDBRows, rows, output); err != nil {
+		t.Fatalf(`fail to insert rows: %v`, err)
+	}
+
+	want := []string{
+		`insert User [map[id:1 team_id:<nil>]]`,
+		`insert Team [map[id:10 owner_id:1]]`,
+		`update User [id] [map[id:1 team_id:10]]`,
+	}
+	if !slices.Equal(r.calls, want) {
+		t.Errorf("got != want\n  got  = %#v\n  want = %#v", r.calls, want)
+	}
+	wantOutput := "-- User: 1 rows\n-- Team: 1 rows\n-- User: 1 deferred rows\n"
+	if output.String() != wantOutput {
+		t.Errorf("got != want\n  got  = %q\n  want = %q", output.String(), wantOutput)
+	}
+}
+
+func TestInsertDeferred_UnknownTable(t *testing.T) {
+	s := schema.SchemaFormat{TablesVal: []schema.TableFormat{{NameVal: "User"}}, ReferencesVal: [][]int{{}}}
+	toDBRows := func(table string, rows dml.Rows) (dml.Rows, error) { return rows, nil }
+
+	r := &recorder{}
+	err := insert.InsertDeferred(context.Background(), s, nil, r, r, toDBRows, map[string]dml.Rows{"Team": {{"id": 1}}}, nil)
+	if err == nil {
+		t.Errorf(`unknown table is not detected`)
+	}
+}
+
+func TestInsertDeferred_NoKey(t *testing.T) {
+	s := schema.SchemaFormat{
+		TablesVal: []schema.TableFormat{
+			{NameVal: "Node", ColumnsVal: []schema.ColumnFormat{{NameVal: "id"}, {NameVal: "parent_id"}}, PrimaryKeyVal: []int{}},
+		},
+		ReferencesVal: [][]int{{0}},
+	}
+	foreignKeys := []schema.ForeignKey{
+		{Table: "Node", Columns: []string{"parent_id"}, ReferencedTable: "Node", Nullable: true},
+	}
+	rows := map[string]dml.Rows{
+		"Node": {{"id": 1, "parent_id": nil}, {"id": 2, "parent_id": 1}},
+	}
+	toDBRows := func(table string, rows dml.Rows) (dml.Rows, error) { return rows, nil }
+
+	r := &recorder{}
+	err := insert.InsertDeferred(context.Background(), s, foreignKeys, r, r, toDBRows, rows, nil)
+	if err == nil {
+		t.Errorf(`update without key not detected`)
+	}
+	if len(r.calls) != 0 {
+		t.Errorf(`rows must not be written: %#v`, r.calls)
+	}
+}
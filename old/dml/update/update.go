@@ -0,0 +1,12 @@
+package update
+
+import (
+	"context"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+)
+
+// Updater updates the columns of rows in a table identified by the values of the key columns in the rows.
+type Updater interface {
+	Update(ctx context.Context, table string, key []string, rows dml.Rows) error
+}
@@ -0,0 +1,54 @@
+package jsonschema
+
+import (
+	"fmt"
+
+	"github.com/Jumpaku/gotaface/old/ddl/schema"
+	"github.com/Jumpaku/gotaface/old/json/wrap"
+)
+
+// ColumnSchemaFunc returns a JSON Schema of values of a column type.
+type ColumnSchemaFunc func(columnType string) *Schema
+
+// DBInsertInput derives a JSON Schema of the input of gf-dbinsert from a database schema.
+// Each element of the input is validated against the table selected by its name, and values of each column are validated by the JSON Schema from columnSchema.
+func DBInsertInput(s schema.Schema, columnSchema ColumnSchemaFunc) *Schema {
+	closed := false
+	tables := []*Schema{}
+	for _, table := range s.Tables() {
+		columns := map[string]*Schema{}
+		for _, column := range table.Columns() {
+			valueSchema := columnSchema(column.Type())
+			if valueSchema.Description == "" {
+				valueSchema.Description = fmt.Sprintf(`%s %s`, column.Name(), column.Type())
+			} else {
+				valueSchema.Description = fmt.Sprintf(`%s %s: %s`, column.Name(), column.Type(), valueSchema.Description)
+			}
+			columns[column.Name()] = valueSchema
+		}
+		tables = append(tables, &Schema{
+			Title: table.Name(),
+			Type:  Types{TypeObject},
+			Properties: map[string]*Schema{
+				`name`: {Enum: Enum{wrap.String(table.Name())}},
+				`rows`: {
+					Type: Types{TypeArray},
+					Items: &Schema{
+						Type:                 Types{TypeObject},
+						Properties:           columns,
+						AdditionalProperties: &closed,
+					},
+				},
+			},
+			Required:             []string{`name`, `rows`},
+			AdditionalProperties: &closed,
+		})
+	}
+
+	return &Schema{
+		Schema:      Draft202012,
+		Description: `input of gf-dbinsert`,
+		Type:        Types{TypeArray},
+		Items:       &Schema{AnyOf: tables},
+	}
+}
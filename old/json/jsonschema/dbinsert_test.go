@@ -0,0 +1,66 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/ddl/schema"
+	"github.com/Jumpaku/gotaface/old/json/jsonschema"
+	"github.com/Jumpaku/gotaface/old/test/assert"
+)
+
+func TestDBInsertInput(t *testing.T) {
+	s := schema.SchemaFormat{
+		TablesVal: []schema.TableFormat{
+			{
+				NameVal:       "User",
+				ColumnsVal:    []schema.ColumnFormat{{NameVal: "id", TypeVal: "INT"}, {NameVal: "name", TypeVal: "TEXT"}},
+				PrimaryKeyVal: []int{0},
+			},
+			{
+				NameVal:       "Comment",
+				ColumnsVal:    []schema.ColumnFormat{{NameVal: "id", TypeVal: "INT"}, {NameVal: "userId", TypeVal: "INT"}},
+				PrimaryKeyVal: []int{0},
+			},
+		},
+		ReferencesVal: [][]int{{}, {0}},
+	}
+	columnSchema := func(columnType string) *jsonschema.Schema {
+		if strings.HasPrefix(columnType, "INT") {
+			return &jsonschema.Schema{Type: jsonschema.Types{jsonschema.TypeInteger, jsonschema.TypeNull}}
+		}
+		return &jsonschema.Schema{Type: jsonschema.Types{jsonschema.TypeString, jsonschema.TypeNull}}
+	}
+
+	sut := jsonschema.DBInsertInput(s, columnSchema)
+
+	t.Run(`roundtrip`, func(t *testing.T) {
+		b, err := json.Marshal(sut)
+		assert.Equal(t, err, nil)
+		parsed, err := jsonschema.Parse(b)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, parsed.Schema, jsonschema.Draft202012)
+		assert.Equal(t, len(parsed.Items.AnyOf), 2)
+	})
+	t.Run(`valid`, func(t *testing.T) {
+		err := sut.Validate(parse(t, `[
+			{"name": "User", "rows": [{"id": 1, "name": "a"}, {"id": 2, "name": null}]},
+			{"name": "Comment", "rows": [{"id": 1, "userId": 1}]}
+		]`))
+		assert.Equal(t, err, nil)
+	})
+	t.Run(`invalid`, func(t *testing.T) {
+		testcases := []string{
+			`{}`,
+			`[{"name": "Unknown", "rows": []}]`,
+			`[{"name": "User"}]`,
+			`[{"name": "User", "rows": [{"id": "1"}]}]`,
+			`[{"name": "Comment", "rows": [{"id": 1, "name": "a"}]}]`,
+		}
+		for _, doc := range testcases {
+			err := sut.Validate(parse(t, doc))
+			assert.NotEqual(t, err, nil)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Jumpaku/gotaface/old/json/wrap"
+)
+
+// Draft202012 is the URI of the meta-schema of JSON Schema draft 2020-12.
+const Draft202012 = `https://json-schema.org/draft/2020-12/schema`
+
+// Schema is a JSON Schema in a subset of draft 2020-12.
+// The supported keywords are type, properties, required, additionalProperties, items, enum, minimum, maximum, pattern, and anyOf.
+// Annotations $schema, title, and description are kept but do not affect validation.
+// Unlike the standard, the type integer accepts only numbers written without a fraction or an exponent, such as 1 but not 1.0 or 1e2, as the values converted into integers by ToDBValue of each driver.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 Enum               `json:"enum,omitempty"`
+	Minimum              *json.Number       `json:"minimum,omitempty"`
+	Maximum              *json.Number       `json:"maximum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+}
+
+const (
+	TypeNull    = `null`
+	TypeBoolean = `boolean`
+	TypeNumber  = `number`
+	TypeInteger = `integer`
+	TypeString  = `string`
+	TypeArray   = `array`
+	TypeObject  = `object`
+)
+
+// Types is a value of the type keyword, which is given as a string or an array of strings.
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *Types) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*t = Types{s}
+		return nil
+	}
+	var a []string
+	if err := json.Unmarshal(b, &a); err != nil {
+		return fmt.Errorf(`fail to unmarshal type as string or array of strings: %w`, err)
+	}
+	*t = Types(a)
+	return nil
+}
+
+// Enum is a value of the enum keyword.
+type Enum []wrap.JsonValue
+
+func (e *Enum) UnmarshalJSON(b []byte) error {
+	v := wrap.Null()
+	if err := v.UnmarshalJSON(b); err != nil {
+		return fmt.Errorf(`fail to unmarshal enum: %w`, err)
+	}
+	if v.Type() != wrap.JsonTypeArray {
+		return fmt.Errorf(`enum must be array: %s`, v.Type())
+	}
+	*e = Enum{}
+	for i := 0; i < v.ArrayLen(); i++ {
+		*e = append(*e, v.ArrayGetElm(i))
+	}
+	return nil
+}
+
+// Parse parses a JSON Schema in JSON, which fails if the schema has keywords outside the supported subset.
+func Parse(b []byte) (*Schema, error) {
+	decoder := json.NewDecoder(bytes.NewBuffer(b))
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+
+	var s Schema
+	if err := decoder.Decode(&s); err != nil {
+		return nil, fmt.Errorf(`fail to decode JSON Schema: %w`, err)
+	}
+	return &s, nil
+}
+
+// ParseFile reads and parses a JSON Schema in the file at path in the same way as Parse.
+func ParseFile(path string) (*Schema, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to read %s: %w`, path, err)
+	}
+
+	s, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to parse JSON Schema %s: %w`, path, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,30 @@
+package jsonschema_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/json/jsonschema"
+	"github.com/Jumpaku/gotaface/old/test/assert"
+)
+
+func TestParseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.json")
+	err := os.WriteFile(path, []byte(`{"type":"integer"}`), 0644)
+	assert.Equal(t, err, nil)
+
+	s, err := jsonschema.ParseFile(path)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, s.Validate(parse(t, `1`)), nil)
+	if err := s.Validate(parse(t, `1.0`)); err == nil {
+		t.Errorf(`1.0 must not be an integer`)
+	}
+}
+
+func TestParseFile_NotFound(t *testing.T) {
+	_, err := jsonschema.ParseFile(filepath.Join(t.TempDir(), "schema.json"))
+	if err == nil {
+		t.Errorf(`missing file must be an error`)
+	}
+}
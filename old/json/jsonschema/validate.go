@@ -0,0 +1,182 @@
+package jsonschema
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Jumpaku/gotaface/old/json/wrap"
+)
+
+// ValidationError is a violation of a JSON Schema at a path in a JSON value.
+type ValidationError struct {
+	Path    wrap.Path
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf(`%q: %s`, e.Path.Pointer(), e.Message)
+}
+
+// ValidationErrors is a list of violations of a JSON Schema.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := []string{}
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Validate validates v against the schema and returns ValidationErrors if v violates the schema.
+func (s *Schema) Validate(v wrap.JsonValue) error {
+	errs := s.validate(wrap.Path{}, v)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (s *Schema) validate(path wrap.Path, v wrap.JsonValue) ValidationErrors {
+	errs := ValidationErrors{}
+	addErr := func(path wrap.Path, format string, args ...any) {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.Type) > 0 && !matchesAnyType(s.Type, v) {
+		addErr(path, `type must be %s but %s`, strings.Join(s.Type, ` or `), v.Type())
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if wrap.Equal(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			addErr(path, `value must be one of %s`, marshalEnum(s.Enum))
+		}
+	}
+
+	if len(s.AnyOf) > 0 {
+		var best ValidationErrors
+		for _, sub := range s.AnyOf {
+			subErrs := sub.validate(path, v)
+			if len(subErrs) == 0 {
+				best = nil
+				break
+			}
+			if best == nil || len(subErrs) < len(best) {
+				best = subErrs
+			}
+		}
+		if best != nil {
+			addErr(path, `value must match any of %d schemas`, len(s.AnyOf))
+			errs = append(errs, best...)
+		}
+	}
+
+	switch v.Type() {
+	case wrap.JsonTypeNumber:
+		if s.Minimum != nil && compareNumber(v.NumberGet().String(), s.Minimum.String()) < 0 {
+			addErr(path, `value must be greater than or equal to %s`, s.Minimum.String())
+		}
+		if s.Maximum != nil && compareNumber(v.NumberGet().String(), s.Maximum.String()) > 0 {
+			addErr(path, `value must be less than or equal to %s`, s.Maximum.String())
+		}
+	case wrap.JsonTypeString:
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				addErr(path, `invalid pattern %q: %v`, s.Pattern, err)
+			} else if !re.MatchString(v.StringGet()) {
+				addErr(path, `value must match pattern %q`, s.Pattern)
+			}
+		}
+	case wrap.JsonTypeArray:
+		if s.Items != nil {
+			for i := 0; i < v.ArrayLen(); i++ {
+				errs = append(errs, s.Items.validate(path.Append(wrap.Key(strconv.Itoa(i))), v.ArrayGetElm(i))...)
+			}
+		}
+	case wrap.JsonTypeObject:
+		for _, key := range s.Required {
+			if !v.ObjectHasElm(key) {
+				addErr(path, `property %q is required`, key)
+			}
+		}
+		for _, key := range v.ObjectKeys() {
+			elmPath := path.Append(wrap.Key(key))
+			if sub, ok := s.Properties[key]; ok {
+				errs = append(errs, sub.validate(elmPath, v.ObjectGetElm(key))...)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				addErr(elmPath, `property %q is not allowed`, key)
+			}
+		}
+	}
+
+	return errs
+}
+
+func matchesAnyType(types Types, v wrap.JsonValue) bool {
+	for _, t := range types {
+		switch t {
+		case TypeNull:
+			if v.Type() == wrap.JsonTypeNull {
+				return true
+			}
+		case TypeBoolean:
+			if v.Type() == wrap.JsonTypeBoolean {
+				return true
+			}
+		case TypeNumber:
+			if v.Type() == wrap.JsonTypeNumber {
+				return true
+			}
+		case TypeInteger:
+			if v.Type() == wrap.JsonTypeNumber && !strings.ContainsAny(v.NumberGet().String(), `.eE`) {
+				return true
+			}
+		case TypeString:
+			if v.Type() == wrap.JsonTypeString {
+				return true
+			}
+		case TypeArray:
+			if v.Type() == wrap.JsonTypeArray {
+				return true
+			}
+		case TypeObject:
+			if v.Type() == wrap.JsonTypeObject {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func compareNumber(a string, b string) int {
+	x, okX := new(big.Rat).SetString(a)
+	y, okY := new(big.Rat).SetString(b)
+	if !okX || !okY {
+		return 0
+	}
+	return x.Cmp(y)
+}
+
+func marshalEnum(enum Enum) string {
+	values := []string{}
+	for _, e := range enum {
+		b, err := e.MarshalJSON()
+		if err != nil {
+			values = append(values, e.Type().String())
+			continue
+		}
+		values = append(values, string(b))
+	}
+	return `[` + strings.Join(values, `,`) + `]`
+}
@@ -0,0 +1,164 @@
+package jsonschema_test
+
+import (
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/json/jsonschema"
+	"github.com/Jumpaku/gotaface/old/json/wrap"
+	"github.com/Jumpaku/gotaface/old/test/assert"
+)
+
+func parse(t *testing.T, s string) wrap.JsonValue {
+	t.Helper()
+
+	v := wrap.Null()
+	err := v.UnmarshalJSON([]byte(s))
+	assert.Equal(t, err, nil)
+	return v
+}
+
+func validationErrors(t *testing.T, schema string, doc string) []string {
+	t.Helper()
+
+	s, err := jsonschema.Parse([]byte(schema))
+	assert.Equal(t, err, nil)
+
+	errs := []string{}
+	err = s.Validate(parse(t, doc))
+	if err == nil {
+		return errs
+	}
+	for _, e := range err.(jsonschema.ValidationErrors) {
+		errs = append(errs, e.Path.Pointer())
+	}
+	return errs
+}
+
+func TestSchema_Validate(t *testing.T) {
+	testcases := []struct {
+		name   string
+		schema string
+		doc    string
+		want   []string
+	}{
+		{
+			name:   `empty schema`,
+			schema: `{}`,
+			doc:    `{"a":[1,"b",null]}`,
+			want:   []string{},
+		},
+		{
+			name:   `type`,
+			schema: `{"type":"string"}`,
+			doc:    `1`,
+			want:   []string{``},
+		},
+		{
+			name:   `types`,
+			schema: `{"type":["string","null"]}`,
+			doc:    `null`,
+			want:   []string{},
+		},
+		{
+			name:   `integer`,
+			schema: `{"type":"integer"}`,
+			doc:    `-1`,
+			want:   []string{},
+		},
+		{
+			name:   `integer with fraction`,
+			schema: `{"type":"integer"}`,
+			doc:    `1.0`,
+			want:   []string{``},
+		},
+		{
+			name:   `integer with exponent`,
+			schema: `{"type":"integer"}`,
+			doc:    `1e2`,
+			want:   []string{``},
+		},
+		{
+			name:   `not integer`,
+			schema: `{"type":"integer"}`,
+			doc:    `1.5`,
+			want:   []string{``},
+		},
+		{
+			name:   `enum`,
+			schema: `{"enum":["a",1,{"b":null}]}`,
+			doc:    `[{"b":null},1.0,"c"]`,
+			want:   []string{``},
+		},
+		{
+			name:   `minimum and maximum`,
+			schema: `{"items":{"minimum":0,"maximum":1.5}}`,
+			doc:    `[-0.1,0,1.5,2,"x"]`,
+			want:   []string{`/0`, `/3`},
+		},
+		{
+			name:   `pattern`,
+			schema: `{"items":{"pattern":"^[a-z]+$"}}`,
+			doc:    `["abc","aBc",1]`,
+			want:   []string{`/1`},
+		},
+		{
+			name:   `properties and required`,
+			schema: `{"type":"object","properties":{"id":{"type":"integer"},"name":{"type":"string"}},"required":["id","name"]}`,
+			doc:    `{"id":"1","age":20}`,
+			want:   []string{``, `/id`},
+		},
+		{
+			name:   `additional properties`,
+			schema: `{"properties":{"id":{}},"additionalProperties":false}`,
+			doc:    `{"id":1,"a/b":2}`,
+			want:   []string{`/a~1b`},
+		},
+		{
+			name:   `items`,
+			schema: `{"type":"array","items":{"type":"array","items":{"type":"boolean"}}}`,
+			doc:    `[[true],[false,null]]`,
+			want:   []string{`/1/1`},
+		},
+		{
+			name:   `any of`,
+			schema: `{"items":{"anyOf":[{"type":"string"},{"type":"object","required":["id"]}]}}`,
+			doc:    `["a",{"id":1},{"name":"b"},1]`,
+			want:   []string{`/2`, `/2`, `/3`, `/3`},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := validationErrors(t, tc.schema, tc.doc)
+			assert.Equal(t, len(got), len(tc.want))
+			for i := range got {
+				assert.Equal(t, got[i], tc.want[i])
+			}
+		})
+	}
+}
+
+func TestSchema_Validate_Message(t *testing.T) {
+	s, err := jsonschema.Parse([]byte(`{"properties":{"users":{"items":{"required":["id"]}}}}`))
+	assert.Equal(t, err, nil)
+
+	err = s.Validate(parse(t, `{"users":[{"id":1},{}]}`))
+	assert.Equal(t, err.Error(), `"/users/1": property "id" is required`)
+}
+
+func TestParse(t *testing.T) {
+	t.Run(`roundtrip`, func(t *testing.T) {
+		s, err := jsonschema.Parse([]byte(`{"type":["integer","null"],"enum":[1,null],"minimum":1e3}`))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, len(s.Type), 2)
+		assert.Equal(t, len(s.Enum), 2)
+		assert.Equal(t, s.Minimum.String(), `1e3`)
+	})
+	t.Run(`unsupported keyword`, func(t *testing.T) {
+		_, err := jsonschema.Parse([]byte(`{"oneOf":[]}`))
+		assert.NotEqual(t, err, nil)
+	})
+	t.Run(`invalid type`, func(t *testing.T) {
+		_, err := jsonschema.Parse([]byte(`{"type":1}`))
+		assert.NotEqual(t, err, nil)
+	})
+}
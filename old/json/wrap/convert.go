@@ -0,0 +1,613 @@
+package wrap
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jumpaku/gotaface/old/errors"
+)
+
+// DecodeError is an error of Decode with the path of the value which cannot be decoded.
+type DecodeError struct {
+	Path Path
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf(`fail to decode value at %q: %v`, e.Path.Pointer(), e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	jsonValueType       = reflect.TypeOf((*JsonValue)(nil)).Elem()
+	jsonNumberType      = reflect.TypeOf(json.Number(""))
+	timeType            = reflect.TypeOf(time.Time{})
+	bigIntType          = reflect.TypeOf(big.Int{})
+	bigFloatType        = reflect.TypeOf(big.Float{})
+	bigRatType          = reflect.TypeOf(big.Rat{})
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+type jsonField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// jsonFields returns the fields of a struct type which are encoded in JSON following the json tags in the same way as encoding/json.
+// Fields of untagged embedded structs are promoted, and a shallower field or a tagged field takes precedence over the other fields of the same name.
+func jsonFields(t reflect.Type) []jsonField {
+	type embedded struct {
+		t     reflect.Type
+		index []int
+	}
+
+	fields := []jsonField{}
+	taken := map[string]bool{}
+	visited := map[reflect.Type]bool{}
+	for level := []embedded{{t: t}}; len(level) > 0; {
+		next := []embedded{}
+		candidates := map[string][]jsonField{}
+		tagged := map[string][]jsonField{}
+		names := []string{}
+		for _, e := range level {
+			if visited[e.t] {
+				continue
+			}
+			visited[e.t] = true
+			for i := 0; i < e.t.NumField(); i++ {
+				f := e.t.Field(i)
+				tag := f.Tag.Get(`json`)
+				if tag == `-` {
+					continue
+				}
+				name, options, _ := strings.Cut(tag, `,`)
+				index := append(append([]int{}, e.index...), i)
+				if f.Anonymous {
+					ft := f.Type
+					if ft.Kind() == reflect.Pointer {
+						ft = ft.Elem()
+					}
+					if name == `` && ft.Kind() == reflect.Struct {
+						next = append(next, embedded{t: ft, index: index})
+						continue
+					}
+				}
+				if !f.IsExported() {
+					continue
+				}
+				field := jsonField{name: name, index: index, omitEmpty: strings.Contains(`,`+options+`,`, `,omitempty,`)}
+				if name == `` {
+					field.name = f.Name
+				} else {
+					tagged[field.name] = append(tagged[field.name], field)
+				}
+				if _, ok := candidates[field.name]; !ok {
+					names = append(names, field.name)
+				}
+				candidates[field.name] = append(candidates[field.name], field)
+			}
+		}
+		for _, name := range names {
+			if taken[name] {
+				continue
+			}
+			taken[name] = true
+			if len(tagged[name]) == 1 {
+				fields = append(fields, tagged[name][0])
+			} else if len(tagged[name]) == 0 && len(candidates[name]) == 1 {
+				fields = append(fields, candidates[name][0])
+			}
+		}
+		level = next
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i].index, fields[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return fields
+}
+
+// Encode converts a Go value into a JsonValue in the same way as encoding/json without marshaling it into bytes.
+// Structs are converted into objects following the json tags, maps are converted into objects with sorted keys, and slices and arrays are converted into arrays.
+// time.Time is converted into a string in RFC 3339, *big.Int and *big.Float are converted into numbers, and *big.Rat is converted into a number if it has a finite decimal representation or a string `a/b` otherwise.
+// Other values implementing json.Marshaler or encoding.TextMarshaler are converted through their methods.
+// Encode panics if v has values which cannot be represented in JSON such as channels, functions, complex numbers, NaN, or infinities.
+func Encode(v any) JsonValue {
+	return encode(Path{}, reflect.ValueOf(v))
+}
+
+func encode(path Path, rv reflect.Value) JsonValue {
+	if !rv.IsValid() {
+		return Null()
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return Null()
+		}
+	}
+
+	if rv.Type().Implements(jsonValueType) {
+		return rv.Interface().(JsonValue).Clone()
+	}
+
+	switch rv.Type() {
+	case jsonNumberType:
+		return Number(json.Number(rv.String()))
+	case timeType:
+		return String(rv.Interface().(time.Time).Format(time.RFC3339Nano))
+	case bigIntType:
+		v := rv.Interface().(big.Int)
+		return Number(json.Number(v.String()))
+	case bigFloatType:
+		v := rv.Interface().(big.Float)
+		errors.Assert(!v.IsInf(), "infinity at %s cannot be encoded", path.Pointer())
+		return Number(json.Number(v.Text('g', -1)))
+	case bigRatType:
+		v := rv.Interface().(big.Rat)
+		if s, ok := decimalString(&v); ok {
+			return Number(json.Number(s))
+		}
+		return String(v.String())
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return encode(path, rv.Elem())
+	}
+
+	if rv.Type().Implements(jsonMarshalerType) {
+		b, err := rv.Interface().(json.Marshaler).MarshalJSON()
+		errors.Assert(err == nil, "fail to marshal value at %s: %v", path.Pointer(), err)
+		v := Null()
+		err = v.UnmarshalJSON(b)
+		errors.Assert(err == nil, "fail to unmarshal value at %s: %v", path.Pointer(), err)
+		return v
+	}
+	if rv.Type().Implements(textMarshalerType) {
+		b, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
+		errors.Assert(err == nil, "fail to marshal value at %s: %v", path.Pointer(), err)
+		return String(string(b))
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return Boolean(rv.Bool())
+	case reflect.String:
+		return String(rv.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Number(json.Number(strconv.FormatInt(rv.Int(), 10)))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return Number(json.Number(strconv.FormatUint(rv.Uint(), 10)))
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		errors.Assert(!math.IsNaN(f) && !math.IsInf(f, 0), "%v at %s cannot be encoded", f, path.Pointer())
+		return Number(json.Number(strconv.FormatFloat(f, 'g', -1, rv.Type().Bits())))
+	case reflect.Slice:
+		if rv.IsNil() {
+			return Null()
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return String(base64.StdEncoding.EncodeToString(rv.Bytes()))
+		}
+		fallthrough
+	case reflect.Array:
+		arr := Array()
+		for i := 0; i < rv.Len(); i++ {
+			arr.ArrayAddElm(encode(path.Append(Key(strconv.Itoa(i))), rv.Index(i)))
+		}
+		return arr
+	case reflect.Map:
+		if rv.IsNil() {
+			return Null()
+		}
+		keys := map[string]reflect.Value{}
+		for _, k := range rv.MapKeys() {
+			keys[encodeKey(path, k)] = k
+		}
+		sorted := []string{}
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		obj := Object()
+		for _, k := range sorted {
+			obj.ObjectSetElm(k, encode(path.Append(Key(k)), rv.MapIndex(keys[k])))
+		}
+		return obj
+	case reflect.Struct:
+		obj := Object()
+		for _, f := range jsonFields(rv.Type()) {
+			fv, err := rv.FieldByIndexErr(f.index)
+			if err != nil {
+				// the field is promoted through a nil embedded pointer.
+				continue
+			}
+			if f.omitEmpty && isEmpty(fv) {
+				continue
+			}
+			obj.ObjectSetElm(f.name, encode(path.Append(Key(f.name)), fv))
+		}
+		return obj
+	default:
+		return errors.Unexpected1[JsonValue]("value of %s at %s cannot be encoded", rv.Type(), path.Pointer())
+	}
+}
+
+func encodeKey(path Path, k reflect.Value) string {
+	switch k.Kind() {
+	case reflect.String:
+		return k.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10)
+	default:
+		return errors.Unexpected1[string]("map key of %s at %s cannot be encoded", k.Type(), path.Pointer())
+	}
+}
+
+// decimalString returns the decimal representation of r if it is finite.
+func decimalString(r *big.Rat) (string, bool) {
+	if r.IsInt() {
+		return r.Num().String(), true
+	}
+
+	d := new(big.Int).Set(r.Denom())
+	digits := 0
+	for _, p := range []int64{2, 5} {
+		n := 0
+		for new(big.Int).Mod(d, big.NewInt(p)).Sign() == 0 {
+			d.Div(d, big.NewInt(p))
+			n++
+		}
+		if n > digits {
+			digits = n
+		}
+	}
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return "", false
+	}
+	return r.FloatString(digits), true
+}
+
+func isEmpty(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return rv.IsZero()
+	default:
+		return false
+	}
+}
+
+// Decode converts a JsonValue into a Go value of type T in the same way as encoding/json without marshaling it into bytes.
+// Objects are converted into structs following the json tags, where unknown keys are ignored, or into maps, and arrays are converted into slices or arrays of the same length.
+// time.Time is converted from a string in RFC 3339, big.Int, big.Float, and big.Rat are converted from numbers, and big.Rat is also converted from a string `a/b`.
+// Numbers are converted into json.Number for interface types.
+// Other types implementing json.Unmarshaler or encoding.TextUnmarshaler are converted through their methods.
+// Decode returns a *DecodeError with the path of the value which cannot be converted.
+func Decode[T any](v JsonValue) (T, error) {
+	var t T
+	if err := decodeValue(Path{}, v, reflect.ValueOf(&t).Elem()); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+func decodeErr(path Path, format string, args ...any) error {
+	return &DecodeError{Path: path, Err: fmt.Errorf(format, args...)}
+}
+
+func decodeValue(path Path, v JsonValue, rv reflect.Value) error {
+	t := rv.Type()
+	if t == jsonValueType {
+		rv.Set(reflect.ValueOf(v.Clone()))
+		return nil
+	}
+
+	if v.Type() == JsonTypeNull {
+		rv.Set(reflect.Zero(t))
+		return nil
+	}
+
+	switch t {
+	case jsonNumberType:
+		if v.Type() != JsonTypeNumber {
+			return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+		}
+		rv.SetString(v.NumberGet().String())
+		return nil
+	case timeType:
+		if v.Type() != JsonTypeString {
+			return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+		}
+		tm, err := time.Parse(time.RFC3339Nano, v.StringGet())
+		if err != nil {
+			return &DecodeError{Path: path, Err: err}
+		}
+		rv.Set(reflect.ValueOf(tm))
+		return nil
+	case bigIntType:
+		if v.Type() != JsonTypeNumber {
+			return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+		}
+		n, ok := new(big.Int).SetString(v.NumberGet().String(), 10)
+		if !ok {
+			return decodeErr(path, `cannot decode %s into %s`, v.NumberGet(), t)
+		}
+		rv.Set(reflect.ValueOf(n).Elem())
+		return nil
+	case bigFloatType:
+		if v.Type() != JsonTypeNumber {
+			return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+		}
+		f, _, err := big.ParseFloat(v.NumberGet().String(), 10, 0, big.ToNearestEven)
+		if err != nil {
+			return &DecodeError{Path: path, Err: err}
+		}
+		rv.Set(reflect.ValueOf(f).Elem())
+		return nil
+	case bigRatType:
+		var s string
+		switch v.Type() {
+		case JsonTypeNumber:
+			s = v.NumberGet().String()
+		case JsonTypeString:
+			s = v.StringGet()
+		default:
+			return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+		}
+		r, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return decodeErr(path, `cannot decode %q into %s`, s, t)
+		}
+		rv.Set(reflect.ValueOf(r).Elem())
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		p := reflect.New(t.Elem())
+		if err := decodeValue(path, v, p.Elem()); err != nil {
+			return err
+		}
+		rv.Set(p)
+		return nil
+	case reflect.Interface:
+		if t.NumMethod() > 0 {
+			return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+		}
+		rv.Set(reflect.ValueOf(toGo(v)))
+		return nil
+	}
+
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		b, err := v.MarshalJSON()
+		if err != nil {
+			return &DecodeError{Path: path, Err: err}
+		}
+		if err := rv.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(b); err != nil {
+			return &DecodeError{Path: path, Err: err}
+		}
+		return nil
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		if v.Type() != JsonTypeString {
+			return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+		}
+		if err := rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(v.StringGet())); err != nil {
+			return &DecodeError{Path: path, Err: err}
+		}
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if v.Type() != JsonTypeBoolean {
+			return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+		}
+		rv.SetBool(v.BooleanGet())
+	case reflect.String:
+		if v.Type() != JsonTypeString {
+			return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+		}
+		rv.SetString(v.StringGet())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() != JsonTypeNumber {
+			return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+		}
+		n, err := strconv.ParseInt(v.NumberGet().String(), 10, 64)
+		if err != nil || rv.OverflowInt(n) {
+			return decodeErr(path, `cannot decode %s into %s`, v.NumberGet(), t)
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Type() != JsonTypeNumber {
+			return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+		}
+		n, err := strconv.ParseUint(v.NumberGet().String(), 10, 64)
+		if err != nil || rv.OverflowUint(n) {
+			return decodeErr(path, `cannot decode %s into %s`, v.NumberGet(), t)
+		}
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if v.Type() != JsonTypeNumber {
+			return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+		}
+		f, err := strconv.ParseFloat(v.NumberGet().String(), t.Bits())
+		if err != nil || rv.OverflowFloat(f) {
+			return decodeErr(path, `cannot decode %s into %s`, v.NumberGet(), t)
+		}
+		rv.SetFloat(f)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && v.Type() == JsonTypeString {
+			b, err := base64.StdEncoding.DecodeString(v.StringGet())
+			if err != nil {
+				return &DecodeError{Path: path, Err: err}
+			}
+			rv.SetBytes(b)
+			return nil
+		}
+		if v.Type() != JsonTypeArray {
+			return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+		}
+		s := reflect.MakeSlice(t, v.ArrayLen(), v.ArrayLen())
+		for i := 0; i < v.ArrayLen(); i++ {
+			if err := decodeValue(path.Append(Key(strconv.Itoa(i))), v.ArrayGetElm(i), s.Index(i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(s)
+	case reflect.Array:
+		if v.Type() != JsonTypeArray {
+			return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+		}
+		if v.ArrayLen() != t.Len() {
+			return decodeErr(path, `cannot decode array of length %d into %s`, v.ArrayLen(), t)
+		}
+		for i := 0; i < v.ArrayLen(); i++ {
+			if err := decodeValue(path.Append(Key(strconv.Itoa(i))), v.ArrayGetElm(i), rv.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type() != JsonTypeObject {
+			return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+		}
+		m := reflect.MakeMapWithSize(t, v.ObjectLen())
+		for _, key := range v.ObjectKeys() {
+			elmPath := path.Append(Key(key))
+			k := reflect.New(t.Key()).Elem()
+			if err := decodeKey(elmPath, key, k); err != nil {
+				return err
+			}
+			e := reflect.New(t.Elem()).Elem()
+			if err := decodeValue(elmPath, v.ObjectGetElm(key), e); err != nil {
+				return err
+			}
+			m.SetMapIndex(k, e)
+		}
+		rv.Set(m)
+	case reflect.Struct:
+		if v.Type() != JsonTypeObject {
+			return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+		}
+		fields := jsonFields(t)
+		for _, key := range v.ObjectKeys() {
+			f, ok := findField(fields, key)
+			if !ok {
+				continue
+			}
+			if err := decodeValue(path.Append(Key(key)), v.ObjectGetElm(key), fieldByIndexAlloc(rv, f.index)); err != nil {
+				return err
+			}
+		}
+	default:
+		return decodeErr(path, `cannot decode %s into %s`, v.Type(), t)
+	}
+	return nil
+}
+
+func decodeKey(path Path, key string, k reflect.Value) error {
+	switch k.Kind() {
+	case reflect.String:
+		k.SetString(key)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, 64)
+		if err != nil || k.OverflowInt(n) {
+			return decodeErr(path, `cannot decode key %q into %s`, key, k.Type())
+		}
+		k.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(key, 10, 64)
+		if err != nil || k.OverflowUint(n) {
+			return decodeErr(path, `cannot decode key %q into %s`, key, k.Type())
+		}
+		k.SetUint(n)
+	default:
+		return decodeErr(path, `cannot decode key %q into %s`, key, k.Type())
+	}
+	return nil
+}
+
+// findField returns the field of the name, preferring an exact match to a case-insensitive match as encoding/json does.
+func findField(fields []jsonField, name string) (jsonField, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return jsonField{}, false
+}
+
+// fieldByIndexAlloc returns the nested field of rv allocating nil embedded pointers on the way.
+func fieldByIndexAlloc(rv reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv
+}
+
+// toGo converts v into a plain Go value of nil, bool, json.Number, string, []any, or map[string]any.
+func toGo(v JsonValue) any {
+	switch v.Type() {
+	case JsonTypeBoolean:
+		return v.BooleanGet()
+	case JsonTypeNumber:
+		return v.NumberGet()
+	case JsonTypeString:
+		return v.StringGet()
+	case JsonTypeArray:
+		a := []any{}
+		for i := 0; i < v.ArrayLen(); i++ {
+			a = append(a, toGo(v.ArrayGetElm(i)))
+		}
+		return a
+	case JsonTypeObject:
+		m := map[string]any{}
+		for _, key := range v.ObjectKeys() {
+			m[key] = toGo(v.ObjectGetElm(key))
+		}
+		return m
+	default:
+		return nil
+	}
+}
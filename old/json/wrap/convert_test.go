@@ -0,0 +1,176 @@
+package wrap_test
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Jumpaku/gotaface/old/json/wrap"
+	"github.com/Jumpaku/gotaface/old/test/assert"
+)
+
+type convertBase struct {
+	ID      int64  `json:"id"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type convertExample struct {
+	convertBase
+	Name      string         `json:"name"`
+	Nickname  *string        `json:"nickname"`
+	Tags      []string       `json:"tags"`
+	Scores    map[string]int `json:"scores"`
+	Counts    map[int]uint8  `json:"counts,omitempty"`
+	Pair      [2]bool        `json:"pair"`
+	CreatedAt time.Time      `json:"created_at"`
+	Amount    *big.Int       `json:"amount"`
+	Ratio     big.Rat        `json:"ratio"`
+	Raw       []byte         `json:"raw"`
+	Extra     any            `json:"extra"`
+	Value     wrap.JsonValue `json:"value"`
+	Ignored   string         `json:"-"`
+	Untagged  float64
+	internal  int
+}
+
+func TestEncode(t *testing.T) {
+	nickname := "jumpaku"
+	amount, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	v := convertExample{
+		convertBase: convertBase{ID: 1},
+		Name:        "a",
+		Nickname:    &nickname,
+		Tags:        []string{"x", "y"},
+		Scores:      map[string]int{"b": 2, "a": 1},
+		Pair:        [2]bool{true, false},
+		CreatedAt:   time.Date(2023, 4, 5, 6, 7, 8, 9, time.UTC),
+		Amount:      amount,
+		Ratio:       *big.NewRat(1, 8),
+		Raw:         []byte("abc"),
+		Extra:       map[string]any{"k": []any{1.5, nil}},
+		Value:       wrap.Array(wrap.Number(1)),
+		Ignored:     "ignored",
+		Untagged:    0.25,
+		internal:    1,
+	}
+
+	got := wrap.Encode(v)
+
+	want := `{"id":1,"name":"a","nickname":"jumpaku","tags":["x","y"],"scores":{"a":1,"b":2},"pair":[true,false],` +
+		`"created_at":"2023-04-05T06:07:08.000000009Z","amount":123456789012345678901234567890,"ratio":0.125,"raw":"YWJj",` +
+		`"extra":{"k":[1.5,null]},"value":[1],"Untagged":0.25}`
+	assert.Equal(t, marshal(t, got), want)
+}
+
+func TestEncode_Values(t *testing.T) {
+	testcases := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{name: `nil`, v: nil, want: `null`},
+		{name: `nil pointer`, v: (*int)(nil), want: `null`},
+		{name: `nil slice`, v: []int(nil), want: `null`},
+		{name: `nil map`, v: map[string]int(nil), want: `null`},
+		{name: `empty slice`, v: []int{}, want: `[]`},
+		{name: `uint64`, v: uint64(18446744073709551615), want: `18446744073709551615`},
+		{name: `float32`, v: float32(0.1), want: `0.1`},
+		{name: `big float`, v: big.NewFloat(1e100), want: `1e+100`},
+		{name: `big rat not decimal`, v: big.NewRat(1, 3), want: `"1/3"`},
+		{name: `big rat integer`, v: big.NewRat(-4, 2), want: `-2`},
+		{name: `big rat pointer`, v: big.NewRat(1, 2), want: `0.5`},
+		{name: `json value`, v: wrap.Object(map[string]wrap.JsonValue{"a": wrap.Null()}), want: `{"a":null}`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, marshal(t, wrap.Encode(tc.v)), tc.want)
+		})
+	}
+}
+
+func TestEncode_Panic(t *testing.T) {
+	testcases := []struct {
+		name string
+		v    any
+	}{
+		{name: `channel`, v: map[string]any{"c": make(chan int)}},
+		{name: `NaN`, v: []float64{math.NaN()}},
+		{name: `complex`, v: complex(1, 2)},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				assert.NotEqual(t, recover(), nil)
+			}()
+			wrap.Encode(tc.v)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	v := parse(t, `{
+		"id": 1, "comment": "c", "name": "a", "nickname": null, "tags": ["x","y"], "scores": {"b": 2, "a": 1},
+		"counts": {"3": 4}, "pair": [true, false], "created_at": "2023-04-05T06:07:08.000000009Z",
+		"amount": 123456789012345678901234567890, "ratio": "1/3", "raw": "YWJj",
+		"extra": {"k": [1.5, null]}, "value": [1], "Ignored": "x", "untagged": 0.25, "unknown": true
+	}`)
+
+	got, err := wrap.Decode[convertExample](v)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, got.ID, int64(1))
+	assert.Equal(t, got.Comment, "c")
+	assert.Equal(t, got.Name, "a")
+	assert.Equal(t, got.Nickname == nil, true)
+	assert.Equal(t, len(got.Tags), 2)
+	assert.Equal(t, got.Tags[1], "y")
+	assert.Equal(t, got.Scores["a"], 1)
+	assert.Equal(t, got.Scores["b"], 2)
+	assert.Equal(t, got.Counts[3], uint8(4))
+	assert.Equal(t, got.Pair, [2]bool{true, false})
+	assert.Equal(t, got.CreatedAt.Equal(time.Date(2023, 4, 5, 6, 7, 8, 9, time.UTC)), true)
+	assert.Equal(t, got.Amount.String(), "123456789012345678901234567890")
+	assert.Equal(t, got.Ratio.String(), "1/3")
+	assert.Equal(t, string(got.Raw), "abc")
+	assert.Equal(t, marshal(t, wrap.Encode(got.Extra)), `{"k":[1.5,null]}`)
+	assert.Equal(t, marshal(t, got.Value), `[1]`)
+	assert.Equal(t, got.Ignored, "")
+	assert.Equal(t, got.Untagged, 0.25)
+}
+
+func TestDecode_Roundtrip(t *testing.T) {
+	v := parse(t, `{"id":1,"name":"a","nickname":"n","tags":[],"scores":{},"counts":{"1":2},"pair":[false,true],`+
+		`"created_at":"2023-04-05T06:07:08Z","amount":-1,"ratio":0.5,"raw":"","extra":[true,"x"],"value":{"a":null},"Untagged":1}`)
+
+	got, err := wrap.Decode[convertExample](v)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, marshal(t, wrap.Encode(got)), marshal(t, v))
+}
+
+func TestDecode_Error(t *testing.T) {
+	testcases := []struct {
+		name string
+		doc  string
+		path string
+	}{
+		{name: `type mismatch`, doc: `{"name": 1}`, path: `/name`},
+		{name: `integer overflow`, doc: `{"counts": {"1": 256}}`, path: `/counts/1`},
+		{name: `invalid key`, doc: `{"counts": {"x": 1}}`, path: `/counts/x`},
+		{name: `not integer`, doc: `{"id": 1.5}`, path: `/id`},
+		{name: `array length`, doc: `{"pair": [true]}`, path: `/pair`},
+		{name: `nested`, doc: `{"tags": ["a", null, 3]}`, path: `/tags/2`},
+		{name: `invalid time`, doc: `{"created_at": "yesterday"}`, path: `/created_at`},
+		{name: `invalid big int`, doc: `{"amount": 1.5}`, path: `/amount`},
+		{name: `invalid base64`, doc: `{"raw": "!"}`, path: `/raw`},
+		{name: `root`, doc: `[]`, path: ``},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := wrap.Decode[convertExample](parse(t, tc.doc))
+			var decodeErr *wrap.DecodeError
+			assert.Equal(t, errors.As(err, &decodeErr), true)
+			assert.Equal(t, decodeErr.Path.Pointer(), tc.path)
+		})
+	}
+}
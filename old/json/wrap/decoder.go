@@ -0,0 +1,107 @@
+package wrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Decoder reads a JSON document from a stream and yields the values at a depth with their paths without loading the whole document.
+// For example, a Decoder with depth 2 yields each row of each table in a dump `{"table": [row, ...], ...}` with the path `/table/index`.
+type Decoder struct {
+	decoder *json.Decoder
+	depth   int
+	started bool
+	stack   []decoderFrame
+}
+
+type decoderFrame struct {
+	array bool
+	index int
+	key   string
+}
+
+// NewDecoder returns a Decoder which reads a JSON document from r and yields the values at depth, where the root value is at depth 0.
+func NewDecoder(r io.Reader, depth int) *Decoder {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	return &Decoder{decoder: decoder, depth: depth}
+}
+
+// Next returns the next value at the depth of the Decoder with its path.
+// Values shallower than the depth are skipped.
+// Next returns io.EOF if the document has no more values at the depth.
+func (d *Decoder) Next() (Path, JsonValue, error) {
+	for {
+		if n := len(d.stack); n > 0 {
+			top := &d.stack[n-1]
+			if !d.decoder.More() {
+				if _, err := d.token(); err != nil {
+					return nil, nil, err
+				}
+				d.stack = d.stack[:n-1]
+				continue
+			}
+			if top.array {
+				top.key = strconv.Itoa(top.index)
+				top.index++
+			} else {
+				key, err := d.token()
+				if err != nil {
+					return nil, nil, err
+				}
+				top.key = key.(string)
+			}
+		} else if d.started {
+			return nil, nil, io.EOF
+		}
+		d.started = true
+
+		if len(d.stack) == d.depth {
+			v, err := decode(d.decoder)
+			if err != nil {
+				return nil, nil, d.wrapErr(err)
+			}
+			return d.path(), v, nil
+		}
+
+		token, err := d.token()
+		if err != nil {
+			return nil, nil, err
+		}
+		switch token {
+		case json.Delim('['):
+			d.stack = append(d.stack, decoderFrame{array: true})
+		case json.Delim('{'):
+			d.stack = append(d.stack, decoderFrame{})
+		}
+	}
+}
+
+func (d *Decoder) path() Path {
+	path := Path{}
+	for _, frame := range d.stack {
+		path = path.Append(Key(frame.key))
+	}
+	return path
+}
+
+func (d *Decoder) token() (json.Token, error) {
+	token, err := d.decoder.Token()
+	if err != nil {
+		return nil, d.wrapErr(err)
+	}
+	return token, nil
+}
+
+func (d *Decoder) wrapErr(err error) error {
+	if err == io.EOF {
+		if len(d.stack) == 0 {
+			return io.EOF
+		}
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf(`fail to decode JSON at %q: %w`, d.path().Pointer(), err)
+}
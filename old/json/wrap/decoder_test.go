@@ -0,0 +1,166 @@
+package wrap_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/json/wrap"
+	"github.com/Jumpaku/gotaface/old/test/assert"
+)
+
+func decodeAll(t *testing.T, doc string, depth int) ([]string, error) {
+	t.Helper()
+
+	events := []string{}
+	d := wrap.NewDecoder(strings.NewReader(doc), depth)
+	for {
+		path, v, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+		events = append(events, path.Pointer()+`=`+marshal(t, v))
+	}
+}
+
+func TestDecoder_Next(t *testing.T) {
+	doc := `{
+		"User": [{"id": 1, "name": "a"}, {"id": 2, "name": null}],
+		"Empty": [],
+		"Comment": [{"id": 1, "text": "x"}],
+		"count": 3
+	}`
+	testcases := []struct {
+		name  string
+		doc   string
+		depth int
+		want  []string
+	}{
+		{
+			name:  `depth 0`,
+			doc:   `{"b": 1, "a": [true]}`,
+			depth: 0,
+			want:  []string{`={"b":1,"a":[true]}`},
+		},
+		{
+			name:  `depth 1`,
+			doc:   doc,
+			depth: 1,
+			want: []string{
+				`/User=[{"id":1,"name":"a"},{"id":2,"name":null}]`,
+				`/Empty=[]`,
+				`/Comment=[{"id":1,"text":"x"}]`,
+				`/count=3`,
+			},
+		},
+		{
+			name:  `depth 2`,
+			doc:   doc,
+			depth: 2,
+			want: []string{
+				`/User/0={"id":1,"name":"a"}`,
+				`/User/1={"id":2,"name":null}`,
+				`/Comment/0={"id":1,"text":"x"}`,
+			},
+		},
+		{
+			name:  `depth 3`,
+			doc:   `[[["a/b"]], {"k~": {"x": 1.50}}]`,
+			depth: 3,
+			want:  []string{`/0/0/0="a/b"`, `/1/k~0/x=1.50`},
+		},
+		{
+			name:  `scalar root`,
+			doc:   `"abc"`,
+			depth: 1,
+			want:  []string{},
+		},
+		{
+			name:  `empty`,
+			doc:   ``,
+			depth: 0,
+			want:  []string{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := decodeAll(t, tc.doc, tc.depth)
+			assert.Equal(t, err, nil)
+			assert.Equal(t, strings.Join(got, "\n"), strings.Join(tc.want, "\n"))
+		})
+	}
+}
+
+func TestDecoder_Next_Error(t *testing.T) {
+	testcases := []struct {
+		name  string
+		doc   string
+		depth int
+		want  []string
+	}{
+		{
+			name:  `truncated in value`,
+			doc:   `{"User": [{"id": 1}, {"id": `,
+			depth: 2,
+			want:  []string{`/User/0={"id":1}`},
+		},
+		{
+			name:  `truncated in container`,
+			doc:   `{"User": [{"id": 1},`,
+			depth: 2,
+			want:  []string{`/User/0={"id":1}`},
+		},
+		{
+			name:  `truncated root`,
+			doc:   `[1, 2`,
+			depth: 0,
+			want:  []string{},
+		},
+		{
+			name:  `syntax error`,
+			doc:   `{"User": [{"id": 1}, {"id" 2}]}`,
+			depth: 1,
+			want:  []string{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := decodeAll(t, tc.doc, tc.depth)
+			assert.NotEqual(t, err, nil)
+			assert.Equal(t, strings.Join(got, "\n"), strings.Join(tc.want, "\n"))
+		})
+	}
+}
+
+func TestDecoder_Next_Large(t *testing.T) {
+	r, w := io.Pipe()
+	go func() {
+		_, _ = w.Write([]byte(`{"rows":[`))
+		for i := 0; i < 10000; i++ {
+			if i > 0 {
+				_, _ = w.Write([]byte(`,`))
+			}
+			_, _ = w.Write([]byte(`{"id":` + strings.Repeat("1", 1+i%10) + `}`))
+		}
+		_, _ = w.Write([]byte(`]}`))
+		_ = w.Close()
+	}()
+
+	d := wrap.NewDecoder(r, 2)
+	n := 0
+	for {
+		path, v, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.Equal(t, err, nil)
+		assert.Equal(t, path.Len(), 2)
+		assert.Equal(t, v.ObjectLen(), 1)
+		n++
+	}
+	assert.Equal(t, n, 10000)
+}
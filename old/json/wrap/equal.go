@@ -0,0 +1,111 @@
+package wrap
+
+import (
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/Jumpaku/gotaface/old/errors"
+)
+
+// Equal reports whether a and b represent the same JSON value, where numbers are compared by their numeric values.
+func Equal(a JsonValue, b JsonValue) bool {
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a.Type() {
+	case JsonTypeNull:
+		return true
+	case JsonTypeBoolean:
+		return a.BooleanGet() == b.BooleanGet()
+	case JsonTypeString:
+		return a.StringGet() == b.StringGet()
+	case JsonTypeNumber:
+		x, okX := new(big.Rat).SetString(a.NumberGet().String())
+		y, okY := new(big.Rat).SetString(b.NumberGet().String())
+		if !okX || !okY {
+			return a.NumberGet() == b.NumberGet()
+		}
+		return x.Cmp(y) == 0
+	case JsonTypeArray:
+		if a.ArrayLen() != b.ArrayLen() {
+			return false
+		}
+		for i := 0; i < a.ArrayLen(); i++ {
+			if !Equal(a.ArrayGetElm(i), b.ArrayGetElm(i)) {
+				return false
+			}
+		}
+		return true
+	case JsonTypeObject:
+		if a.ObjectLen() != b.ObjectLen() {
+			return false
+		}
+		for _, key := range a.ObjectKeys() {
+			if !b.ObjectHasElm(key) || !Equal(a.ObjectGetElm(key), b.ObjectGetElm(key)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return errors.Unexpected1[bool](`invalid JsonType: %v`, a.Type())
+	}
+}
+
+// Difference is a difference between JSON values at Path.
+// Old is nil if the value is added, and New is nil if the value is removed.
+type Difference struct {
+	Path Path
+	Old  JsonValue
+	New  JsonValue
+}
+
+// Diff returns the differences between a and b at the deepest paths where they differ.
+// Object members are compared by key in sorted order, and array elements are compared by index, where extra elements are reported as added or removed.
+// Values of different types at the same path are reported as a single difference at that path.
+func Diff(a JsonValue, b JsonValue) []Difference {
+	return diff(Path{}, a, b, []Difference{})
+}
+
+func diff(path Path, a JsonValue, b JsonValue, diffs []Difference) []Difference {
+	if Equal(a, b) {
+		return diffs
+	}
+
+	switch {
+	case a.Type() == JsonTypeObject && b.Type() == JsonTypeObject:
+		keys := a.ObjectKeys()
+		for _, key := range b.ObjectKeys() {
+			if !a.ObjectHasElm(key) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			switch {
+			case !b.ObjectHasElm(key):
+				diffs = append(diffs, Difference{Path: path.Append(Key(key)), Old: a.ObjectGetElm(key)})
+			case !a.ObjectHasElm(key):
+				diffs = append(diffs, Difference{Path: path.Append(Key(key)), New: b.ObjectGetElm(key)})
+			default:
+				diffs = diff(path.Append(Key(key)), a.ObjectGetElm(key), b.ObjectGetElm(key), diffs)
+			}
+		}
+	case a.Type() == JsonTypeArray && b.Type() == JsonTypeArray:
+		for i := 0; i < a.ArrayLen() || i < b.ArrayLen(); i++ {
+			key := Key(strconv.Itoa(i))
+			switch {
+			case i >= b.ArrayLen():
+				diffs = append(diffs, Difference{Path: path.Append(key), Old: a.ArrayGetElm(i)})
+			case i >= a.ArrayLen():
+				diffs = append(diffs, Difference{Path: path.Append(key), New: b.ArrayGetElm(i)})
+			default:
+				diffs = diff(path.Append(key), a.ArrayGetElm(i), b.ArrayGetElm(i), diffs)
+			}
+		}
+	default:
+		diffs = append(diffs, Difference{Path: path, Old: a, New: b})
+	}
+	return diffs
+}
@@ -0,0 +1,90 @@
+package wrap_test
+
+import (
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/json/wrap"
+	"github.com/Jumpaku/gotaface/old/test/assert"
+)
+
+func TestEqual(t *testing.T) {
+	testcases := []struct {
+		a    string
+		b    string
+		want bool
+	}{
+		{`null`, `null`, true},
+		{`null`, `false`, false},
+		{`true`, `true`, true},
+		{`true`, `false`, false},
+		{`"a"`, `"a"`, true},
+		{`"a"`, `"b"`, false},
+		{`1`, `1.0`, true},
+		{`100`, `1e2`, true},
+		{`0.1`, `0.10`, true},
+		{`1`, `2`, false},
+		{`1`, `"1"`, false},
+		{`[1,[2]]`, `[1,[2.0]]`, true},
+		{`[1,2]`, `[2,1]`, false},
+		{`[1]`, `[1,1]`, false},
+		{`{"a":1,"b":{"c":[]}}`, `{"b":{"c":[]},"a":1.0}`, true},
+		{`{"a":1}`, `{"b":1}`, false},
+		{`{"a":1}`, `{"a":1,"b":1}`, false},
+		{`{}`, `[]`, false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.a+` `+tc.b, func(t *testing.T) {
+			assert.Equal(t, wrap.Equal(parse(t, tc.a), parse(t, tc.b)), tc.want)
+			assert.Equal(t, wrap.Equal(parse(t, tc.b), parse(t, tc.a)), tc.want)
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Run(`equal`, func(t *testing.T) {
+		diffs := wrap.Diff(parse(t, `{"a":[1,{"b":2}]}`), parse(t, `{"a":[1.0,{"b":2e0}]}`))
+		assert.Equal(t, len(diffs), 0)
+	})
+	t.Run(`different`, func(t *testing.T) {
+		diffs := wrap.Diff(
+			parse(t, `{"a":1,"b":{"c":"x","d":[1,2,3]},"e":[],"f":null}`),
+			parse(t, `{"b":{"c":"y","d":[1,5]},"e":{},"f":null,"g":true}`),
+		)
+
+		want := []struct {
+			pointer string
+			old     string
+			new     string
+		}{
+			{`/a`, `1`, ``},
+			{`/b/c`, `"x"`, `"y"`},
+			{`/b/d/1`, `2`, `5`},
+			{`/b/d/2`, `3`, ``},
+			{`/e`, `[]`, `{}`},
+			{`/g`, ``, `true`},
+		}
+		assert.Equal(t, len(diffs), len(want))
+		for i, want := range want {
+			if i >= len(diffs) {
+				break
+			}
+			got := diffs[i]
+			assert.Equal(t, got.Path.Pointer(), want.pointer)
+			if want.old == `` {
+				assert.Equal(t, got.Old == nil, true)
+			} else {
+				assert.Equal(t, marshal(t, got.Old), want.old)
+			}
+			if want.new == `` {
+				assert.Equal(t, got.New == nil, true)
+			} else {
+				assert.Equal(t, marshal(t, got.New), want.new)
+			}
+		}
+	})
+	t.Run(`root`, func(t *testing.T) {
+		diffs := wrap.Diff(parse(t, `1`), parse(t, `"1"`))
+		assert.Equal(t, len(diffs), 1)
+		assert.Equal(t, diffs[0].Path.Len(), 0)
+	})
+}
@@ -0,0 +1,256 @@
+package wrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+const (
+	PatchOpAdd     = `add`
+	PatchOpRemove  = `remove`
+	PatchOpReplace = `replace`
+	PatchOpMove    = `move`
+	PatchOpCopy    = `copy`
+	PatchOpTest    = `test`
+)
+
+// PatchOperation is an operation of JSON Patch defined in RFC 6902, where Path and From are JSON Pointers.
+type PatchOperation struct {
+	Op    string
+	Path  string
+	From  string
+	Value JsonValue
+}
+
+type patchOperationJSON struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+func (o PatchOperation) MarshalJSON() ([]byte, error) {
+	j := patchOperationJSON{Op: o.Op, Path: o.Path, From: o.From}
+	switch o.Op {
+	case PatchOpAdd, PatchOpReplace, PatchOpTest:
+		if o.Value == nil {
+			return nil, fmt.Errorf(`value is required for operation %s`, o.Op)
+		}
+		b, err := o.Value.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf(`fail to marshal value: %w`, err)
+		}
+		j.Value = b
+	}
+	return json.Marshal(j)
+}
+
+func (o *PatchOperation) UnmarshalJSON(b []byte) error {
+	decoder := json.NewDecoder(bytes.NewBuffer(b))
+	decoder.DisallowUnknownFields()
+
+	var j patchOperationJSON
+	if err := decoder.Decode(&j); err != nil {
+		return fmt.Errorf(`fail to unmarshal JSON Patch operation: %w`, err)
+	}
+
+	*o = PatchOperation{Op: j.Op, Path: j.Path, From: j.From}
+	if j.Value != nil {
+		o.Value = Null()
+		if err := o.Value.UnmarshalJSON(j.Value); err != nil {
+			return fmt.Errorf(`fail to unmarshal value: %w`, err)
+		}
+	}
+	return nil
+}
+
+// Patch is a JSON Patch defined in RFC 6902.
+type Patch []PatchOperation
+
+// ApplyPatch applies the operations in the patch to v in order.
+// If any operation fails, an error is returned and v is not modified.
+func ApplyPatch(v JsonValue, patch Patch) error {
+	patched := v.Clone()
+	for i, o := range patch {
+		if err := applyOperation(patched, o); err != nil {
+			return fmt.Errorf(`fail to apply operation %d %s: %w`, i, o.Op, err)
+		}
+	}
+
+	v.Assign(patched)
+	return nil
+}
+
+func applyOperation(v JsonValue, o PatchOperation) error {
+	path, err := ParsePointer(o.Path)
+	if err != nil {
+		return fmt.Errorf(`invalid path: %w`, err)
+	}
+
+	switch o.Op {
+	default:
+		return fmt.Errorf(`unsupported operation: %q`, o.Op)
+	case PatchOpAdd:
+		if o.Value == nil {
+			return fmt.Errorf(`value is required`)
+		}
+		return add(v, path, o.Value.Clone())
+	case PatchOpRemove:
+		return Delete(v, path)
+	case PatchOpReplace:
+		if o.Value == nil {
+			return fmt.Errorf(`value is required`)
+		}
+		if _, ok := Find(v, path); !ok {
+			return fmt.Errorf(`%s not found`, o.Path)
+		}
+		return Set(v, path, o.Value.Clone())
+	case PatchOpMove, PatchOpCopy:
+		from, err := ParsePointer(o.From)
+		if err != nil {
+			return fmt.Errorf(`invalid from: %w`, err)
+		}
+		found, ok := Find(v, from)
+		if !ok {
+			return fmt.Errorf(`%s not found`, o.From)
+		}
+		if o.Op == PatchOpCopy {
+			return add(v, path, found.Clone())
+		}
+		if from.Len() < path.Len() && from.Equals(path[:from.Len()]) {
+			return fmt.Errorf(`%s cannot be moved into its child %s`, o.From, o.Path)
+		}
+		if err := Delete(v, from); err != nil {
+			return err
+		}
+		return add(v, path, found)
+	case PatchOpTest:
+		if o.Value == nil {
+			return fmt.Errorf(`value is required`)
+		}
+		found, ok := Find(v, path)
+		if !ok {
+			return fmt.Errorf(`%s not found`, o.Path)
+		}
+		if !Equal(found, o.Value) {
+			return fmt.Errorf(`value at %s is not equal to the expected value`, o.Path)
+		}
+		return nil
+	}
+}
+
+// add inserts val at the path, where the elements at and after the index are shifted if the parent is an array.
+func add(v JsonValue, path Path, val JsonValue) error {
+	if path.Len() == 0 {
+		return Set(v, path, val)
+	}
+
+	parent, ok := Find(v, path[:path.Len()-1])
+	if !ok {
+		return fmt.Errorf(`parent of %s not found`, path.Pointer())
+	}
+	if parent.Type() != JsonTypeArray {
+		return Set(v, path, val)
+	}
+
+	key := path.Get(path.Len() - 1)
+	if key == "-" {
+		parent.ArrayAddElm(val)
+		return nil
+	}
+	index, ok := arrayIndex(key, parent.ArrayLen()+1)
+	if !ok {
+		return fmt.Errorf(`invalid array index in %s`, path.Pointer())
+	}
+	inserted := parent.ArraySlice(0, index)
+	inserted.ArrayAddElm(val)
+	for i := index; i < parent.ArrayLen(); i++ {
+		inserted.ArrayAddElm(parent.ArrayGetElm(i))
+	}
+	parent.Assign(inserted)
+	return nil
+}
+
+// CreatePatch returns a JSON Patch which transforms from into to.
+// Object members are compared recursively, and array elements are compared recursively by index, where extra elements are added or removed at the end.
+func CreatePatch(from JsonValue, to JsonValue) Patch {
+	return createPatch(Path{}, from, to, Patch{})
+}
+
+func createPatch(path Path, from JsonValue, to JsonValue, patch Patch) Patch {
+	if Equal(from, to) {
+		return patch
+	}
+
+	switch {
+	case from.Type() == JsonTypeObject && to.Type() == JsonTypeObject:
+		fromKeys := from.ObjectKeys()
+		sort.Strings(fromKeys)
+		for _, key := range fromKeys {
+			if !to.ObjectHasElm(key) {
+				patch = append(patch, PatchOperation{Op: PatchOpRemove, Path: path.Append(Key(key)).Pointer()})
+			}
+		}
+		toKeys := to.ObjectKeys()
+		sort.Strings(toKeys)
+		for _, key := range toKeys {
+			if from.ObjectHasElm(key) {
+				patch = createPatch(path.Append(Key(key)), from.ObjectGetElm(key), to.ObjectGetElm(key), patch)
+			} else {
+				patch = append(patch, PatchOperation{Op: PatchOpAdd, Path: path.Append(Key(key)).Pointer(), Value: to.ObjectGetElm(key).Clone()})
+			}
+		}
+	case from.Type() == JsonTypeArray && to.Type() == JsonTypeArray:
+		n := from.ArrayLen()
+		if to.ArrayLen() < n {
+			n = to.ArrayLen()
+		}
+		for i := 0; i < n; i++ {
+			patch = createPatch(path.Append(Key(strconv.Itoa(i))), from.ArrayGetElm(i), to.ArrayGetElm(i), patch)
+		}
+		for i := from.ArrayLen() - 1; i >= n; i-- {
+			patch = append(patch, PatchOperation{Op: PatchOpRemove, Path: path.Append(Key(strconv.Itoa(i))).Pointer()})
+		}
+		for i := n; i < to.ArrayLen(); i++ {
+			patch = append(patch, PatchOperation{Op: PatchOpAdd, Path: path.Append(Key(strconv.Itoa(i))).Pointer(), Value: to.ArrayGetElm(i).Clone()})
+		}
+	default:
+		patch = append(patch, PatchOperation{Op: PatchOpReplace, Path: path.Pointer(), Value: to.Clone()})
+	}
+	return patch
+}
+
+// ApplyMergePatch applies the JSON Merge Patch defined in RFC 7396 to v.
+// Members of objects in the patch are merged recursively, where null removes the member, and any other values replace the target.
+func ApplyMergePatch(v JsonValue, patch JsonValue) {
+	v.Assign(mergePatch(v, patch))
+}
+
+func mergePatch(target JsonValue, patch JsonValue) JsonValue {
+	if patch.Type() != JsonTypeObject {
+		return patch.Clone()
+	}
+
+	merged := Object()
+	if target.Type() == JsonTypeObject {
+		for _, key := range target.ObjectKeys() {
+			merged.ObjectSetElm(key, target.ObjectGetElm(key))
+		}
+	}
+	for _, key := range patch.ObjectKeys() {
+		val := patch.ObjectGetElm(key)
+		if val.Type() == JsonTypeNull {
+			merged.ObjectDelElm(key)
+			continue
+		}
+		elm := Null()
+		if merged.ObjectHasElm(key) {
+			elm = merged.ObjectGetElm(key)
+		}
+		merged.ObjectSetElm(key, mergePatch(elm, val))
+	}
+	return merged
+}
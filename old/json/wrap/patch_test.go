@@ -0,0 +1,220 @@
+package wrap_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/json/wrap"
+	"github.com/Jumpaku/gotaface/old/test/assert"
+)
+
+func parse(t *testing.T, s string) wrap.JsonValue {
+	t.Helper()
+
+	v := wrap.Null()
+	err := v.UnmarshalJSON([]byte(s))
+	assert.Equal(t, err, nil)
+	return v
+}
+
+func parsePatch(t *testing.T, s string) wrap.Patch {
+	t.Helper()
+
+	var p wrap.Patch
+	err := json.Unmarshal([]byte(s), &p)
+	assert.Equal(t, err, nil)
+	return p
+}
+
+func TestApplyPatch(t *testing.T) {
+	testcases := []struct {
+		name   string
+		doc    string
+		patch  string
+		want   string
+		hasErr bool
+	}{
+		{
+			name:  `add object member`,
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz","value":"qux"}]`,
+			want:  `{"foo":"bar","baz":"qux"}`,
+		},
+		{
+			name:  `add array element`,
+			doc:   `{"foo":["bar","baz"]}`,
+			patch: `[{"op":"add","path":"/foo/1","value":"qux"},{"op":"add","path":"/foo/-","value":null}]`,
+			want:  `{"foo":["bar","qux","baz",null]}`,
+		},
+		{
+			name:  `remove`,
+			doc:   `{"baz":"qux","foo":["bar","qux","baz"]}`,
+			patch: `[{"op":"remove","path":"/baz"},{"op":"remove","path":"/foo/1"}]`,
+			want:  `{"foo":["bar","baz"]}`,
+		},
+		{
+			name:  `replace`,
+			doc:   `{"baz":"qux","foo":"bar"}`,
+			patch: `[{"op":"replace","path":"/baz","value":"boo"}]`,
+			want:  `{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			name:  `move`,
+			doc:   `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			want:  `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			name:  `move array element`,
+			doc:   `{"foo":["all","grass","cows","eat"]}`,
+			patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			want:  `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			name:  `copy`,
+			doc:   `{"foo":{"bar":1}}`,
+			patch: `[{"op":"copy","from":"/foo","path":"/baz"},{"op":"replace","path":"/baz/bar","value":2}]`,
+			want:  `{"foo":{"bar":1},"baz":{"bar":2}}`,
+		},
+		{
+			name:  `test`,
+			doc:   `{"baz":"qux","foo":["a",2,"c"]}`,
+			patch: `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`,
+			want:  `{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			name:   `test fails`,
+			doc:    `{"baz":"qux"}`,
+			patch:  `[{"op":"add","path":"/foo","value":1},{"op":"test","path":"/baz","value":"bar"}]`,
+			want:   `{"baz":"qux"}`,
+			hasErr: true,
+		},
+		{
+			name:   `remove missing`,
+			doc:    `{"foo":"bar"}`,
+			patch:  `[{"op":"remove","path":"/baz"}]`,
+			want:   `{"foo":"bar"}`,
+			hasErr: true,
+		},
+		{
+			name:   `replace missing`,
+			doc:    `{"foo":"bar"}`,
+			patch:  `[{"op":"replace","path":"/baz","value":1}]`,
+			want:   `{"foo":"bar"}`,
+			hasErr: true,
+		},
+		{
+			name:   `move into child`,
+			doc:    `{"foo":{"bar":1}}`,
+			patch:  `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`,
+			want:   `{"foo":{"bar":1}}`,
+			hasErr: true,
+		},
+		{
+			name:   `unsupported op`,
+			doc:    `{}`,
+			patch:  `[{"op":"unknown","path":"/foo"}]`,
+			want:   `{}`,
+			hasErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := parse(t, tc.doc)
+			err := wrap.ApplyPatch(v, parsePatch(t, tc.patch))
+			assert.Equal(t, err != nil, tc.hasErr)
+			assert.Equal(t, marshal(t, v), tc.want)
+		})
+	}
+}
+
+func TestPatch_MarshalJSON(t *testing.T) {
+	p := parsePatch(t, `[{"op":"add","path":"/a","value":null},{"op":"remove","path":"/b"},{"op":"move","from":"/c","path":"/d"}]`)
+	b, err := json.Marshal(p)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, string(b), `[{"op":"add","path":"/a","value":null},{"op":"remove","path":"/b"},{"op":"move","path":"/d","from":"/c"}]`)
+}
+
+func TestCreatePatch(t *testing.T) {
+	testcases := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{
+		{
+			name: `same`,
+			from: `{"a":[1,{"b":true}]}`,
+			to:   `{"a":[1,{"b":true}]}`,
+			want: `[]`,
+		},
+		{
+			name: `object`,
+			from: `{"a":1,"b":{"c":"x","d":"y"}}`,
+			to:   `{"b":{"c":"z","e":null},"f":[]}`,
+			want: `[{"op":"remove","path":"/a"},{"op":"remove","path":"/b/d"},{"op":"replace","path":"/b/c","value":"z"},{"op":"add","path":"/b/e","value":null},{"op":"add","path":"/f","value":[]}]`,
+		},
+		{
+			name: `shorter array`,
+			from: `[1,2,3,4]`,
+			to:   `[1,5]`,
+			want: `[{"op":"replace","path":"/1","value":5},{"op":"remove","path":"/3"},{"op":"remove","path":"/2"}]`,
+		},
+		{
+			name: `longer array`,
+			from: `[1]`,
+			to:   `[1,2,3]`,
+			want: `[{"op":"add","path":"/1","value":2},{"op":"add","path":"/2","value":3}]`,
+		},
+		{
+			name: `type changed`,
+			from: `{"a":[1]}`,
+			to:   `{"a":{"0":1}}`,
+			want: `[{"op":"replace","path":"/a","value":{"0":1}}]`,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			from, to := parse(t, tc.from), parse(t, tc.to)
+			patch := wrap.CreatePatch(from, to)
+			b, err := json.Marshal(patch)
+			assert.Equal(t, err, nil)
+			assert.Equal(t, string(b), tc.want)
+
+			err = wrap.ApplyPatch(from, patch)
+			assert.Equal(t, err, nil)
+			assert.Equal(t, marshal(t, from), marshal(t, parse(t, tc.to)))
+		})
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	testcases := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.target+` `+tc.patch, func(t *testing.T) {
+			v := parse(t, tc.target)
+			wrap.ApplyMergePatch(v, parse(t, tc.patch))
+			assert.Equal(t, marshal(t, v), tc.want)
+		})
+	}
+}
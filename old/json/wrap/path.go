@@ -65,14 +65,14 @@ func walkImpl(parentKey Path, val JsonValue, walkFunc func(key Path, val JsonVal
 	return nil
 }
 
+// Find returns the value at the path in v in O(depth) time.
 func Find(v JsonValue, path Path) (JsonValue, bool) {
-	var found JsonValue
-	_ = Walk(v, func(p Path, val JsonValue) error {
-		if p.Equals(path) {
-			found = val
+	for _, key := range path {
+		var ok bool
+		if v, ok = child(v, key); !ok {
+			return nil, false
 		}
-		return nil
-	})
+	}
 
-	return found, found != nil
+	return v, true
 }
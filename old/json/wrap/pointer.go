@@ -0,0 +1,147 @@
+package wrap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParsePointer parses a JSON Pointer defined in RFC 6901, e.g., `/users/0/name`, into a Path.
+// The empty string represents the whole document and `~1` and `~0` are unescaped into `/` and `~` respectively.
+func ParsePointer(pointer string) (Path, error) {
+	if pointer == "" {
+		return Path{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf(`JSON pointer must start with '/': %q`, pointer)
+	}
+
+	path := Path{}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		for i := 0; i < len(token); i++ {
+			if token[i] == '~' && (i+1 >= len(token) || (token[i+1] != '0' && token[i+1] != '1')) {
+				return nil, fmt.Errorf(`invalid escape in JSON pointer: %q`, pointer)
+			}
+		}
+		path = path.Append(Key(strings.NewReplacer("~1", "/", "~0", "~").Replace(token)))
+	}
+
+	return path, nil
+}
+
+// Pointer returns the path formatted as a JSON Pointer defined in RFC 6901.
+func (k Path) Pointer() string {
+	var b strings.Builder
+	for _, key := range k {
+		b.WriteString("/")
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(key.String()))
+	}
+	return b.String()
+}
+
+// arrayIndex returns the index represented by the key in an array of length n, which must be a non-negative integer without leading zeros.
+func arrayIndex(key Key, n int) (int, bool) {
+	s := key.String()
+	if s == "" || (len(s) > 1 && s[0] == '0') {
+		return 0, false
+	}
+	for _, c := range s {
+		if c < '0' || '9' < c {
+			return 0, false
+		}
+	}
+	index, err := strconv.Atoi(s)
+	if err != nil || index >= n {
+		return 0, false
+	}
+	return index, true
+}
+
+// child returns the element of v specified by the key.
+func child(v JsonValue, key Key) (JsonValue, bool) {
+	switch v.Type() {
+	case JsonTypeObject:
+		if !v.ObjectHasElm(key.String()) {
+			return nil, false
+		}
+		return v.ObjectGetElm(key.String()), true
+	case JsonTypeArray:
+		index, ok := arrayIndex(key, v.ArrayLen())
+		if !ok {
+			return nil, false
+		}
+		return v.ArrayGetElm(index), true
+	default:
+		return nil, false
+	}
+}
+
+// Set sets val at the path in v in O(depth) time.
+// If the parent of the path is an object, the key is added or replaced.
+// If the parent of the path is an array, the element at the index is replaced, or val is appended if the last key is `-` or equal to the length of the array.
+// If the path is empty, v is replaced with val.
+func Set(v JsonValue, path Path, val JsonValue) error {
+	if path.Len() == 0 {
+		v.Assign(val)
+		return nil
+	}
+
+	parent, ok := Find(v, path[:path.Len()-1])
+	if !ok {
+		return fmt.Errorf(`parent of %s not found`, path.Pointer())
+	}
+
+	key := path.Get(path.Len() - 1)
+	switch parent.Type() {
+	case JsonTypeObject:
+		parent.ObjectSetElm(key.String(), val)
+	case JsonTypeArray:
+		if key == "-" || key.String() == strconv.Itoa(parent.ArrayLen()) {
+			parent.ArrayAddElm(val)
+			return nil
+		}
+		index, ok := arrayIndex(key, parent.ArrayLen())
+		if !ok {
+			return fmt.Errorf(`invalid array index in %s`, path.Pointer())
+		}
+		parent.ArraySetElm(index, val)
+	default:
+		return fmt.Errorf(`parent of %s must be object or array but %v`, path.Pointer(), parent.Type())
+	}
+	return nil
+}
+
+// Delete deletes the value at the path in v in O(depth) time, where elements following the deleted element in an array are shifted.
+// The empty path cannot be deleted.
+func Delete(v JsonValue, path Path) error {
+	if path.Len() == 0 {
+		return fmt.Errorf(`whole document cannot be deleted`)
+	}
+
+	parent, ok := Find(v, path[:path.Len()-1])
+	if !ok {
+		return fmt.Errorf(`parent of %s not found`, path.Pointer())
+	}
+
+	key := path.Get(path.Len() - 1)
+	switch parent.Type() {
+	case JsonTypeObject:
+		if !parent.ObjectHasElm(key.String()) {
+			return fmt.Errorf(`%s not found`, path.Pointer())
+		}
+		parent.ObjectDelElm(key.String())
+	case JsonTypeArray:
+		index, ok := arrayIndex(key, parent.ArrayLen())
+		if !ok {
+			return fmt.Errorf(`%s not found`, path.Pointer())
+		}
+		deleted := parent.ArraySlice(0, index)
+		for i := index + 1; i < parent.ArrayLen(); i++ {
+			deleted.ArrayAddElm(parent.ArrayGetElm(i))
+		}
+		parent.Assign(deleted)
+	default:
+		return fmt.Errorf(`parent of %s must be object or array but %v`, path.Pointer(), parent.Type())
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package wrap_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/json/wrap"
+	"github.com/Jumpaku/gotaface/old/test/assert"
+)
+
+func TestParsePointer(t *testing.T) {
+	t.Run(`root`, func(t *testing.T) {
+		p, err := wrap.ParsePointer(``)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, p.Len(), 0)
+	})
+	t.Run(`keys`, func(t *testing.T) {
+		p, err := wrap.ParsePointer(`/users/0/name`)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, p.Equals(wrap.Path{"users", "0", "name"}), true)
+	})
+	t.Run(`escape`, func(t *testing.T) {
+		p, err := wrap.ParsePointer(`/a~1b/m~0n/~01/`)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, p.Equals(wrap.Path{"a/b", "m~n", "~1", ""}), true)
+	})
+	t.Run(`no leading slash`, func(t *testing.T) {
+		_, err := wrap.ParsePointer(`users`)
+		assert.NotEqual(t, err, nil)
+	})
+	t.Run(`invalid escape`, func(t *testing.T) {
+		_, err := wrap.ParsePointer(`/a~2`)
+		assert.NotEqual(t, err, nil)
+	})
+}
+
+func TestPath_Pointer(t *testing.T) {
+	assert.Equal(t, wrap.Path{}.Pointer(), ``)
+	assert.Equal(t, wrap.Path{"a/b", "m~n", "~1", ""}.Pointer(), `/a~1b/m~0n/~01/`)
+}
+
+func newPointerExample(t *testing.T) wrap.JsonValue {
+	t.Helper()
+
+	v := wrap.Null()
+	err := v.UnmarshalJSON([]byte(`{"users":[{"id":1,"name":"a"},{"id":2,"name":"b"}],"a/b":{"m~n":true}}`))
+	assert.Equal(t, err, nil)
+	return v
+}
+
+func marshal(t *testing.T, v wrap.JsonValue) string {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	assert.Equal(t, err, nil)
+	return string(b)
+}
+
+func TestFind_Pointer(t *testing.T) {
+	v := newPointerExample(t)
+	t.Run(`found`, func(t *testing.T) {
+		p, _ := wrap.ParsePointer(`/users/1/name`)
+		a, ok := wrap.Find(v, p)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, a.StringGet(), "b")
+	})
+	t.Run(`escaped`, func(t *testing.T) {
+		p, _ := wrap.ParsePointer(`/a~1b/m~0n`)
+		a, ok := wrap.Find(v, p)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, a.BooleanGet(), true)
+	})
+	t.Run(`out of range`, func(t *testing.T) {
+		_, ok := wrap.Find(v, wrap.Path{"users", "2"})
+		assert.Equal(t, ok, false)
+	})
+	t.Run(`leading zero`, func(t *testing.T) {
+		_, ok := wrap.Find(v, wrap.Path{"users", "01"})
+		assert.Equal(t, ok, false)
+	})
+}
+
+func TestSet(t *testing.T) {
+	t.Run(`object`, func(t *testing.T) {
+		v := newPointerExample(t)
+		err := wrap.Set(v, wrap.Path{"users", "0", "name"}, wrap.String("x"))
+		assert.Equal(t, err, nil)
+		err = wrap.Set(v, wrap.Path{"users", "0", "age"}, wrap.Number(20))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, marshal(t, v), `{"users":[{"id":1,"name":"x","age":20},{"id":2,"name":"b"}],"a/b":{"m~n":true}}`)
+	})
+	t.Run(`array`, func(t *testing.T) {
+		v := newPointerExample(t)
+		err := wrap.Set(v, wrap.Path{"users", "1"}, wrap.Null())
+		assert.Equal(t, err, nil)
+		err = wrap.Set(v, wrap.Path{"users", "-"}, wrap.Number(3))
+		assert.Equal(t, err, nil)
+		err = wrap.Set(v, wrap.Path{"users", "3"}, wrap.Number(4))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, marshal(t, v), `{"users":[{"id":1,"name":"a"},null,3,4],"a/b":{"m~n":true}}`)
+	})
+	t.Run(`root`, func(t *testing.T) {
+		v := newPointerExample(t)
+		err := wrap.Set(v, wrap.Path{}, wrap.Number(1))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, marshal(t, v), `1`)
+	})
+	t.Run(`parent not found`, func(t *testing.T) {
+		v := newPointerExample(t)
+		err := wrap.Set(v, wrap.Path{"x", "y"}, wrap.Null())
+		assert.NotEqual(t, err, nil)
+	})
+	t.Run(`invalid index`, func(t *testing.T) {
+		v := newPointerExample(t)
+		err := wrap.Set(v, wrap.Path{"users", "5"}, wrap.Null())
+		assert.NotEqual(t, err, nil)
+	})
+}
+
+func TestDelete(t *testing.T) {
+	t.Run(`object`, func(t *testing.T) {
+		v := newPointerExample(t)
+		err := wrap.Delete(v, wrap.Path{"a/b"})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, marshal(t, v), `{"users":[{"id":1,"name":"a"},{"id":2,"name":"b"}]}`)
+	})
+	t.Run(`array`, func(t *testing.T) {
+		v := newPointerExample(t)
+		err := wrap.Delete(v, wrap.Path{"users", "0"})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, marshal(t, v), `{"users":[{"id":2,"name":"b"}],"a/b":{"m~n":true}}`)
+	})
+	t.Run(`not found`, func(t *testing.T) {
+		v := newPointerExample(t)
+		err := wrap.Delete(v, wrap.Path{"users", "2"})
+		assert.NotEqual(t, err, nil)
+	})
+	t.Run(`root`, func(t *testing.T) {
+		v := newPointerExample(t)
+		err := wrap.Delete(v, wrap.Path{})
+		assert.NotEqual(t, err, nil)
+	})
+}
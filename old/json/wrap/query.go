@@ -0,0 +1,696 @@
+package wrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Match is a value selected by a query with its path from the root.
+type Match struct {
+	Path  Path
+	Value JsonValue
+}
+
+// Query is a parsed JSONPath query.
+type Query struct {
+	segments []segment
+}
+
+// ParseQuery parses a query in a subset of JSONPath, which supports the following syntax:
+//
+//   - `$` selects the root value.
+//   - `.name` and `['name']` select the member of an object.
+//   - `.*` and `[*]` select all the elements of an array or the members of an object.
+//   - `[0]` and `[-1]` select the element of an array at the index, where negative indices count from the end.
+//   - `[start:end:step]` selects the elements of an array in the slice, where each of start, end, and step can be omitted.
+//   - `[a,b]` selects the union of the selectors, e.g., `['id','name']` or `[0,2]`.
+//   - `..` followed by a selector, e.g., `..name` or `..[0]`, applies the selector to the value and all its descendants.
+//   - `[?(expr)]` selects the elements or members for which expr is true, where `@` in expr refers to each of them.
+//     expr can consist of comparisons `==`, `!=`, `<`, `<=`, `>`, `>=` between relative paths like `@.id`, absolute paths like `$.id`, and literals of numbers, strings, true, false, and null,
+//     existence tests of paths like `@.name`, logical operators `&&`, `||`, `!`, and parentheses.
+//
+// For example, `$.users[?(@.id > 3)].name` selects the names of the users whose ids are greater than 3.
+func ParseQuery(query string) (Query, error) {
+	p := &queryParser{runes: []rune(query)}
+	q, err := p.parseQuery('$')
+	if err != nil {
+		return Query{}, fmt.Errorf(`fail to parse query %q: %w`, query, err)
+	}
+	if !p.end() {
+		return Query{}, fmt.Errorf(`fail to parse query %q: unexpected %q at %d`, query, p.peek(), p.pos)
+	}
+	return q, nil
+}
+
+// Select returns the values selected by the query in v in document order.
+func (q Query) Select(v JsonValue) []Match {
+	return q.selectFrom(Match{Path: Path{}, Value: v}, v)
+}
+
+func (q Query) selectFrom(current Match, root JsonValue) []Match {
+	matches := []Match{current}
+	for _, s := range q.segments {
+		next := []Match{}
+		for _, m := range matches {
+			nodes := []Match{m}
+			if s.descendant {
+				nodes = descendants(m, []Match{})
+			}
+			for _, node := range nodes {
+				for _, sel := range s.selectors {
+					next = sel.selectChildren(node, root, next)
+				}
+			}
+		}
+		matches = next
+	}
+	return matches
+}
+
+// Select parses the query and returns the values selected by it in v.
+func Select(v JsonValue, query string) ([]Match, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return q.Select(v), nil
+}
+
+type segment struct {
+	descendant bool
+	selectors  []selector
+}
+
+type selector interface {
+	selectChildren(m Match, root JsonValue, out []Match) []Match
+}
+
+// children returns the elements of an array or the members of an object.
+func children(m Match) []Match {
+	out := []Match{}
+	switch m.Value.Type() {
+	case JsonTypeArray:
+		for i := 0; i < m.Value.ArrayLen(); i++ {
+			out = append(out, Match{Path: m.Path.Append(Key(strconv.Itoa(i))), Value: m.Value.ArrayGetElm(i)})
+		}
+	case JsonTypeObject:
+		for _, key := range m.Value.ObjectKeys() {
+			out = append(out, Match{Path: m.Path.Append(Key(key)), Value: m.Value.ObjectGetElm(key)})
+		}
+	}
+	return out
+}
+
+func descendants(m Match, out []Match) []Match {
+	out = append(out, m)
+	for _, c := range children(m) {
+		out = descendants(c, out)
+	}
+	return out
+}
+
+type nameSelector struct{ name string }
+
+func (s nameSelector) selectChildren(m Match, _ JsonValue, out []Match) []Match {
+	if m.Value.Type() == JsonTypeObject && m.Value.ObjectHasElm(s.name) {
+		out = append(out, Match{Path: m.Path.Append(Key(s.name)), Value: m.Value.ObjectGetElm(s.name)})
+	}
+	return out
+}
+
+type wildcardSelector struct{}
+
+func (s wildcardSelector) selectChildren(m Match, _ JsonValue, out []Match) []Match {
+	return append(out, children(m)...)
+}
+
+type indexSelector struct{ index int }
+
+func (s indexSelector) selectChildren(m Match, _ JsonValue, out []Match) []Match {
+	if m.Value.Type() != JsonTypeArray {
+		return out
+	}
+	index := s.index
+	if index < 0 {
+		index += m.Value.ArrayLen()
+	}
+	if index < 0 || index >= m.Value.ArrayLen() {
+		return out
+	}
+	return append(out, Match{Path: m.Path.Append(Key(strconv.Itoa(index))), Value: m.Value.ArrayGetElm(index)})
+}
+
+type sliceSelector struct {
+	start *int
+	end   *int
+	step  int
+}
+
+func (s sliceSelector) selectChildren(m Match, _ JsonValue, out []Match) []Match {
+	if m.Value.Type() != JsonTypeArray || s.step == 0 {
+		return out
+	}
+
+	n := m.Value.ArrayLen()
+	normalize := func(i *int, defaultValue int) int {
+		if i == nil {
+			return defaultValue
+		}
+		if *i < 0 {
+			return *i + n
+		}
+		return *i
+	}
+	clamp := func(i int, lower int, upper int) int {
+		if i < lower {
+			return lower
+		}
+		if i > upper {
+			return upper
+		}
+		return i
+	}
+
+	add := func(i int) {
+		out = append(out, Match{Path: m.Path.Append(Key(strconv.Itoa(i))), Value: m.Value.ArrayGetElm(i)})
+	}
+	if s.step > 0 {
+		start := clamp(normalize(s.start, 0), 0, n)
+		end := clamp(normalize(s.end, n), 0, n)
+		for i := start; i < end; i += s.step {
+			add(i)
+		}
+	} else {
+		start := clamp(normalize(s.start, n-1), -1, n-1)
+		end := clamp(normalize(s.end, -n-1), -1, n-1)
+		for i := start; i > end; i += s.step {
+			add(i)
+		}
+	}
+	return out
+}
+
+type filterSelector struct{ expr filterExpr }
+
+func (s filterSelector) selectChildren(m Match, root JsonValue, out []Match) []Match {
+	for _, c := range children(m) {
+		if s.expr.test(c, root) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+type filterExpr interface {
+	test(current Match, root JsonValue) bool
+}
+
+type orExpr struct{ left, right filterExpr }
+
+func (e orExpr) test(current Match, root JsonValue) bool {
+	return e.left.test(current, root) || e.right.test(current, root)
+}
+
+type andExpr struct{ left, right filterExpr }
+
+func (e andExpr) test(current Match, root JsonValue) bool {
+	return e.left.test(current, root) && e.right.test(current, root)
+}
+
+type notExpr struct{ expr filterExpr }
+
+func (e notExpr) test(current Match, root JsonValue) bool {
+	return !e.expr.test(current, root)
+}
+
+type existenceExpr struct{ operand pathOperand }
+
+func (e existenceExpr) test(current Match, root JsonValue) bool {
+	return len(e.operand.selectFrom(current, root)) > 0
+}
+
+type comparisonExpr struct {
+	op          string
+	left, right operand
+}
+
+func (e comparisonExpr) test(current Match, root JsonValue) bool {
+	l, okL := e.left.value(current, root)
+	r, okR := e.right.value(current, root)
+	switch e.op {
+	case `==`:
+		return (!okL && !okR) || (okL && okR && Equal(l, r))
+	case `!=`:
+		return !((!okL && !okR) || (okL && okR && Equal(l, r)))
+	}
+	if !okL || !okR {
+		return false
+	}
+
+	var c int
+	switch {
+	case l.Type() == JsonTypeNumber && r.Type() == JsonTypeNumber:
+		x, okX := new(big.Rat).SetString(l.NumberGet().String())
+		y, okY := new(big.Rat).SetString(r.NumberGet().String())
+		if !okX || !okY {
+			return false
+		}
+		c = x.Cmp(y)
+	case l.Type() == JsonTypeString && r.Type() == JsonTypeString:
+		c = strings.Compare(l.StringGet(), r.StringGet())
+	default:
+		return false
+	}
+	switch e.op {
+	case `<`:
+		return c < 0
+	case `<=`:
+		return c <= 0
+	case `>`:
+		return c > 0
+	case `>=`:
+		return c >= 0
+	default:
+		return false
+	}
+}
+
+type operand interface {
+	// value returns the value of the operand, or false if a path selects no values or multiple values.
+	value(current Match, root JsonValue) (JsonValue, bool)
+}
+
+type literalOperand struct{ literal JsonValue }
+
+func (o literalOperand) value(Match, JsonValue) (JsonValue, bool) {
+	return o.literal, true
+}
+
+type pathOperand struct {
+	relative bool
+	query    Query
+}
+
+func (o pathOperand) selectFrom(current Match, root JsonValue) []Match {
+	if o.relative {
+		return o.query.selectFrom(current, root)
+	}
+	return o.query.selectFrom(Match{Path: Path{}, Value: root}, root)
+}
+
+func (o pathOperand) value(current Match, root JsonValue) (JsonValue, bool) {
+	matches := o.selectFrom(current, root)
+	if len(matches) != 1 {
+		return nil, false
+	}
+	return matches[0].Value, true
+}
+
+type queryParser struct {
+	runes []rune
+	pos   int
+}
+
+func (p *queryParser) end() bool {
+	return p.pos >= len(p.runes)
+}
+
+func (p *queryParser) peek() rune {
+	if p.end() {
+		return 0
+	}
+	return p.runes[p.pos]
+}
+
+func (p *queryParser) hasPrefix(s string) bool {
+	return strings.HasPrefix(string(p.runes[p.pos:]), s)
+}
+
+func (p *queryParser) skipSpaces() {
+	for !p.end() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *queryParser) expect(r rune) error {
+	if p.peek() != r {
+		if p.end() {
+			return fmt.Errorf(`expected %q but reached end`, r)
+		}
+		return fmt.Errorf(`expected %q but %q at %d`, r, p.peek(), p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *queryParser) parseQuery(root rune) (Query, error) {
+	if err := p.expect(root); err != nil {
+		return Query{}, err
+	}
+
+	q := Query{}
+	for {
+		switch {
+		case p.hasPrefix(`..`):
+			p.pos += 2
+			var s segment
+			var err error
+			if p.peek() == '[' {
+				s, err = p.parseBracket()
+			} else {
+				s, err = p.parseDotted()
+			}
+			if err != nil {
+				return Query{}, err
+			}
+			s.descendant = true
+			q.segments = append(q.segments, s)
+		case p.peek() == '.':
+			p.pos++
+			s, err := p.parseDotted()
+			if err != nil {
+				return Query{}, err
+			}
+			q.segments = append(q.segments, s)
+		case p.peek() == '[':
+			s, err := p.parseBracket()
+			if err != nil {
+				return Query{}, err
+			}
+			q.segments = append(q.segments, s)
+		default:
+			return q, nil
+		}
+	}
+}
+
+func isNameRune(r rune, first bool) bool {
+	return r == '_' || unicode.IsLetter(r) || r > unicode.MaxASCII || (!first && (r == '-' || unicode.IsDigit(r)))
+}
+
+func (p *queryParser) parseDotted() (segment, error) {
+	if p.peek() == '*' {
+		p.pos++
+		return segment{selectors: []selector{wildcardSelector{}}}, nil
+	}
+
+	begin := p.pos
+	for !p.end() && isNameRune(p.peek(), p.pos == begin) {
+		p.pos++
+	}
+	if p.pos == begin {
+		if p.end() {
+			return segment{}, fmt.Errorf(`expected member name but reached end`)
+		}
+		return segment{}, fmt.Errorf(`expected member name but %q at %d`, p.peek(), p.pos)
+	}
+	return segment{selectors: []selector{nameSelector{name: string(p.runes[begin:p.pos])}}}, nil
+}
+
+func (p *queryParser) parseBracket() (segment, error) {
+	if err := p.expect('['); err != nil {
+		return segment{}, err
+	}
+
+	s := segment{}
+	for {
+		p.skipSpaces()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return segment{}, err
+		}
+		s.selectors = append(s.selectors, sel)
+
+		p.skipSpaces()
+		if p.peek() == ',' {
+			p.pos++
+			continue
+		}
+		if err := p.expect(']'); err != nil {
+			return segment{}, err
+		}
+		return s, nil
+	}
+}
+
+func (p *queryParser) parseSelector() (selector, error) {
+	switch r := p.peek(); {
+	case r == '*':
+		p.pos++
+		return wildcardSelector{}, nil
+	case r == '\'' || r == '"':
+		name, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return nameSelector{name: name}, nil
+	case r == '?':
+		p.pos++
+		p.skipSpaces()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return filterSelector{expr: expr}, nil
+	default:
+		return p.parseIndexOrSlice()
+	}
+}
+
+func (p *queryParser) parseIndexOrSlice() (selector, error) {
+	var bounds [3]*int
+	colons := 0
+	for {
+		p.skipSpaces()
+		if p.peek() == '-' || unicode.IsDigit(p.peek()) {
+			n, err := p.parseInt()
+			if err != nil {
+				return nil, err
+			}
+			bounds[colons] = &n
+			p.skipSpaces()
+		}
+		if p.peek() != ':' || colons == 2 {
+			break
+		}
+		p.pos++
+		colons++
+	}
+
+	if colons == 0 {
+		if bounds[0] == nil {
+			if p.end() {
+				return nil, fmt.Errorf(`expected selector but reached end`)
+			}
+			return nil, fmt.Errorf(`expected selector but %q at %d`, p.peek(), p.pos)
+		}
+		return indexSelector{index: *bounds[0]}, nil
+	}
+
+	step := 1
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	return sliceSelector{start: bounds[0], end: bounds[1], step: step}, nil
+}
+
+func (p *queryParser) parseInt() (int, error) {
+	begin := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for !p.end() && unicode.IsDigit(p.peek()) {
+		p.pos++
+	}
+	n, err := strconv.Atoi(string(p.runes[begin:p.pos]))
+	if err != nil {
+		return 0, fmt.Errorf(`invalid integer at %d: %w`, begin, err)
+	}
+	return n, nil
+}
+
+func (p *queryParser) parseString() (string, error) {
+	quote := p.peek()
+	begin := p.pos
+	p.pos++
+
+	var b strings.Builder
+	for {
+		if p.end() {
+			return "", fmt.Errorf(`unterminated string at %d`, begin)
+		}
+		r := p.peek()
+		p.pos++
+		switch r {
+		case quote:
+			return b.String(), nil
+		case '\\':
+			if p.end() {
+				return "", fmt.Errorf(`unterminated string at %d`, begin)
+			}
+			e := p.peek()
+			p.pos++
+			switch e {
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			case 'r':
+				b.WriteRune('\r')
+			case 'b':
+				b.WriteRune('\b')
+			case 'f':
+				b.WriteRune('\f')
+			case 'u':
+				if p.pos+4 > len(p.runes) {
+					return "", fmt.Errorf(`invalid escape at %d`, p.pos-2)
+				}
+				code, err := strconv.ParseUint(string(p.runes[p.pos:p.pos+4]), 16, 32)
+				if err != nil {
+					return "", fmt.Errorf(`invalid escape at %d: %w`, p.pos-2, err)
+				}
+				p.pos += 4
+				b.WriteRune(rune(code))
+			default:
+				b.WriteRune(e)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+func (p *queryParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !p.hasPrefix(`||`) {
+			return left, nil
+		}
+		p.pos += 2
+		p.skipSpaces()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left: left, right: right}
+	}
+}
+
+func (p *queryParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !p.hasPrefix(`&&`) {
+			return left, nil
+		}
+		p.pos += 2
+		p.skipSpaces()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left: left, right: right}
+	}
+}
+
+func (p *queryParser) parseUnary() (filterExpr, error) {
+	p.skipSpaces()
+	if p.peek() == '!' && !p.hasPrefix(`!=`) {
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr: expr}, nil
+	}
+	if p.peek() == '(' {
+		p.pos++
+		p.skipSpaces()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	for _, op := range []string{`==`, `!=`, `<=`, `>=`, `<`, `>`} {
+		if p.hasPrefix(op) {
+			p.pos += len(op)
+			p.skipSpaces()
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return comparisonExpr{op: op, left: left, right: right}, nil
+		}
+	}
+
+	path, ok := left.(pathOperand)
+	if !ok {
+		return nil, fmt.Errorf(`literal must be compared at %d`, p.pos)
+	}
+	return existenceExpr{operand: path}, nil
+}
+
+func (p *queryParser) parseOperand() (operand, error) {
+	switch r := p.peek(); {
+	case r == '@' || r == '$':
+		q, err := p.parseQuery(r)
+		if err != nil {
+			return nil, err
+		}
+		return pathOperand{relative: r == '@', query: q}, nil
+	case r == '\'' || r == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return literalOperand{literal: String(s)}, nil
+	case r == '-' || unicode.IsDigit(r):
+		begin := p.pos
+		p.pos++
+		for !p.end() && strings.ContainsRune(`0123456789.eE+-`, p.peek()) {
+			p.pos++
+		}
+		s := string(p.runes[begin:p.pos])
+		if _, ok := new(big.Rat).SetString(s); !ok {
+			return nil, fmt.Errorf(`invalid number %q at %d`, s, begin)
+		}
+		return literalOperand{literal: Number(json.Number(s))}, nil
+	case p.hasPrefix(`true`):
+		p.pos += 4
+		return literalOperand{literal: Boolean(true)}, nil
+	case p.hasPrefix(`false`):
+		p.pos += 5
+		return literalOperand{literal: Boolean(false)}, nil
+	case p.hasPrefix(`null`):
+		p.pos += 4
+		return literalOperand{literal: Null()}, nil
+	default:
+		if p.end() {
+			return nil, fmt.Errorf(`expected operand but reached end`)
+		}
+		return nil, fmt.Errorf(`expected operand but %q at %d`, r, p.pos)
+	}
+}
@@ -0,0 +1,168 @@
+package wrap_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/json/wrap"
+	"github.com/Jumpaku/gotaface/old/test/assert"
+)
+
+const queryExample = `{
+	"store": {
+		"book": [
+			{"id": 1, "category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"id": 2, "category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"id": 3, "category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+			{"id": 4, "category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+		],
+		"bicycle": {"color": "red", "price": 19.95}
+	},
+	"limit": 10
+}`
+
+func TestSelect(t *testing.T) {
+	testcases := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  `root`,
+			query: `$`,
+			want:  []string{``},
+		},
+		{
+			name:  `member`,
+			query: `$.store.bicycle.color`,
+			want:  []string{`/store/bicycle/color`},
+		},
+		{
+			name:  `bracket member`,
+			query: `$['store']["bicycle"]['color','price']`,
+			want:  []string{`/store/bicycle/color`, `/store/bicycle/price`},
+		},
+		{
+			name:  `missing member`,
+			query: `$.store.car`,
+			want:  []string{},
+		},
+		{
+			name:  `wildcard`,
+			query: `$.store.*`,
+			want:  []string{`/store/book`, `/store/bicycle`},
+		},
+		{
+			name:  `wildcard array`,
+			query: `$.store.book[*].id`,
+			want:  []string{`/store/book/0/id`, `/store/book/1/id`, `/store/book/2/id`, `/store/book/3/id`},
+		},
+		{
+			name:  `index`,
+			query: `$.store.book[1,-1,4].id`,
+			want:  []string{`/store/book/1/id`, `/store/book/3/id`},
+		},
+		{
+			name:  `slice`,
+			query: `$.store.book[1:3]`,
+			want:  []string{`/store/book/1`, `/store/book/2`},
+		},
+		{
+			name:  `slice with step`,
+			query: `$.store.book[::2]`,
+			want:  []string{`/store/book/0`, `/store/book/2`},
+		},
+		{
+			name:  `slice with negative step`,
+			query: `$.store.book[:-3:-1]`,
+			want:  []string{`/store/book/3`, `/store/book/2`},
+		},
+		{
+			name:  `recursive descent`,
+			query: `$..price`,
+			want:  []string{`/store/book/0/price`, `/store/book/1/price`, `/store/book/2/price`, `/store/book/3/price`, `/store/bicycle/price`},
+		},
+		{
+			name:  `recursive descent with bracket`,
+			query: `$..book[0].id`,
+			want:  []string{`/store/book/0/id`},
+		},
+		{
+			name:  `filter comparison`,
+			query: `$.store.book[?(@.id > 3)].title`,
+			want:  []string{`/store/book/3/title`},
+		},
+		{
+			name:  `filter string`,
+			query: `$.store.book[?(@.category == 'reference')].id`,
+			want:  []string{`/store/book/0/id`},
+		},
+		{
+			name:  `filter existence`,
+			query: `$.store.book[?(@.isbn)].id`,
+			want:  []string{`/store/book/2/id`, `/store/book/3/id`},
+		},
+		{
+			name:  `filter logical`,
+			query: `$.store.book[?(!@.isbn && @.price < 10 || @.id == 4)].id`,
+			want:  []string{`/store/book/0/id`, `/store/book/3/id`},
+		},
+		{
+			name:  `filter absolute path`,
+			query: `$..[?(@.price < $.limit)].id`,
+			want:  []string{`/store/book/0/id`, `/store/book/2/id`},
+		},
+		{
+			name:  `filter without parentheses`,
+			query: `$.store.book[?@.price >= 12.99 && @.price <= 2.299e1].id`,
+			want:  []string{`/store/book/1/id`, `/store/book/3/id`},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := parse(t, queryExample)
+			matches, err := wrap.Select(v, tc.query)
+			assert.Equal(t, err, nil)
+
+			got := []string{}
+			for _, m := range matches {
+				got = append(got, m.Path.Pointer())
+				found, ok := wrap.Find(v, m.Path)
+				assert.Equal(t, ok, true)
+				assert.Equal(t, marshal(t, m.Value), marshal(t, found))
+			}
+			assert.Equal(t, strings.Join(got, ","), strings.Join(tc.want, ","))
+		})
+	}
+}
+
+func TestSelect_Value(t *testing.T) {
+	v := parse(t, queryExample)
+	matches, err := wrap.Select(v, `$.store.book[?(@.author == "Herman Melville")]['title','price']`)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(matches), 2)
+	assert.Equal(t, marshal(t, matches[0].Value), `"Moby Dick"`)
+	assert.Equal(t, marshal(t, matches[1].Value), `8.99`)
+}
+
+func TestParseQuery_Error(t *testing.T) {
+	testcases := []string{
+		``,
+		`store`,
+		`$.`,
+		`$[`,
+		`$['a'`,
+		`$['a]`,
+		`$[a]`,
+		`$[?(@.a > )]`,
+		`$[?(@.a == 1]`,
+		`$[?(1)]`,
+		`$.a b`,
+	}
+	for _, query := range testcases {
+		t.Run(query, func(t *testing.T) {
+			_, err := wrap.ParseQuery(query)
+			assert.Equal(t, err != nil, true)
+		})
+	}
+}
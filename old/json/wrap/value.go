@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
 	"strconv"
 
 	"github.com/Jumpaku/gotaface/old/errors"
@@ -66,8 +68,10 @@ type jsonValue struct {
 	jsonNumber  json.Number
 	jsonBoolean bool
 	jsonString  string
-	jsonObject  map[string]JsonValue
-	jsonArray   []JsonValue
+	// jsonKeys holds the keys of jsonObject in insertion order.
+	jsonKeys   []string
+	jsonObject map[string]JsonValue
+	jsonArray  []JsonValue
 }
 
 func Null() JsonValue {
@@ -114,16 +118,22 @@ func Number[V ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16
 	return &jsonValue{jsonType: JsonTypeNumber, jsonNumber: json.Number(v)}
 }
 
+// Object returns a JSON object with the members of ms, which are inserted in the order of ms and in the sorted order of keys in each map.
 func Object(ms ...map[string]JsonValue) JsonValue {
-	o := map[string]JsonValue{}
+	o := &jsonValue{jsonType: JsonTypeObject, jsonKeys: []string{}, jsonObject: map[string]JsonValue{}}
 	for _, m := range ms {
-		for k, v := range m {
-			errors.Assert(v != nil, "JsonValue must not be nil")
-			o[k] = v
+		keys := []string{}
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			errors.Assert(m[k] != nil, "JsonValue must not be nil")
+			o.ObjectSetElm(k, m[k])
 		}
 	}
 
-	return &jsonValue{jsonType: JsonTypeObject, jsonObject: o}
+	return o
 }
 func Array(vs ...JsonValue) JsonValue {
 	a := make([]JsonValue, len(vs))
@@ -148,48 +158,129 @@ func (v *jsonValue) MarshalJSON() ([]byte, error) {
 	case JsonTypeArray:
 		return json.Marshal(v.jsonArray)
 	case JsonTypeObject:
-		return json.Marshal(v.jsonObject)
+		return marshalObject(v.jsonKeys, func(key string) json.Marshaler { return v.jsonObject[key] })
 	default:
 		return errors.Unexpected2[[]byte, error](`invalid JsonType: %v`, v.Type())
 	}
 }
 
-func fromGo(a any) JsonValue {
-	switch a := a.(type) {
+func marshalObject(keys []string, elm func(key string) json.Marshaler) ([]byte, error) {
+	buf := bytes.NewBufferString(`{`)
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteString(`,`)
+		}
+		b, err := json.Marshal(key)
+		if err != nil {
+			return nil, fmt.Errorf(`fail to marshal key %q: %w`, key, err)
+		}
+		buf.Write(b)
+		buf.WriteString(`:`)
+		b, err = json.Marshal(elm(key))
+		if err != nil {
+			return nil, fmt.Errorf(`fail to marshal value of key %q: %w`, key, err)
+		}
+		buf.Write(b)
+	}
+	buf.WriteString(`}`)
+
+	return buf.Bytes(), nil
+}
+
+// SortKeys returns a json.Marshaler which marshals v with the keys of all the objects in v sorted.
+func SortKeys(v JsonValue) json.Marshaler {
+	return sortedKeys{v: v}
+}
+
+type sortedKeys struct {
+	v JsonValue
+}
+
+func (s sortedKeys) MarshalJSON() ([]byte, error) {
+	switch s.v.Type() {
+	case JsonTypeArray:
+		elms := []json.Marshaler{}
+		for i := 0; i < s.v.ArrayLen(); i++ {
+			elms = append(elms, sortedKeys{v: s.v.ArrayGetElm(i)})
+		}
+		return json.Marshal(elms)
+	case JsonTypeObject:
+		keys := s.v.ObjectKeys()
+		sort.Strings(keys)
+		return marshalObject(keys, func(key string) json.Marshaler { return sortedKeys{v: s.v.ObjectGetElm(key)} })
+	default:
+		return s.v.MarshalJSON()
+	}
+}
+
+// decode reads a JSON value from decoder preserving the order of object keys.
+func decode(decoder *json.Decoder) (JsonValue, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	// io.EOF after the first token means that the value is incomplete.
+	fail := func(err error) (JsonValue, error) {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	switch token := token.(type) {
 	case nil:
-		return Null()
+		return Null(), nil
 	case json.Number:
-		return Number(a)
+		return Number(token), nil
 	case string:
-		return String(a)
+		return String(token), nil
 	case bool:
-		return Boolean(a)
-	case []any:
-		arr := Array()
-		for _, a := range a {
-			arr.ArrayAddElm(fromGo(a))
+		return Boolean(token), nil
+	case json.Delim:
+		switch token {
+		case '[':
+			arr := Array()
+			for decoder.More() {
+				elm, err := decode(decoder)
+				if err != nil {
+					return fail(err)
+				}
+				arr.ArrayAddElm(elm)
+			}
+			if _, err := decoder.Token(); err != nil {
+				return fail(err)
+			}
+			return arr, nil
+		case '{':
+			obj := Object()
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return fail(err)
+				}
+				elm, err := decode(decoder)
+				if err != nil {
+					return fail(err)
+				}
+				obj.ObjectSetElm(key.(string), elm)
+			}
+			if _, err := decoder.Token(); err != nil {
+				return fail(err)
+			}
+			return obj, nil
 		}
-		return arr
-	case map[string]any:
-		obj := Object()
-		for k, a := range a {
-			obj.ObjectSetElm(k, fromGo(a))
-		}
-		return obj
-	default:
-		return errors.Unexpected1[JsonValue]("unexpected value that cannot be converted to JsonValue: %#v", a)
 	}
+	return errors.Unexpected2[JsonValue, error]("unexpected token that cannot be converted to JsonValue: %#v", token)
 }
 func (v *jsonValue) UnmarshalJSON(b []byte) error {
 	decoder := json.NewDecoder(bytes.NewBuffer(b))
 	decoder.UseNumber()
 
-	var a any
-	if err := decoder.Decode(&a); err != nil {
+	a, err := decode(decoder)
+	if err != nil {
 		return fmt.Errorf(`fail to unmarshal value to JsonValue: %w`, err)
 	}
 
-	v.Assign(fromGo(a))
+	v.Assign(a)
 
 	return nil
 }
@@ -207,9 +298,9 @@ func (v *jsonValue) Assign(other JsonValue) {
 			v.jsonArray[i] = other.ArrayGetElm(i)
 		}
 	case JsonTypeObject:
+		v.jsonKeys = other.ObjectKeys()
 		v.jsonObject = map[string]JsonValue{}
-		keys := other.ObjectKeys()
-		for _, k := range keys {
+		for _, k := range v.jsonKeys {
 			v.jsonObject[k] = other.ObjectGetElm(k)
 		}
 	case JsonTypeBoolean:
@@ -265,15 +356,12 @@ func (v *jsonValue) BooleanGet() bool {
 
 	return v.jsonBoolean
 }
+
+// ObjectKeys returns the keys of the object in insertion order.
 func (v *jsonValue) ObjectKeys() []string {
 	errors.Assert(v.Type() == JsonTypeObject, "JsonValue must be JSON object")
 
-	keys := []string{}
-	for key := range v.jsonObject {
-		keys = append(keys, key)
-	}
-
-	return keys
+	return append([]string{}, v.jsonKeys...)
 }
 func (v *jsonValue) ObjectHasElm(key string) bool {
 	errors.Assert(v.Type() == JsonTypeObject, "JsonValue must be JSON object")
@@ -292,12 +380,24 @@ func (v *jsonValue) ObjectSetElm(key string, val JsonValue) {
 	errors.Assert(v.Type() == JsonTypeObject, "JsonValue must be JSON object")
 	errors.Assert(val != nil, "JsonValue must be not nil")
 
+	if _, ok := v.jsonObject[key]; !ok {
+		v.jsonKeys = append(v.jsonKeys, key)
+	}
 	v.jsonObject[key] = val
 }
 func (v *jsonValue) ObjectDelElm(key string) {
 	errors.Assert(v.Type() == JsonTypeObject, "JsonValue must be JSON object")
 
+	if _, ok := v.jsonObject[key]; !ok {
+		return
+	}
 	delete(v.jsonObject, key)
+	for i, k := range v.jsonKeys {
+		if k == key {
+			v.jsonKeys = append(v.jsonKeys[:i], v.jsonKeys[i+1:]...)
+			break
+		}
+	}
 }
 func (v *jsonValue) ObjectLen() int {
 	errors.Assert(v.Type() == JsonTypeObject, "JsonValue must be JSON object")
@@ -3,6 +3,7 @@ package wrap_test
 import (
 	"encoding/json"
 	"math"
+	"strings"
 	"testing"
 
 	"github.com/Jumpaku/gotaface/old/json/wrap"
@@ -212,6 +213,33 @@ func TestObjectKeys(t *testing.T) {
 	checkSliceContains(t, aKeys, "f")
 }
 
+func TestObjectKeys_Order(t *testing.T) {
+	o := wrap.Null()
+	err := o.UnmarshalJSON([]byte(`{"c":1,"a":2,"b":3}`))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, strings.Join(o.ObjectKeys(), ","), "c,a,b")
+
+	o.ObjectSetElm("a", wrap.Null())
+	o.ObjectSetElm("d", wrap.Null())
+	o.ObjectDelElm("c")
+	assert.Equal(t, strings.Join(o.ObjectKeys(), ","), "a,b,d")
+	assert.Equal(t, strings.Join(o.Clone().ObjectKeys(), ","), "a,b,d")
+}
+
+func TestMarshalJSON_Order(t *testing.T) {
+	v := wrap.Null()
+	err := v.UnmarshalJSON([]byte(`{"z":{"y":1,"x":[{"q":true,"p":null}]},"a":"b"}`))
+	assert.Equal(t, err, nil)
+
+	b, err := json.Marshal(v)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, string(b), `{"z":{"y":1,"x":[{"q":true,"p":null}]},"a":"b"}`)
+
+	b, err = json.Marshal(wrap.SortKeys(v))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, string(b), `{"a":"b","z":{"x":[{"p":null,"q":true}],"y":1}}`)
+}
+
 func TestObjectGetElm(t *testing.T) {
 	o := wrap.Object(map[string]wrap.JsonValue{
 		"a": wrap.Null(),
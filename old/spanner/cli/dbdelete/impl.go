@@ -3,8 +3,13 @@ package dbdelete
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/gotaface/old/ddl/schema"
+	"github.com/Jumpaku/gotaface/old/dml/delete"
+	gotaface_spanner "github.com/Jumpaku/gotaface/old/spanner"
+	spanner_schema "github.com/Jumpaku/gotaface/old/spanner/ddl/schema"
 	spanner_delete "github.com/Jumpaku/gotaface/old/spanner/dml/delete"
 )
 
@@ -27,3 +32,105 @@ func DBDeleteFunc(ctx context.Context, driver string, dataSource string, input D
 
 	return nil
 }
+
+// DBDeleteDryRunFunc writes the statements to delete the rows with the row counts per table to output instead of executing them.
+func DBDeleteDryRunFunc(ctx context.Context, driver string, dataSource string, input DBDeleteInput, output io.Writer) error {
+	client, err := spanner.NewClient(ctx, dataSource)
+	if err != nil {
+		return fmt.Errorf(`fail to create Spanner client %s: %w`, dataSource, err)
+	}
+	defer client.Close()
+
+	tx := client.ReadOnlyTransaction()
+	defer tx.Close()
+
+	deleter := dryRunDeleter{queryer: tx, deleter: spanner_delete.NewDeleter(gotaface_spanner.NewDryRunUpdater(tx, output)), output: output}
+	for _, target := range input {
+		if err := deleter.Delete(ctx, target); err != nil {
+			return fmt.Errorf(`fail to delete rows in table %s: %w`, target, err)
+		}
+	}
+
+	return nil
+}
+
+// DBDeleteDeferredFunc deletes the rows in the tables in the order of the references between the tables instead of the order of the input.
+// The references in cycles are deferred by setting the nullable foreign key columns to NULL before deleting the rows.
+func DBDeleteDeferredFunc(ctx context.Context, driver string, dataSource string, input DBDeleteInput) error {
+	client, err := spanner.NewClient(ctx, dataSource)
+	if err != nil {
+		return fmt.Errorf(`fail to create Spanner client %s: %w`, dataSource, err)
+	}
+	defer client.Close()
+
+	s, foreignKeys, err := fetchSchema(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	deleter := spanner_delete.NewDeleter(client)
+	return delete.DeleteDeferred(ctx, s, foreignKeys, deleter, deleter, input)
+}
+
+// DBDeleteDeferredDryRunFunc writes the statements of DBDeleteDeferredFunc with the row counts per table to output instead of executing them.
+func DBDeleteDeferredDryRunFunc(ctx context.Context, driver string, dataSource string, input DBDeleteInput, output io.Writer) error {
+	client, err := spanner.NewClient(ctx, dataSource)
+	if err != nil {
+		return fmt.Errorf(`fail to create Spanner client %s: %w`, dataSource, err)
+	}
+	defer client.Close()
+
+	s, foreignKeys, err := fetchSchema(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	tx := client.ReadOnlyTransaction()
+	defer tx.Close()
+
+	deleter := dryRunDeleter{queryer: tx, deleter: spanner_delete.NewDeleter(gotaface_spanner.NewDryRunUpdater(tx, output)), output: output}
+	return delete.DeleteDeferred(ctx, s, foreignKeys, deleter, deleter, input)
+}
+
+func fetchSchema(ctx context.Context, client *spanner.Client) (*spanner_schema.Schema, []schema.ForeignKey, error) {
+	tx := client.ReadOnlyTransaction()
+	defer tx.Close()
+
+	s, err := spanner_schema.FetchSchema(ctx, tx)
+	if err != nil {
+		return nil, nil, fmt.Errorf(`fail to fetch schema: %w`, err)
+	}
+	foreignKeys, err := spanner_schema.FetchForeignKeys(ctx, tx)
+	if err != nil {
+		return nil, nil, fmt.Errorf(`fail to fetch foreign keys: %w`, err)
+	}
+	return s, foreignKeys, nil
+}
+
+// dryRunDeleter writes the row count of a table to output before writing the statement to delete the rows.
+type dryRunDeleter struct {
+	queryer gotaface_spanner.Queryer
+	deleter interface {
+		delete.Deleter
+		delete.Clearer
+	}
+	output io.Writer
+}
+
+func (d dryRunDeleter) Delete(ctx context.Context, table string) error {
+	var count int64
+	err := d.queryer.Query(ctx, spanner.Statement{SQL: fmt.Sprintf(`SELECT COUNT(*) FROM %s`, table)}).Do(func(r *spanner.Row) error {
+		return r.Column(0, &count)
+	})
+	if err != nil {
+		return fmt.Errorf(`fail to count rows in table %s: %w`, table, err)
+	}
+	if _, err := fmt.Fprintf(d.output, "-- %s: %d rows\n", table, count); err != nil {
+		return fmt.Errorf(`fail to write row count: %w`, err)
+	}
+	return d.deleter.Delete(ctx, table)
+}
+
+func (d dryRunDeleter) Clear(ctx context.Context, table string, columns []string) error {
+	return d.deleter.Clear(ctx, table, columns)
+}
@@ -9,11 +9,15 @@ import (
 	"github.com/Jumpaku/gotaface/old/dml"
 	spanner_schema "github.com/Jumpaku/gotaface/old/spanner/ddl/schema"
 	spanner_dump "github.com/Jumpaku/gotaface/old/spanner/dml/dump"
+	"golang.org/x/sync/errgroup"
 )
 
 type DBDumpInput = []string
 type DBDumpOutput = map[string]dml.Rows
 
+// dumpParallelism is the maximum number of tables dumped concurrently.
+const dumpParallelism = 8
+
 func DBDumpFunc(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer, input DBDumpInput) (DBDumpOutput, error) {
 	client, err := spanner.NewClient(ctx, dataSource)
 	if err != nil {
@@ -21,24 +25,42 @@ func DBDumpFunc(ctx context.Context, driver string, dataSource string, schemaRea
 	}
 	defer client.Close()
 
-	rtx := client.ReadOnlyTransaction()
-	defer rtx.Close()
+	// all tables are dumped concurrently from the same snapshot at the timestamp of this transaction.
+	btx, err := client.BatchReadOnlyTransaction(ctx, spanner.StrongRead())
+	if err != nil {
+		return nil, fmt.Errorf(`fail to begin batch read-only transaction: %w`, err)
+	}
+	defer btx.Cleanup(ctx)
 
-	schema, err := spanner_schema.FetchSchemaOrUseCache(ctx, schemaReader, schemaWriter, rtx)
+	schema, err := spanner_schema.FetchSchemaOrUseCache(ctx, schemaReader, schemaWriter, btx)
 	if err != nil {
 		return nil, fmt.Errorf(`fail to fetch schema or use cache: %w`, err)
 	}
 
-	dumper := spanner_dump.NewDumper(rtx, schema)
+	dumper := spanner_dump.NewPartitionedDumper(btx, schema)
 
-	output := DBDumpOutput{}
-	for _, target := range input {
-		rows, err := dumper.Dump(ctx, target)
-		if err != nil {
-			return nil, fmt.Errorf(`fail to dump rows in table %s: %w`, target, err)
-		}
+	dumped := make([]dml.Rows, len(input))
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(dumpParallelism)
+	for i, target := range input {
+		i, target := i, target
+		eg.Go(func() error {
+			rows, err := dumper.Dump(egCtx, target)
+			if err != nil {
+				return fmt.Errorf(`fail to dump rows in table %s: %w`, target, err)
+			}
 
-		output[target] = rows
+			dumped[i] = rows
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	output := DBDumpOutput{}
+	for i, target := range input {
+		output[target] = dumped[i]
 	}
 
 	return output, nil
@@ -0,0 +1,57 @@
+package dbgenerate
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/gotaface/spanner/generate"
+	spanner_schema "github.com/Jumpaku/gotaface/spanner/schema"
+)
+
+type DBGenerateInput = []string
+type DBGenerateOutput = []generate.TableRows
+
+// DBGenerateFunc generates rows in the input tables and the tables referenced by them transitively.
+func DBGenerateFunc(ctx context.Context, driver string, dataSource string, rows int, seed int64, input DBGenerateInput) (DBGenerateOutput, error) {
+	client, err := spanner.NewClient(ctx, dataSource)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to create Spanner client %s: %w`, dataSource, err)
+	}
+	defer client.Close()
+
+	tx := client.ReadOnlyTransaction()
+	defer tx.Close()
+
+	fetcher := spanner_schema.NewFetcher(tx)
+	tables := []spanner_schema.SchemaTable{}
+	fetched := map[string]bool{}
+	for queue := input; len(queue) > 0; queue = queue[1:] {
+		name := queue[0]
+		if fetched[name] {
+			continue
+		}
+		fetched[name] = true
+
+		table, err := fetcher.Fetch(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf(`fail to fetch schema: %w`, err)
+		}
+		tables = append(tables, table)
+
+		if table.Parent != "" {
+			queue = append(queue, table.Parent)
+		}
+		for _, fk := range table.ForeignKeys {
+			queue = append(queue, fk.ReferencedTable)
+		}
+	}
+
+	output, err := generate.NewGenerator(rand.New(rand.NewSource(seed))).Generate(tables, rows)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to generate rows: %w`, err)
+	}
+
+	return output, nil
+}
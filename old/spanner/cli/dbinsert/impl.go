@@ -6,10 +6,14 @@ import (
 	"io"
 
 	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/gotaface/old/ddl/schema"
 	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/insert"
+	"github.com/Jumpaku/gotaface/old/dml/update"
 	spanner_impl "github.com/Jumpaku/gotaface/old/spanner"
 	spanner_schema "github.com/Jumpaku/gotaface/old/spanner/ddl/schema"
 	spanner_insert "github.com/Jumpaku/gotaface/old/spanner/dml/insert"
+	spanner_update "github.com/Jumpaku/gotaface/old/spanner/dml/update"
 )
 
 type InsertRows = interface {
@@ -34,33 +38,15 @@ func DBInsertFunc(ctx context.Context, driver string, dataSource string, schemaR
 			return fmt.Errorf(`fail to fetch schema or use cache: %w`, err)
 		}
 
-		tableMap := map[string]spanner_schema.Table{}
-		for _, table := range schema.TablesVal {
-			tableMap[table.Name()] = table
-		}
-
 		inserter := spanner_insert.NewInserter(rwt)
 
 		for i := 0; i < input.Len(); i++ {
 			input := input.Get(i)
-			table := tableMap[input.Name()]
-			columnMap := map[string]spanner_schema.Column{}
-			for _, column := range table.ColumnsVal {
-				columnMap[column.Name()] = column
-			}
-
-			rows := dml.Rows{}
-			for _, inputRow := range input.Rows() {
-				row := dml.Row{}
-				for column, value := range inputRow {
-					row[column], err = spanner_impl.ToDBValue(columnMap[column].Type(), value)
-					if err != nil {
-						return fmt.Errorf(`fail to convert value to DB value: %v: %w`, value, err)
-					}
-				}
-				rows = append(rows, row)
+			rows, err := spanner_insert.ToDBRows(schema, input.Name(), input.Rows())
+			if err != nil {
+				return err
 			}
-			err := inserter.Insert(ctx, input.Name(), rows)
+			err = inserter.Insert(ctx, input.Name(), rows)
 			if err != nil {
 				return fmt.Errorf(`fail to insert rows in table %s: %w`, input.Name(), err)
 			}
@@ -74,3 +60,106 @@ func DBInsertFunc(ctx context.Context, driver string, dataSource string, schemaR
 
 	return nil
 }
+
+// DBInsertDryRunFunc writes the statements to insert the rows with the row counts per table to output instead of executing them.
+func DBInsertDryRunFunc(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer, input DBInsertInput, output io.Writer) error {
+	client, err := spanner.NewClient(ctx, dataSource)
+	if err != nil {
+		return fmt.Errorf(`fail to create Spanner client %s: %w`, dataSource, err)
+	}
+	defer client.Close()
+
+	tx := client.ReadOnlyTransaction()
+	defer tx.Close()
+
+	schema, err := spanner_schema.FetchSchemaOrUseCache(ctx, schemaReader, schemaWriter, tx)
+	if err != nil {
+		return fmt.Errorf(`fail to fetch schema or use cache: %w`, err)
+	}
+
+	inserter := spanner_insert.NewInserter(spanner_impl.NewDryRunUpdater(tx, output))
+
+	for i := 0; i < input.Len(); i++ {
+		input := input.Get(i)
+		rows, err := spanner_insert.ToDBRows(schema, input.Name(), input.Rows())
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(output, "-- %s: %d rows\n", input.Name(), len(rows)); err != nil {
+			return fmt.Errorf(`fail to write row count: %w`, err)
+		}
+		err = inserter.Insert(ctx, input.Name(), rows)
+		if err != nil {
+			return fmt.Errorf(`fail to insert rows in table %s: %w`, input.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// DBInsertDeferredFunc inserts the rows into the tables in the order of the references between the tables instead of the order of the input.
+// The references in cycles are deferred by inserting the rows with the nullable foreign key columns set to NULL and then updating the columns.
+func DBInsertDeferredFunc(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer, input DBInsertInput) error {
+	client, err := spanner.NewClient(ctx, dataSource)
+	if err != nil {
+		return fmt.Errorf(`fail to create Spanner client %s: %w`, dataSource, err)
+	}
+	defer client.Close()
+
+	_, err = client.ReadWriteTransaction(ctx, func(ctx context.Context, rwt *spanner.ReadWriteTransaction) error {
+		schema, err := spanner_schema.FetchSchemaOrUseCache(ctx, schemaReader, schemaWriter, rwt)
+		if err != nil {
+			return fmt.Errorf(`fail to fetch schema or use cache: %w`, err)
+		}
+
+		foreignKeys, err := spanner_schema.FetchForeignKeys(ctx, rwt)
+		if err != nil {
+			return fmt.Errorf(`fail to fetch foreign keys: %w`, err)
+		}
+
+		return insertDeferred(ctx, schema, foreignKeys, spanner_insert.NewInserter(rwt), spanner_update.NewUpdater(rwt), input, nil)
+	})
+	if err != nil {
+		return fmt.Errorf(`fail to commit transaction: %w`, err)
+	}
+
+	return nil
+}
+
+// DBInsertDeferredDryRunFunc writes the statements of DBInsertDeferredFunc with the row counts per table to output instead of executing them.
+func DBInsertDeferredDryRunFunc(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer, input DBInsertInput, output io.Writer) error {
+	client, err := spanner.NewClient(ctx, dataSource)
+	if err != nil {
+		return fmt.Errorf(`fail to create Spanner client %s: %w`, dataSource, err)
+	}
+	defer client.Close()
+
+	tx := client.ReadOnlyTransaction()
+	defer tx.Close()
+
+	schema, err := spanner_schema.FetchSchemaOrUseCache(ctx, schemaReader, schemaWriter, tx)
+	if err != nil {
+		return fmt.Errorf(`fail to fetch schema or use cache: %w`, err)
+	}
+
+	foreignKeys, err := spanner_schema.FetchForeignKeys(ctx, tx)
+	if err != nil {
+		return fmt.Errorf(`fail to fetch foreign keys: %w`, err)
+	}
+
+	updater := spanner_impl.NewDryRunUpdater(tx, output)
+	return insertDeferred(ctx, schema, foreignKeys, spanner_insert.NewInserter(updater), spanner_update.NewUpdater(updater), input, output)
+}
+
+func insertDeferred(ctx context.Context, s *spanner_schema.Schema, foreignKeys []schema.ForeignKey, inserter insert.Inserter, updater update.Updater, input DBInsertInput, output io.Writer) error {
+	rows := map[string]dml.Rows{}
+	for i := 0; i < input.Len(); i++ {
+		input := input.Get(i)
+		rows[input.Name()] = append(rows[input.Name()], input.Rows()...)
+	}
+
+	toDBRows := func(table string, rows dml.Rows) (dml.Rows, error) {
+		return spanner_insert.ToDBRows(s, table, rows)
+	}
+	return insert.InsertDeferred(ctx, s, foreignKeys, inserter, updater, toDBRows, rows, output)
+}
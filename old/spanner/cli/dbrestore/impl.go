@@ -0,0 +1,58 @@
+package dbrestore
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/delete"
+	"github.com/Jumpaku/gotaface/old/dml/insert"
+	spanner_schema "github.com/Jumpaku/gotaface/old/spanner/ddl/schema"
+	spanner_delete "github.com/Jumpaku/gotaface/old/spanner/dml/delete"
+	spanner_insert "github.com/Jumpaku/gotaface/old/spanner/dml/insert"
+	spanner_update "github.com/Jumpaku/gotaface/old/spanner/dml/update"
+	"golang.org/x/exp/maps"
+)
+
+type DBRestoreInput = map[string]dml.Rows
+
+func DBRestoreFunc(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer, input DBRestoreInput) error {
+	client, err := spanner.NewClient(ctx, dataSource)
+	if err != nil {
+		return fmt.Errorf(`fail to create Spanner client %s: %w`, dataSource, err)
+	}
+	defer client.Close()
+
+	_, err = client.ReadWriteTransaction(ctx, func(ctx context.Context, rwt *spanner.ReadWriteTransaction) error {
+		s, err := spanner_schema.FetchSchemaOrUseCache(ctx, schemaReader, schemaWriter, rwt)
+		if err != nil {
+			return fmt.Errorf(`fail to fetch schema or use cache: %w`, err)
+		}
+
+		foreignKeys, err := spanner_schema.FetchForeignKeys(ctx, rwt)
+		if err != nil {
+			return fmt.Errorf(`fail to fetch foreign keys: %w`, err)
+		}
+
+		deleter := spanner_delete.NewTransactionDeleter(rwt)
+		if err := delete.DeleteDeferred(ctx, s, foreignKeys, deleter, deleter, maps.Keys(input)); err != nil {
+			return fmt.Errorf(`fail to delete rows: %w`, err)
+		}
+
+		toDBRows := func(table string, rows dml.Rows) (dml.Rows, error) {
+			return spanner_insert.ToDBRows(s, table, rows)
+		}
+		if err := insert.InsertDeferred(ctx, s, foreignKeys, spanner_insert.NewInserter(rwt), spanner_update.NewUpdater(rwt), toDBRows, input, nil); err != nil {
+			return fmt.Errorf(`fail to insert rows: %w`, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf(`fail to commit transaction: %w`, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package dbrestore_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/spanner/cli/dbrestore"
+	"github.com/Jumpaku/gotaface/old/spanner/test"
+)
+
+var testDDLs = []string{`
+CREATE TABLE t0 (
+	id INT64,
+	name STRING(MAX),
+) PRIMARY KEY (id)
+`, `
+CREATE TABLE t1 (
+	id INT64,
+	t0_id INT64,
+	CONSTRAINT fk_t1_t0 FOREIGN KEY (t0_id) REFERENCES t0 (id),
+) PRIMARY KEY (id)
+`, `
+CREATE TABLE t2 (
+	id INT64,
+) PRIMARY KEY (id)
+`,
+}
+
+var testDMLs = []spanner.Statement{
+	{SQL: `INSERT INTO t0 (id, name) VALUES (1, 'a'), (2, 'b')`},
+	{SQL: `INSERT INTO t1 (id, t0_id) VALUES (1, 1), (2, 2)`},
+	{SQL: `INSERT INTO t2 (id) VALUES (1)`},
+}
+
+var testInput = dbrestore.DBRestoreInput{
+	"t1": []dml.Row{
+		{"id": json.Number("3"), "t0_id": json.Number("3")},
+	},
+	"t0": []dml.Row{
+		{"id": json.Number("3"), "name": "c"},
+		{"id": json.Number("4"), "name": nil},
+	},
+}
+
+func TestDBRestoreFunc(t *testing.T) {
+	test.SkipIfNoEnv(t)
+
+	env := test.GetEnvSpanner()
+	database := fmt.Sprintf(`dbrestore_%d`, time.Now().UnixNano())
+	fullDatabase := fmt.Sprintf(`projects/%s/instances/%s/databases/%s`, env.Project, env.Instance, database)
+
+	adminClient, client, tearDown := test.Setup(t, database)
+	defer tearDown()
+
+	test.InitDDL(t, adminClient, fullDatabase, testDDLs)
+	test.InitDML(t, client, testDMLs)
+
+	// sut
+	err := dbrestore.DBRestoreFunc(context.Background(), "spanner", fullDatabase, nil, io.Discard, testInput)
+	if err != nil {
+		t.Errorf(`fail to run: %v`, err)
+	}
+
+	tx := client.ReadOnlyTransaction()
+	defer tx.Close()
+
+	type t0Row struct {
+		Id   int64
+		Name spanner.NullString
+	}
+	gotT0 := test.ListRows[t0Row](t, tx, `t0`)
+	wantT0 := []t0Row{{Id: 3, Name: spanner.NullString{Valid: true, StringVal: "c"}}, {Id: 4}}
+	if len(gotT0) != len(wantT0) {
+		t.Fatalf("row count not match\n  got  = %v\n  want = %v", len(gotT0), len(wantT0))
+	}
+	for i, want := range wantT0 {
+		if *gotT0[i] != want {
+			t.Errorf("t0[%d] not match\n  got  = %v\n  want = %v", i, *gotT0[i], want)
+		}
+	}
+
+	type t1Row struct {
+		Id    int64
+		T0_id int64
+	}
+	gotT1 := test.ListRows[t1Row](t, tx, `t1`)
+	wantT1 := []t1Row{{Id: 3, T0_id: 3}}
+	if len(gotT1) != len(wantT1) {
+		t.Fatalf("row count not match\n  got  = %v\n  want = %v", len(gotT1), len(wantT1))
+	}
+	for i, want := range wantT1 {
+		if *gotT1[i] != want {
+			t.Errorf("t1[%d] not match\n  got  = %v\n  want = %v", i, *gotT1[i], want)
+		}
+	}
+
+	type t2Row struct {
+		Id int64
+	}
+	gotT2 := test.ListRows[t2Row](t, tx, `t2`)
+	if len(gotT2) != 1 {
+		t.Errorf("rows in table not restored must be kept\n  got  = %v\n  want = %v", len(gotT2), 1)
+	}
+}
@@ -0,0 +1,65 @@
+package dbsnapshot
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/gotaface/old/dml"
+	spanner_schema "github.com/Jumpaku/gotaface/old/spanner/ddl/schema"
+	spanner_dump "github.com/Jumpaku/gotaface/old/spanner/dml/dump"
+	"golang.org/x/sync/errgroup"
+)
+
+type DBSnapshotOutput = map[string]dml.Rows
+
+// dumpParallelism is the maximum number of tables dumped concurrently.
+const dumpParallelism = 8
+
+func DBSnapshotFunc(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer) (DBSnapshotOutput, error) {
+	client, err := spanner.NewClient(ctx, dataSource)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to create Spanner client %s: %w`, dataSource, err)
+	}
+	defer client.Close()
+
+	btx, err := client.BatchReadOnlyTransaction(ctx, spanner.StrongRead())
+	if err != nil {
+		return nil, fmt.Errorf(`fail to begin batch read-only transaction: %w`, err)
+	}
+	defer btx.Cleanup(ctx)
+
+	s, err := spanner_schema.FetchSchemaOrUseCache(ctx, schemaReader, schemaWriter, btx)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to fetch schema or use cache: %w`, err)
+	}
+
+	dumper := spanner_dump.NewPartitionedDumper(btx, s)
+
+	dumped := make([]dml.Rows, len(s.TablesVal))
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(dumpParallelism)
+	for i, table := range s.TablesVal {
+		i, table := i, table
+		eg.Go(func() error {
+			rows, err := dumper.Dump(egCtx, table.Name())
+			if err != nil {
+				return fmt.Errorf(`fail to dump rows in table %s: %w`, table.Name(), err)
+			}
+
+			dumped[i] = rows
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	output := DBSnapshotOutput{}
+	for i, table := range s.TablesVal {
+		output[table.Name()] = dumped[i]
+	}
+
+	return output, nil
+}
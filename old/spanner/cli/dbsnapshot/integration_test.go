@@ -0,0 +1,89 @@
+package dbsnapshot_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/spanner/cli/dbsnapshot"
+	"github.com/Jumpaku/gotaface/old/spanner/test"
+)
+
+var testDDLs = []string{`
+CREATE TABLE t0 (
+	id INT64,
+	name STRING(MAX),
+) PRIMARY KEY (id)
+`, `
+CREATE TABLE t1 (
+	id INT64,
+	t0_id INT64,
+	CONSTRAINT fk_t1_t0 FOREIGN KEY (t0_id) REFERENCES t0 (id),
+) PRIMARY KEY (id)
+`,
+}
+
+var testDMLs = []spanner.Statement{
+	{SQL: `INSERT INTO t0 (id, name) VALUES (2, 'b'), (1, NULL)`},
+	{SQL: `INSERT INTO t1 (id, t0_id) VALUES (1, 2), (2, 1)`},
+}
+
+var wantOutput = dbsnapshot.DBSnapshotOutput{
+	"t0": []dml.Row{
+		{"id": spanner.NullInt64{Valid: true, Int64: 1}, "name": spanner.NullString{}},
+		{"id": spanner.NullInt64{Valid: true, Int64: 2}, "name": spanner.NullString{Valid: true, StringVal: "b"}},
+	},
+	"t1": []dml.Row{
+		{"id": spanner.NullInt64{Valid: true, Int64: 1}, "t0_id": spanner.NullInt64{Valid: true, Int64: 2}},
+		{"id": spanner.NullInt64{Valid: true, Int64: 2}, "t0_id": spanner.NullInt64{Valid: true, Int64: 1}},
+	},
+}
+
+func TestDBSnapshotFunc(t *testing.T) {
+	test.SkipIfNoEnv(t)
+
+	env := test.GetEnvSpanner()
+	database := fmt.Sprintf(`dbsnapshot_%d`, time.Now().UnixNano())
+	fullDatabase := fmt.Sprintf(`projects/%s/instances/%s/databases/%s`, env.Project, env.Instance, database)
+
+	adminClient, client, tearDown := test.Setup(t, database)
+	defer tearDown()
+
+	test.InitDDL(t, adminClient, fullDatabase, testDDLs)
+	test.InitDML(t, client, testDMLs)
+
+	// sut
+	got, err := dbsnapshot.DBSnapshotFunc(context.Background(), "spanner", fullDatabase, nil, io.Discard)
+	if err != nil {
+		t.Errorf(`fail to run: %v`, err)
+	}
+
+	want := wantOutput
+	if len(got) != len(want) {
+		t.Errorf("table count not match\n  got = %v\n  want = %v", len(got), len(want))
+	}
+	for table, wantRows := range want {
+		gotRows, ok := got[table]
+		if !ok {
+			t.Errorf("table=%s: got value does not have table", table)
+		}
+		if len(gotRows) != len(wantRows) {
+			t.Errorf("row count not match\n  got = %v\n  want = %v", len(gotRows), len(wantRows))
+		}
+		for i, wantRow := range wantRows {
+			for column, wantColumnValue := range wantRow {
+				gotColumnValue, ok := gotRows[i][column]
+				if !ok {
+					t.Errorf("table=%s, rows[%d], column=%s: got value does not have column", table, i, column)
+				}
+				if gotColumnValue != wantColumnValue {
+					t.Errorf("table=%s, rows[%d], column=%s: not equals:\n  gotVal  = %#v\n  wantVal = %#v", table, i, column, gotColumnValue, wantColumnValue)
+				}
+			}
+		}
+	}
+}
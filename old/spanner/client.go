@@ -17,6 +17,12 @@ type Updater interface {
 	Update(ctx context.Context, stmt spanner.Statement) (rowCount int64, err error)
 }
 
+type PartitionQueryer interface {
+	Queryer
+	PartitionQuery(ctx context.Context, statement spanner.Statement, opt spanner.PartitionOptions) ([]*spanner.Partition, error)
+	Execute(ctx context.Context, p *spanner.Partition) *spanner.RowIterator
+}
+
 type PartitionedUpdater interface {
 	PartitionedUpdate(ctx context.Context, stmt spanner.Statement) (count int64, err error)
 }
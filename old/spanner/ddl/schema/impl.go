@@ -333,6 +333,60 @@ ORDER BY p.TableName;
 	return parent, foreign, nil
 }
 
+// FetchForeignKeys fetches the foreign keys of the tables with the nullability of their columns.
+func FetchForeignKeys(ctx context.Context, queryer gotaface_spanner.Queryer) ([]schema.ForeignKey, error) {
+	type foreignKeyRow struct {
+		TableName           string
+		ReferencedTableName string
+		Columns             []string
+		Nullables           []string
+	}
+
+	rows := queryer.Query(ctx, spanner.Statement{SQL: `
+-- Fetches foreign keys with nullability of their columns
+SELECT
+    t.TABLE_NAME AS TableName,
+    u.TABLE_NAME AS ReferencedTableName,
+    ARRAY(
+        SELECT k.COLUMN_NAME
+        FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE AS k
+        WHERE k.CONSTRAINT_NAME = t.CONSTRAINT_NAME
+        ORDER BY k.ORDINAL_POSITION
+    ) AS Columns,
+    ARRAY(
+        SELECT c.IS_NULLABLE
+        FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE AS k
+            JOIN INFORMATION_SCHEMA.COLUMNS AS c
+            ON k.TABLE_NAME = c.TABLE_NAME AND k.COLUMN_NAME = c.COLUMN_NAME
+        WHERE k.CONSTRAINT_NAME = t.CONSTRAINT_NAME
+        ORDER BY k.ORDINAL_POSITION
+    ) AS Nullables
+FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS AS t
+    JOIN INFORMATION_SCHEMA.CONSTRAINT_TABLE_USAGE AS u
+    ON t.CONSTRAINT_NAME = u.CONSTRAINT_NAME
+WHERE t.TABLE_CATALOG = ''
+    AND t.TABLE_SCHEMA = ''
+    AND t.CONSTRAINT_TYPE = 'FOREIGN KEY'
+ORDER BY t.TABLE_NAME, t.CONSTRAINT_NAME;
+`})
+	scannedRows, err := gotaface_spanner.ScanRows[foreignKeyRow](rows)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to get foreign keys: %w`, err)
+	}
+
+	foreignKeys := []schema.ForeignKey{}
+	for _, row := range scannedRows {
+		foreignKeys = append(foreignKeys, schema.ForeignKey{
+			Table:           row.TableName,
+			Columns:         row.Columns,
+			ReferencedTable: row.ReferencedTableName,
+			Nullable:        !slices.Contains(row.Nullables, `NO`),
+		})
+	}
+
+	return foreignKeys, nil
+}
+
 func FetchSchemaOrUseCache(ctx context.Context, schemaReader io.Reader, schemaWriter io.Writer, queryer gotaface_spanner.Queryer) (*Schema, error) {
 	if schemaReader != nil {
 		schema := new(Schema)
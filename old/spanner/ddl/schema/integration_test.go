@@ -206,6 +206,48 @@ CREATE TABLE t9 (
 	}
 }
 
+func TestFetchForeignKeys(t *testing.T) {
+	adminClient, client, tearDown := spanner_test.Setup(t, fmt.Sprintf(`ddl_schema_fk_%d`, time.Now().UnixNano()))
+	defer tearDown()
+
+	spanner_test.InitDDL(t, adminClient, client.DatabaseName(), []string{`
+CREATE TABLE User (
+	id INT64 NOT NULL,
+	team_id INT64
+) PRIMARY KEY (id)`,
+		`
+CREATE TABLE Team (
+	id INT64 NOT NULL,
+	owner_id INT64 NOT NULL,
+	CONSTRAINT FK_Team_User FOREIGN KEY (owner_id) REFERENCES User (id)
+) PRIMARY KEY (id)`,
+		`
+ALTER TABLE User ADD CONSTRAINT FK_User_Team FOREIGN KEY (team_id) REFERENCES Team (id)`,
+	})
+	tx := client.ReadOnlyTransaction()
+	defer tx.Close()
+
+	got, err := schema_impl.FetchForeignKeys(context.Background(), tx)
+	if err != nil {
+		t.Fatalf("fail to fetch foreign keys: %v", err)
+	}
+
+	want := []schema.ForeignKey{
+		{Table: "Team", Columns: []string{"owner_id"}, ReferencedTable: "User", Nullable: false},
+		{Table: "User", Columns: []string{"team_id"}, ReferencedTable: "Team", Nullable: true},
+	}
+	if !slices.EqualFunc(got, want, equalsForeignKey) {
+		t.Errorf("ForeignKeys not match\n  got = %v\n  want = %v", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func equalsForeignKey(got schema.ForeignKey, want schema.ForeignKey) bool {
+	return got.Table == want.Table &&
+		slices.Equal(got.Columns, want.Columns) &&
+		got.ReferencedTable == want.ReferencedTable &&
+		got.Nullable == want.Nullable
+}
+
 func equalsReferences(got [][]int, want [][]int) bool {
 	if len(got) != len(want) {
 		return false
@@ -3,6 +3,7 @@ package delete
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"cloud.google.com/go/spanner"
 	"github.com/Jumpaku/gotaface/old/dml/delete"
@@ -14,11 +15,25 @@ type deleter struct {
 }
 
 var _ delete.Deleter = deleter{}
+var _ delete.Clearer = deleter{}
 
 func NewDeleter(updater gotaface_spanner.PartitionedUpdater) deleter {
 	return deleter{updater: updater}
 }
 
+// NewTransactionDeleter returns a deleter which executes DML statements by updater such as a read-write transaction instead of partitioned DML.
+func NewTransactionDeleter(updater gotaface_spanner.Updater) deleter {
+	return deleter{updater: transactionUpdater{updater: updater}}
+}
+
+type transactionUpdater struct {
+	updater gotaface_spanner.Updater
+}
+
+func (u transactionUpdater) PartitionedUpdate(ctx context.Context, stmt spanner.Statement) (count int64, err error) {
+	return u.updater.Update(ctx, stmt)
+}
+
 func (deleter deleter) Delete(ctx context.Context, table string) error {
 	_, err := deleter.updater.PartitionedUpdate(ctx, spanner.Statement{SQL: fmt.Sprintf(`DELETE FROM %s WHERE TRUE`, table)})
 	if err != nil {
@@ -26,3 +41,15 @@ func (deleter deleter) Delete(ctx context.Context, table string) error {
 	}
 	return nil
 }
+
+func (deleter deleter) Clear(ctx context.Context, table string, columns []string) error {
+	sets := []string{}
+	for _, column := range columns {
+		sets = append(sets, column+`=NULL`)
+	}
+	_, err := deleter.updater.PartitionedUpdate(ctx, spanner.Statement{SQL: fmt.Sprintf(`UPDATE %s SET %s WHERE TRUE`, table, strings.Join(sets, ","))})
+	if err != nil {
+		return fmt.Errorf(`fail to clear columns: %w`, err)
+	}
+	return nil
+}
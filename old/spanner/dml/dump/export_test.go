@@ -0,0 +1,3 @@
+package dump
+
+var CompareValue = compareValue
@@ -46,6 +46,15 @@ func (dumper dumper) Dump(ctx context.Context, tableName string) (dml.Rows, erro
 
 	itr := dumper.queryer.Query(ctx, stmt)
 
+	rows, err := scanRows(itr, table)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to query by %#v : %w`, stmt, err)
+	}
+
+	return rows, nil
+}
+
+func scanRows(itr *spanner.RowIterator, table spanner_schema.Table) (dml.Rows, error) {
 	rows := dml.Rows{}
 	err := itr.Do(func(r *spanner.Row) error {
 		row := dml.Row{}
@@ -63,7 +72,7 @@ func (dumper dumper) Dump(ctx context.Context, tableName string) (dml.Rows, erro
 		return nil
 	})
 	if err != nil {
-		return nil, fmt.Errorf(`fail to query by %#v : %w`, stmt, err)
+		return nil, err
 	}
 
 	return rows, nil
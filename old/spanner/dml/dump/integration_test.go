@@ -90,6 +90,81 @@ VALUES
 	}
 }
 
+func TestPartitionedDumper_Dump_Order(t *testing.T) {
+	adminClient, client, tearDown := spanner_test.Setup(t, fmt.Sprintf(`dml_dump_%d`, time.Now().UnixNano()))
+	defer tearDown()
+
+	spanner_test.InitDDL(t, adminClient, client.DatabaseName(), []string{`
+CREATE TABLE t (
+	id1 INT64,
+	id2 STRING(MAX),
+) PRIMARY KEY (id1, id2)
+`})
+
+	spanner_test.InitDML(t, client, []spanner.Statement{spanner.NewStatement(`
+INSERT INTO t (id1, id2)
+VALUES
+	(1, 'b'),
+	(2, 'b'),
+	(1, 'a'),
+	(2, 'a'),
+	(NULL, 'a')
+`)})
+
+	ctx := context.Background()
+	tx, err := client.BatchReadOnlyTransaction(ctx, spanner.StrongRead())
+	if err != nil {
+		t.Fatalf("fail to begin batch read-only transaction: %v", err)
+	}
+	defer tx.Cleanup(ctx)
+
+	schema, err := spanner_schema.NewFetcher(tx).Fetch(ctx)
+	if err != nil {
+		t.Fatalf("fail to fetch schema: %v", err)
+	}
+
+	sut := dump.NewPartitionedDumper(tx, schema.(*spanner_schema.Schema))
+
+	got, err := sut.Dump(context.Background(), `t`)
+	if err != nil {
+		t.Errorf("fail to dump table: %v", err)
+	}
+
+	want := dml.Rows{
+		{
+			`id1`: spanner.NullInt64{},
+			`id2`: spanner.NullString{Valid: true, StringVal: `a`},
+		}, {
+			`id1`: spanner.NullInt64{Valid: true, Int64: 1},
+			`id2`: spanner.NullString{Valid: true, StringVal: `a`},
+		}, {
+			`id1`: spanner.NullInt64{Valid: true, Int64: 1},
+			`id2`: spanner.NullString{Valid: true, StringVal: `b`},
+		}, {
+			`id1`: spanner.NullInt64{Valid: true, Int64: 2},
+			`id2`: spanner.NullString{Valid: true, StringVal: `a`},
+		}, {
+			`id1`: spanner.NullInt64{Valid: true, Int64: 2},
+			`id2`: spanner.NullString{Valid: true, StringVal: `b`},
+		},
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("table count not match\n  got = %v\n  want = %v", got, want)
+	}
+	for i, want := range want {
+		for key, want := range want {
+			got, ok := got[i][key]
+			if !ok {
+				t.Errorf("i = %d: gotVal does not have key %s", i, key)
+			}
+			if !equals(got, want) {
+				t.Errorf("i = %d, key = %s\n  gotVal  = %#v\n  wantVal = %#v", i, key, got, want)
+			}
+		}
+	}
+}
+
 func TestDumper_Dump_Types(t *testing.T) {
 	adminClient, client, tearDown := spanner_test.Setup(t, fmt.Sprintf(`dml_dump_%d`, time.Now().UnixNano()))
 	defer tearDown()
@@ -0,0 +1,216 @@
+package dump
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/dump"
+	gotaface_spanner "github.com/Jumpaku/gotaface/old/spanner"
+	spanner_schema "github.com/Jumpaku/gotaface/old/spanner/ddl/schema"
+	"golang.org/x/exp/constraints"
+	"golang.org/x/sync/errgroup"
+)
+
+// partitionParallelism is the maximum number of partitions of a table read concurrently.
+const partitionParallelism = 8
+
+type partitionedDumper struct {
+	queryer  gotaface_spanner.PartitionQueryer
+	schema   *spanner_schema.Schema
+	tableMap map[string]spanner_schema.Table
+}
+
+var _ dump.Dumper = partitionedDumper{}
+
+// NewPartitionedDumper returns a Dumper which splits the query of a table into partitions by PartitionQuery and reads the partitions in parallel.
+// The rows read from the partitions are merged and sorted by the primary key as the Dumper returned by NewDumper does.
+func NewPartitionedDumper(queryer gotaface_spanner.PartitionQueryer, schema *spanner_schema.Schema) partitionedDumper {
+	tableMap := map[string]spanner_schema.Table{}
+	for _, table := range schema.TablesVal {
+		tableMap[table.Name()] = table
+	}
+	return partitionedDumper{queryer: queryer, schema: schema, tableMap: tableMap}
+}
+
+func (dumper partitionedDumper) Dump(ctx context.Context, tableName string) (dml.Rows, error) {
+	table, ok := dumper.tableMap[tableName]
+	if !ok {
+		return nil, fmt.Errorf(`table %s not found`, tableName)
+	}
+
+	// ORDER BY is not allowed in partitioned queries, so that rows are sorted after all partitions are read.
+	stmt := spanner.Statement{
+		SQL: fmt.Sprintf(`SELECT * FROM %s`, table.Name()),
+	}
+
+	partitions, err := dumper.queryer.PartitionQuery(ctx, stmt, spanner.PartitionOptions{})
+	if err != nil {
+		return nil, fmt.Errorf(`fail to partition query %#v : %w`, stmt, err)
+	}
+
+	partitionRows := make([]dml.Rows, len(partitions))
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(partitionParallelism)
+	for i, partition := range partitions {
+		i, partition := i, partition
+		eg.Go(func() error {
+			rows, err := scanRows(dumper.queryer.Execute(egCtx, partition), table)
+			if err != nil {
+				return fmt.Errorf(`fail to read partition %d of %#v : %w`, i, stmt, err)
+			}
+
+			partitionRows[i] = rows
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	rows := dml.Rows{}
+	for _, partitionRows := range partitionRows {
+		rows = append(rows, partitionRows...)
+	}
+
+	primaryKey := []string{}
+	for _, keyIndex := range table.PrimaryKey() {
+		primaryKey = append(primaryKey, table.Columns()[keyIndex].Name())
+	}
+	var sortErr error
+	sort.SliceStable(rows, func(i, j int) bool {
+		c, err := compareKey(rows[i], rows[j], primaryKey)
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+		return c < 0
+	})
+	if sortErr != nil {
+		return nil, fmt.Errorf(`fail to sort rows in table %s by primary key: %w`, table.Name(), sortErr)
+	}
+
+	return rows, nil
+}
+
+func compareKey(a, b dml.Row, key []string) (int, error) {
+	for _, column := range key {
+		c, err := compareValue(a[column], b[column])
+		if err != nil {
+			return 0, fmt.Errorf(`fail to compare values in column %s: %w`, column, err)
+		}
+		if c != 0 {
+			return c, nil
+		}
+	}
+	return 0, nil
+}
+
+func compareOrdered[T constraints.Ordered](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareNull compares validities of values, where NULL is less than any other values as ordered in Spanner.
+func compareNull(aValid, bValid bool) int {
+	switch {
+	case aValid == bValid:
+		return 0
+	case aValid:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// compareValue compares values of a key column in the order of Spanner, and returns an error if the values are of different types or of a type which cannot be a key.
+func compareValue(a, b any) (int, error) {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return 0, fmt.Errorf(`key values of different types: %#v and %#v`, a, b)
+	}
+
+	switch a := a.(type) {
+	default:
+		return 0, fmt.Errorf(`unsupported key value: %#v`, a)
+	case spanner.NullInt64:
+		b := b.(spanner.NullInt64)
+		if c := compareNull(a.Valid, b.Valid); c != 0 || !a.Valid {
+			return c, nil
+		}
+		return compareOrdered(a.Int64, b.Int64), nil
+	case spanner.NullString:
+		b := b.(spanner.NullString)
+		if c := compareNull(a.Valid, b.Valid); c != 0 || !a.Valid {
+			return c, nil
+		}
+		return compareOrdered(a.StringVal, b.StringVal), nil
+	case spanner.NullBool:
+		b := b.(spanner.NullBool)
+		if c := compareNull(a.Valid, b.Valid); c != 0 || !a.Valid {
+			return c, nil
+		}
+		return compareOrdered(boolToInt(a.Bool), boolToInt(b.Bool)), nil
+	case spanner.NullFloat64:
+		b := b.(spanner.NullFloat64)
+		if c := compareNull(a.Valid, b.Valid); c != 0 || !a.Valid {
+			return c, nil
+		}
+		// NaN is less than any other non-NULL values as ordered in Spanner.
+		if c := compareNull(!math.IsNaN(a.Float64), !math.IsNaN(b.Float64)); c != 0 || math.IsNaN(a.Float64) {
+			return c, nil
+		}
+		return compareOrdered(a.Float64, b.Float64), nil
+	case spanner.NullTime:
+		b := b.(spanner.NullTime)
+		if c := compareNull(a.Valid, b.Valid); c != 0 || !a.Valid {
+			return c, nil
+		}
+		return compareTime(a.Time.Before(b.Time), a.Time.After(b.Time)), nil
+	case spanner.NullDate:
+		b := b.(spanner.NullDate)
+		if c := compareNull(a.Valid, b.Valid); c != 0 || !a.Valid {
+			return c, nil
+		}
+		return compareTime(a.Date.Before(b.Date), a.Date.After(b.Date)), nil
+	case spanner.NullNumeric:
+		b := b.(spanner.NullNumeric)
+		if c := compareNull(a.Valid, b.Valid); c != 0 || !a.Valid {
+			return c, nil
+		}
+		return a.Numeric.Cmp(&b.Numeric), nil
+	case []byte:
+		b := b.([]byte)
+		if c := compareNull(a != nil, b != nil); c != 0 || a == nil {
+			return c, nil
+		}
+		return bytes.Compare(a, b), nil
+	}
+}
+
+func compareTime(before, after bool) int {
+	switch {
+	case before:
+		return -1
+	case after:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
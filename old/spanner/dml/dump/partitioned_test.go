@@ -0,0 +1,38 @@
+package dump_test
+
+import (
+	"math"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/gotaface/old/spanner/dml/dump"
+)
+
+func TestCompareValue(t *testing.T) {
+	type testCase struct {
+		a, b  any
+		want  int
+		isErr bool
+	}
+	testCases := []testCase{
+		{a: spanner.NullInt64{Valid: true, Int64: 1}, b: spanner.NullInt64{Valid: true, Int64: 2}, want: -1},
+		{a: spanner.NullInt64{}, b: spanner.NullInt64{Valid: true, Int64: 0}, want: -1},
+		{a: spanner.NullString{Valid: true, StringVal: `b`}, b: spanner.NullString{Valid: true, StringVal: `a`}, want: 1},
+		{a: spanner.NullFloat64{Valid: true, Float64: math.NaN()}, b: spanner.NullFloat64{Valid: true, Float64: math.Inf(-1)}, want: -1},
+		{a: []byte{1}, b: []byte{1}, want: 0},
+		{a: spanner.NullInt64{Valid: true, Int64: 1}, b: spanner.NullString{Valid: true, StringVal: `1`}, isErr: true},
+		{a: spanner.NullJSON{Valid: true, Value: 1}, b: spanner.NullJSON{Valid: true, Value: 2}, isErr: true},
+		{a: nil, b: nil, isErr: true},
+	}
+
+	for i, testCase := range testCases {
+		got, err := dump.CompareValue(testCase.a, testCase.b)
+		if (err != nil) != testCase.isErr {
+			t.Errorf("%d: unexpected error state: %v", i, err)
+			continue
+		}
+		if got != testCase.want {
+			t.Errorf("%d: got != want\n  got  = %d\n  want = %d", i, got, testCase.want)
+		}
+	}
+}
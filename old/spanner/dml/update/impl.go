@@ -0,0 +1,57 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/update"
+	spanner_impl "github.com/Jumpaku/gotaface/old/spanner"
+	"golang.org/x/exp/slices"
+)
+
+type updater struct {
+	updater spanner_impl.Updater
+}
+
+var _ update.Updater = updater{}
+
+func NewUpdater(spannerUpdater spanner_impl.Updater) updater {
+	return updater{updater: spannerUpdater}
+}
+
+func (updater updater) Update(ctx context.Context, table string, key []string, rows dml.Rows) error {
+	for _, row := range rows {
+		columns := []string{}
+		for column := range row {
+			if !slices.Contains(key, column) {
+				columns = append(columns, column)
+			}
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		slices.Sort(columns)
+
+		sets := []string{}
+		params := map[string]any{}
+		for _, column := range columns {
+			sets = append(sets, column+`=@`+column)
+			params[column] = row[column]
+		}
+		conditions := []string{}
+		for _, column := range key {
+			conditions = append(conditions, column+`=@`+column)
+			params[column] = row[column]
+		}
+
+		stmt := fmt.Sprintf(`UPDATE %s SET %s WHERE %s`, table, strings.Join(sets, ","), strings.Join(conditions, " AND "))
+		_, err := updater.updater.Update(ctx, spanner.Statement{SQL: stmt, Params: params})
+		if err != nil {
+			return fmt.Errorf(`fail to update row by %#v [%#v]: %w`, stmt, params, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package spanner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/spanner"
+)
+
+type dryRunUpdater struct {
+	Queryer
+	writer io.Writer
+}
+
+var _ Updater = dryRunUpdater{}
+var _ PartitionedUpdater = dryRunUpdater{}
+
+// NewDryRunUpdater returns an Updater and a PartitionedUpdater which write statements with parameters to writer instead of executing them.
+// Queries are executed by queryer.
+func NewDryRunUpdater(queryer Queryer, writer io.Writer) dryRunUpdater {
+	return dryRunUpdater{Queryer: queryer, writer: writer}
+}
+
+func (updater dryRunUpdater) Update(ctx context.Context, stmt spanner.Statement) (rowCount int64, err error) {
+	params := stmt.Params
+	if params == nil {
+		params = map[string]any{}
+	}
+
+	b, err := json.Marshal(params)
+	if err != nil {
+		return 0, fmt.Errorf(`fail to marshal parameters to JSON: %w`, err)
+	}
+	if _, err := fmt.Fprintf(updater.writer, "%s;\n-- params: %s\n", stmt.SQL, b); err != nil {
+		return 0, fmt.Errorf(`fail to write statement: %w`, err)
+	}
+
+	return 0, nil
+}
+
+func (updater dryRunUpdater) PartitionedUpdate(ctx context.Context, stmt spanner.Statement) (count int64, err error) {
+	return updater.Update(ctx, stmt)
+}
@@ -0,0 +1,129 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/gotaface/old/ddl/schema"
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/delete"
+	"github.com/Jumpaku/gotaface/old/dml/fixture"
+	"github.com/Jumpaku/gotaface/old/dml/insert"
+	spanner_schema "github.com/Jumpaku/gotaface/old/spanner/ddl/schema"
+	spanner_delete "github.com/Jumpaku/gotaface/old/spanner/dml/delete"
+	spanner_dump "github.com/Jumpaku/gotaface/old/spanner/dml/dump"
+	spanner_insert "github.com/Jumpaku/gotaface/old/spanner/dml/insert"
+	spanner_update "github.com/Jumpaku/gotaface/old/spanner/dml/update"
+	"golang.org/x/exp/slices"
+)
+
+// Load replaces rows in the tables of the fixture file with the rows in the file, and registers a cleanup function to restore the original rows after the test.
+// The fixture file must follow the input format of gf-dbinsert in JSON, or in YAML if its extension is .yaml or .yml.
+// Rows are deleted from and inserted into the tables in a read-write transaction in the order of references between tables regardless of the order in the file, tolerating cycles of references as gf-dbinsert with -order references does.
+// Rows in the tables referencing the tables of the fixture file, directly or indirectly, are also deleted and restored after the test.
+func Load(ctx context.Context, t *testing.T, client *spanner.Client, path string) {
+	t.Helper()
+
+	tables, err := fixture.Read(path)
+	if err != nil {
+		t.Fatalf(`fail to read fixture: %v`, err)
+	}
+	names, rows := fixture.Merge(tables)
+
+	var s *spanner_schema.Schema
+	var foreignKeys []schema.ForeignKey
+	var targets []string
+	var snapshot map[string]dml.Rows
+	_, err = client.ReadWriteTransaction(ctx, func(ctx context.Context, rwt *spanner.ReadWriteTransaction) error {
+		s, err = spanner_schema.FetchSchema(ctx, rwt)
+		if err != nil {
+			return fmt.Errorf(`fail to fetch schema: %w`, err)
+		}
+		foreignKeys, err = spanner_schema.FetchForeignKeys(ctx, rwt)
+		if err != nil {
+			return fmt.Errorf(`fail to fetch foreign keys: %w`, err)
+		}
+		targets, err = referencingTables(s, names)
+		if err != nil {
+			return fmt.Errorf(`fail to find tables: %w`, err)
+		}
+
+		dumper := spanner_dump.NewDumper(rwt, s)
+		snapshot = map[string]dml.Rows{}
+		for _, table := range targets {
+			snapshot[table], err = dumper.Dump(ctx, table)
+			if err != nil {
+				return fmt.Errorf(`fail to dump rows in table %s: %w`, table, err)
+			}
+		}
+
+		dbRows := map[string]dml.Rows{}
+		for table, tableRows := range rows {
+			dbRows[table], err = spanner_insert.ToDBRows(s, table, tableRows)
+			if err != nil {
+				return fmt.Errorf(`fail to convert rows in table %s: %w`, table, err)
+			}
+		}
+		return replace(ctx, rwt, s, foreignKeys, targets, dbRows)
+	})
+	if err != nil {
+		t.Fatalf(`fail to load fixture: %v`, err)
+	}
+
+	t.Cleanup(func() {
+		_, err := client.ReadWriteTransaction(context.Background(), func(ctx context.Context, rwt *spanner.ReadWriteTransaction) error {
+			return replace(ctx, rwt, s, foreignKeys, targets, snapshot)
+		})
+		if err != nil {
+			t.Errorf(`fail to restore rows: %v`, err)
+		}
+	})
+}
+
+// replace deletes rows in the tables and inserts rows, which are already converted into DB values.
+func replace(ctx context.Context, rwt *spanner.ReadWriteTransaction, s *spanner_schema.Schema, foreignKeys []schema.ForeignKey, tables []string, rows map[string]dml.Rows) error {
+	deleter := spanner_delete.NewTransactionDeleter(rwt)
+	if err := delete.DeleteDeferred(ctx, s, foreignKeys, deleter, deleter, tables); err != nil {
+		return fmt.Errorf(`fail to delete rows: %w`, err)
+	}
+
+	toDBRows := func(table string, rows dml.Rows) (dml.Rows, error) { return rows, nil }
+	if err := insert.InsertDeferred(ctx, s, foreignKeys, spanner_insert.NewInserter(rwt), spanner_update.NewUpdater(rwt), toDBRows, rows, nil); err != nil {
+		return fmt.Errorf(`fail to insert rows: %w`, err)
+	}
+	return nil
+}
+
+// referencingTables returns the names and the names of the tables referencing them directly or indirectly in the order of the tables in the schema.
+func referencingTables(s *spanner_schema.Schema, names []string) ([]string, error) {
+	targets := make([]bool, len(s.TablesVal))
+	for _, name := range names {
+		index := slices.IndexFunc(s.TablesVal, func(t spanner_schema.Table) bool { return t.Name() == name })
+		if index < 0 {
+			return nil, fmt.Errorf(`table %s not found`, name)
+		}
+		targets[index] = true
+	}
+
+	for found := true; found; {
+		found = false
+		for from, tos := range s.References() {
+			if targets[from] {
+				continue
+			}
+			if slices.ContainsFunc(tos, func(to int) bool { return targets[to] }) {
+				targets[from], found = true, true
+			}
+		}
+	}
+
+	referencing := []string{}
+	for index, table := range s.TablesVal {
+		if targets[index] {
+			referencing = append(referencing, table.Name())
+		}
+	}
+	return referencing, nil
+}
@@ -0,0 +1,112 @@
+package fixture_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/spanner/fixture"
+	"github.com/Jumpaku/gotaface/old/spanner/test"
+)
+
+func TestLoad(t *testing.T) {
+	env := test.GetEnvSpanner()
+	database := fmt.Sprintf(`fixture_%d`, time.Now().UnixNano())
+	fullDatabase := fmt.Sprintf(`projects/%s/instances/%s/databases/%s`, env.Project, env.Instance, database)
+
+	adminClient, client, tearDown := test.Setup(t, database)
+	defer tearDown()
+
+	test.InitDDL(t, adminClient, fullDatabase, []string{`
+CREATE TABLE User (
+	UserId INT64,
+	Name STRING(MAX),
+) PRIMARY KEY (UserId)`, `
+CREATE TABLE Comment (
+	UserId INT64,
+	CommentId INT64,
+	Content STRING(MAX),
+) PRIMARY KEY (UserId, CommentId),
+	INTERLEAVE IN PARENT User`,
+	})
+	test.InitDML(t, client, []spanner.Statement{
+		{SQL: `INSERT INTO User (UserId, Name) VALUES (1, 'Jumpaku'), (3, 'Carol')`},
+		{SQL: `INSERT INTO Comment (UserId, CommentId, Content) VALUES (3, 1, 'Bye')`},
+	})
+
+	t.Run(`load`, func(t *testing.T) {
+		fixture.Load(context.Background(), t, client, `testdata/users.json`)
+
+		tx := client.ReadOnlyTransaction()
+		defer tx.Close()
+		test.AssertTableRows(t, tx, `User`, dml.Rows{
+			{`UserId`: 1, `Name`: `Alice`},
+			{`UserId`: 2, `Name`: `Bob`},
+		})
+		test.AssertTableRows(t, tx, `Comment`, dml.Rows{
+			{`UserId`: 2, `CommentId`: 1, `Content`: `Hello`},
+		})
+	})
+
+	tx := client.ReadOnlyTransaction()
+	defer tx.Close()
+	test.AssertTableRows(t, tx, `User`, dml.Rows{
+		{`UserId`: 1, `Name`: `Jumpaku`},
+		{`UserId`: 3, `Name`: `Carol`},
+	})
+	test.AssertTableRows(t, tx, `Comment`, dml.Rows{
+		{`UserId`: 3, `CommentId`: 1, `Content`: `Bye`},
+	})
+}
+
+func TestLoad_References(t *testing.T) {
+	env := test.GetEnvSpanner()
+	database := fmt.Sprintf(`fixture_%d`, time.Now().UnixNano())
+	fullDatabase := fmt.Sprintf(`projects/%s/instances/%s/databases/%s`, env.Project, env.Instance, database)
+
+	adminClient, client, tearDown := test.Setup(t, database)
+	defer tearDown()
+
+	test.InitDDL(t, adminClient, fullDatabase, []string{`
+CREATE TABLE Comment (
+	CommentId INT64,
+	ReplyTo INT64,
+	Content STRING(MAX),
+	CONSTRAINT FK_Comment_ReplyTo FOREIGN KEY (ReplyTo) REFERENCES Comment (CommentId),
+) PRIMARY KEY (CommentId)`, `
+CREATE TABLE Likes (
+	LikeId INT64,
+	CommentId INT64,
+	CONSTRAINT FK_Likes_CommentId FOREIGN KEY (CommentId) REFERENCES Comment (CommentId),
+) PRIMARY KEY (LikeId)`,
+	})
+	test.InitDML(t, client, []spanner.Statement{
+		{SQL: `INSERT INTO Comment (CommentId, ReplyTo, Content) VALUES (1, NULL, 'Bye'), (3, 1, 'See you')`},
+		{SQL: `INSERT INTO Likes (LikeId, CommentId) VALUES (1, 3)`},
+	})
+
+	t.Run(`load`, func(t *testing.T) {
+		fixture.Load(context.Background(), t, client, `testdata/comments.json`)
+
+		tx := client.ReadOnlyTransaction()
+		defer tx.Close()
+		test.AssertTableRows(t, tx, `Comment`, dml.Rows{
+			{`CommentId`: 1, `ReplyTo`: 2, `Content`: `Hello`},
+			{`CommentId`: 2, `ReplyTo`: nil, `Content`: `Hi`},
+		})
+		test.AssertTableRows(t, tx, `Likes`, dml.Rows{})
+	})
+
+	tx := client.ReadOnlyTransaction()
+	defer tx.Close()
+	test.AssertTableRows(t, tx, `Comment`, dml.Rows{
+		{`CommentId`: 1, `ReplyTo`: nil, `Content`: `Bye`},
+		{`CommentId`: 3, `ReplyTo`: 1, `Content`: `See you`},
+	})
+	test.AssertTableRows(t, tx, `Likes`, dml.Rows{
+		{`LikeId`: 1, `CommentId`: 3},
+	})
+}
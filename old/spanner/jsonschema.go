@@ -0,0 +1,51 @@
+package spanner
+
+import (
+	"strings"
+
+	"github.com/Jumpaku/gotaface/old/json/jsonschema"
+)
+
+// ColumnJSONSchema returns a JSON Schema of values which ToDBValue accepts for a column of the column type.
+func ColumnJSONSchema(columnType string) *jsonschema.Schema {
+	nullable := func(t ...string) jsonschema.Types {
+		return append(jsonschema.Types(t), jsonschema.TypeNull)
+	}
+
+	lower := strings.ToLower(columnType)
+	switch {
+	case strings.HasPrefix(lower, "int64"):
+		return &jsonschema.Schema{Type: nullable(jsonschema.TypeInteger)}
+	case strings.HasPrefix(lower, "string"):
+		return &jsonschema.Schema{Type: nullable(jsonschema.TypeString)}
+	case strings.HasPrefix(lower, "bool"):
+		return &jsonschema.Schema{Type: nullable(jsonschema.TypeBoolean)}
+	case strings.HasPrefix(lower, "float64"):
+		return &jsonschema.Schema{Type: nullable(jsonschema.TypeNumber)}
+	case strings.HasPrefix(lower, "timestamp"):
+		return &jsonschema.Schema{
+			Type:        nullable(jsonschema.TypeString),
+			Pattern:     `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$`,
+			Description: `timestamp in RFC 3339`,
+		}
+	case strings.HasPrefix(lower, "date"):
+		return &jsonschema.Schema{
+			Type:        nullable(jsonschema.TypeString),
+			Pattern:     `^\d{4}-\d{2}-\d{2}$`,
+			Description: `date in YYYY-MM-DD`,
+		}
+	case strings.HasPrefix(lower, "numeric"):
+		return &jsonschema.Schema{
+			Type:    nullable(jsonschema.TypeNumber, jsonschema.TypeString),
+			Pattern: `^[+-]?(\d+(\.\d*)?|\.\d+)$`,
+		}
+	case strings.HasPrefix(lower, "bytes"):
+		return &jsonschema.Schema{Type: nullable(jsonschema.TypeString), Description: `base64-encoded bytes`}
+	case strings.HasPrefix(lower, "array<"):
+		inner := strings.TrimSuffix(strings.TrimPrefix(lower, "array<"), ">")
+		return &jsonschema.Schema{Type: nullable(jsonschema.TypeArray), Items: ColumnJSONSchema(inner)}
+	default:
+		// JSON columns accept any values, and the others cannot be given in JSON.
+		return &jsonschema.Schema{}
+	}
+}
@@ -0,0 +1,158 @@
+package spanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/davecgh/go-spew/spew"
+)
+
+// ToLiteral returns a GoogleSQL literal representing a value scanned from a column.
+func ToLiteral(src any) (string, error) {
+	switch src := src.(type) {
+	case nil:
+		return `NULL`, nil
+	case spanner.NullInt64:
+		if !src.Valid {
+			return `NULL`, nil
+		}
+		return strconv.FormatInt(src.Int64, 10), nil
+	case spanner.NullString:
+		if !src.Valid {
+			return `NULL`, nil
+		}
+		return quoteString(src.StringVal), nil
+	case spanner.NullBool:
+		if !src.Valid {
+			return `NULL`, nil
+		}
+		if src.Bool {
+			return `TRUE`, nil
+		}
+		return `FALSE`, nil
+	case spanner.NullFloat64:
+		if !src.Valid {
+			return `NULL`, nil
+		}
+		return formatFloat(src.Float64), nil
+	case spanner.NullTime:
+		if !src.Valid {
+			return `NULL`, nil
+		}
+		return `TIMESTAMP ` + quoteString(src.Time.UTC().Format(time.RFC3339Nano)), nil
+	case spanner.NullDate:
+		if !src.Valid {
+			return `NULL`, nil
+		}
+		return `DATE ` + quoteString(src.Date.String()), nil
+	case spanner.NullNumeric:
+		if !src.Valid {
+			return `NULL`, nil
+		}
+		return `NUMERIC ` + quoteString(spanner.NumericString(&src.Numeric)), nil
+	case spanner.NullJSON:
+		if !src.Valid {
+			return `NULL`, nil
+		}
+		b, err := json.Marshal(src.Value)
+		if err != nil {
+			return "", fmt.Errorf(`fail to marshal %v to JSON: %w`, spew.Sdump(src), err)
+		}
+		return `JSON ` + quoteString(string(b)), nil
+	case []byte:
+		if src == nil {
+			return `NULL`, nil
+		}
+		return quoteBytes(src), nil
+	}
+
+	srcRV := reflect.ValueOf(src)
+	if srcRV.Kind() != reflect.Slice {
+		return "", fmt.Errorf(`unsupported DB value: %v`, spew.Sdump(src))
+	}
+	if srcRV.IsNil() {
+		return `NULL`, nil
+	}
+
+	elements := []string{}
+	for i := 0; i < srcRV.Len(); i++ {
+		element, err := ToLiteral(srcRV.Index(i).Interface())
+		if err != nil {
+			return "", fmt.Errorf(`fail to convert src[%d] in %v: %w`, i, spew.Sdump(src), err)
+		}
+		elements = append(elements, element)
+	}
+	return `[` + strings.Join(elements, `, `) + `]`, nil
+}
+
+// QuoteIdentifier returns a GoogleSQL quoted identifier representing a name of a table or a column.
+func QuoteIdentifier(name string) string {
+	return "`" + strings.NewReplacer(`\`, `\\`, "`", "\\`").Replace(name) + "`"
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return `CAST("nan" AS FLOAT64)`
+	case math.IsInf(f, 1):
+		return `CAST("inf" AS FLOAT64)`
+	case math.IsInf(f, -1):
+		return `CAST("-inf" AS FLOAT64)`
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, `.e`) {
+		s += `.0`
+	}
+	return s
+}
+
+func quoteString(s string) string {
+	b := strings.Builder{}
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func quoteBytes(bs []byte) string {
+	b := strings.Builder{}
+	b.WriteString(`b"`)
+	for _, c := range bs {
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case 0x20 <= c && c < 0x7f:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
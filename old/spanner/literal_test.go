@@ -0,0 +1,74 @@
+package spanner_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"cloud.google.com/go/spanner"
+	spanner_impl "github.com/Jumpaku/gotaface/old/spanner"
+)
+
+func TestToLiteral(t *testing.T) {
+	type testCase struct {
+		src   any
+		want  string
+		isErr bool
+	}
+	testCases := []testCase{
+		{src: nil, want: `NULL`},
+		{src: spanner.NullInt64{}, want: `NULL`},
+		{src: spanner.NullInt64{Valid: true, Int64: -123}, want: `-123`},
+		{src: spanner.NullString{}, want: `NULL`},
+		{src: spanner.NullString{Valid: true, StringVal: "a\"b\\c\nd\x00e'f"}, want: `"a\"b\\c\nd\u0000e'f"`},
+		{src: spanner.NullBool{Valid: true, Bool: true}, want: `TRUE`},
+		{src: spanner.NullBool{Valid: true, Bool: false}, want: `FALSE`},
+		{src: spanner.NullFloat64{Valid: true, Float64: 1.25}, want: `1.25`},
+		{src: spanner.NullFloat64{Valid: true, Float64: 2}, want: `2.0`},
+		{src: spanner.NullFloat64{Valid: true, Float64: 1e100}, want: `1e+100`},
+		{src: spanner.NullFloat64{Valid: true, Float64: math.NaN()}, want: `CAST("nan" AS FLOAT64)`},
+		{src: spanner.NullFloat64{Valid: true, Float64: math.Inf(-1)}, want: `CAST("-inf" AS FLOAT64)`},
+		{src: spanner.NullTime{Valid: true, Time: time.Date(2023, 6, 11, 1, 23, 45, 123000000, time.UTC)}, want: `TIMESTAMP "2023-06-11T01:23:45.123Z"`},
+		{src: spanner.NullDate{Valid: true, Date: civil.Date{Year: 2023, Month: 6, Day: 11}}, want: `DATE "2023-06-11"`},
+		{src: spanner.NullNumeric{Valid: true, Numeric: *big.NewRat(-123, 4)}, want: `NUMERIC "-30.750000000"`},
+		{src: spanner.NullJSON{Valid: true, Value: map[string]any{"a": "x\"y"}}, want: `JSON "{\"a\":\"x\\\"y\"}"`},
+		{src: []byte(nil), want: `NULL`},
+		{src: []byte("a\"\\\x00\xff"), want: `b"a\"\\\x00\xff"`},
+		{src: []spanner.NullInt64(nil), want: `NULL`},
+		{src: []spanner.NullInt64{}, want: `[]`},
+		{src: []spanner.NullInt64{{Valid: true, Int64: 1}, {}}, want: `[1, NULL]`},
+		{src: spanner.GenericColumnValue{}, isErr: true},
+	}
+
+	for i, testCase := range testCases {
+		got, err := spanner_impl.ToLiteral(testCase.src)
+		if (err != nil) != testCase.isErr {
+			t.Errorf("%d: unexpected error state: %v", i, err)
+			continue
+		}
+		if got != testCase.want {
+			t.Errorf("%d: got != want\n  got  = %s\n  want = %s", i, got, testCase.want)
+		}
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	type testCase struct {
+		name string
+		want string
+	}
+	testCases := []testCase{
+		{name: `User`, want: "`User`"},
+		{name: `Order`, want: "`Order`"},
+		{name: "a`b\\c", want: "`a\\`b\\\\c`"},
+	}
+
+	for i, testCase := range testCases {
+		got := spanner_impl.QuoteIdentifier(testCase.name)
+		if got != testCase.want {
+			t.Errorf("%d: got != want\n  got  = %s\n  want = %s", i, got, testCase.want)
+		}
+	}
+}
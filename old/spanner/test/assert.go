@@ -0,0 +1,53 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	gotaface_spanner "github.com/Jumpaku/gotaface/old/spanner"
+	spanner_schema "github.com/Jumpaku/gotaface/old/spanner/ddl/schema"
+	"github.com/Jumpaku/gotaface/old/spanner/dml/dump"
+	"github.com/Jumpaku/gotaface/old/test/assert"
+)
+
+// AssertTableRows asserts that the table contains exactly the expected rows, which are matched by the primary key.
+// The expected values are converted by the column types in the schema, e.g., 1 for an INT64 column and "2023-06-11T00:00:00Z" for a TIMESTAMP column, and columns missing in the expected rows are expected to be NULL.
+// Since the schema is fetched and the table is dumped by multiple queries, queryer should be a multi-use transaction such as a read-only transaction.
+func AssertTableRows(t testing.TB, queryer gotaface_spanner.Queryer, table string, expect dml.Rows) {
+	t.Helper()
+
+	schema, err := spanner_schema.FetchSchema(context.Background(), queryer)
+	if err != nil {
+		t.Fatalf(`fail to fetch schema: %v`, err)
+		return
+	}
+	actual, err := dumpTable(queryer, schema, table)
+	if err != nil {
+		t.Fatalf(`fail to dump table %s: %v`, table, err)
+		return
+	}
+
+	assert.SchemaTableRows(t, schema, table, gotaface_spanner.ToDBValue, gotaface_spanner.FromDBValue, actual, expect)
+}
+
+// dumpTable returns rows in the table ordered by the primary key, where values are converted into plain Go values.
+func dumpTable(queryer gotaface_spanner.Queryer, schema *spanner_schema.Schema, table string) (dml.Rows, error) {
+	dumped, err := dump.NewDumper(queryer, schema).Dump(context.Background(), table)
+	if err != nil {
+		return nil, err
+	}
+	rows := dml.Rows{}
+	for _, dumpedRow := range dumped {
+		row := dml.Row{}
+		for column, value := range dumpedRow {
+			row[column], err = gotaface_spanner.FromDBValue(value)
+			if err != nil {
+				return nil, fmt.Errorf(`fail to convert value in column %s: %w`, column, err)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,234 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	spanner_admin "cloud.google.com/go/spanner/admin/database/apiv1"
+	spanner_adminpb "cloud.google.com/go/spanner/admin/database/apiv1/databasepb"
+	spanner_instance "cloud.google.com/go/spanner/admin/instance/apiv1"
+	spanner_instancepb "cloud.google.com/go/spanner/admin/instance/apiv1/instancepb"
+)
+
+const (
+	EnvSpannerEmulatorHost = "SPANNER_EMULATOR_HOST"
+	// EnvTestSpannerEmulator is the path of the emulator binary, which is looked up as emulator_main in PATH if not specified.
+	EnvTestSpannerEmulator = "GOTAFACE_TEST_SPANNER_EMULATOR"
+)
+
+const (
+	defaultEmulatorProject  = "gotaface"
+	defaultEmulatorInstance = "test"
+	emulatorStartTimeout    = 30 * time.Second
+)
+
+// SetupEmulator creates a database with the DDL statements in the file at ddlPath on the Spanner emulator and returns a client of the database and a function to tear down.
+// If SPANNER_EMULATOR_HOST is not set, it starts the emulator binary specified by GOTAFACE_TEST_SPANNER_EMULATOR or found as emulator_main in PATH, and the test is skipped if the binary is not found.
+// The instance is created if it does not exist, where the project and the instance are given by GOTAFACE_TEST_SPANNER_PROJECT and GOTAFACE_TEST_SPANNER_INSTANCE or default to gotaface and test respectively.
+// If ddlPath is empty, the database is created without tables.
+func SetupEmulator(t *testing.T, database string, ddlPath string) (*spanner.Client, func()) {
+	t.Helper()
+
+	var ddl []string
+	if ddlPath != "" {
+		b, err := os.ReadFile(ddlPath)
+		if err != nil {
+			t.Fatalf(`fail to read DDL file %s: %v`, ddlPath, err)
+		}
+		ddl = SplitDDL(string(b))
+	}
+
+	stopEmulator := func() {}
+	if os.Getenv(EnvSpannerEmulatorHost) == "" {
+		host, stop, err := startEmulator(t)
+		if err != nil {
+			t.Fatalf(`fail to start Spanner emulator: %v`, err)
+		}
+		stopEmulator = stop
+		t.Setenv(EnvSpannerEmulatorHost, host)
+	}
+
+	env := GetEnvSpanner()
+	if env.Project == "" {
+		env.Project = defaultEmulatorProject
+	}
+	if env.Instance == "" {
+		env.Instance = defaultEmulatorInstance
+	}
+
+	ctx := context.Background()
+	if err := createInstanceIfNotExists(ctx, env); err != nil {
+		stopEmulator()
+		t.Fatalf(`fail to create instance: %v`, err)
+	}
+
+	adminClient, err := spanner_admin.NewDatabaseAdminClient(ctx)
+	if err != nil {
+		stopEmulator()
+		t.Fatalf(`fail to create spanner admin client: %v`, err)
+	}
+
+	parent := fmt.Sprintf(`projects/%s/instances/%s`, env.Project, env.Instance)
+	op, err := adminClient.CreateDatabase(ctx, &spanner_adminpb.CreateDatabaseRequest{
+		Parent:          parent,
+		CreateStatement: fmt.Sprintf("CREATE DATABASE `%s`", database),
+		ExtraStatements: ddl,
+	})
+	if err != nil {
+		adminClient.Close()
+		stopEmulator()
+		t.Fatalf(`fail to create spanner database in %s: %v`, parent, err)
+	}
+	if _, err := op.Wait(ctx); err != nil {
+		adminClient.Close()
+		stopEmulator()
+		t.Fatalf(`fail to wait create spanner database: %v`, err)
+	}
+
+	dataSource := fmt.Sprintf(`%s/databases/%s`, parent, database)
+	client, err := spanner.NewClient(ctx, dataSource)
+	if err != nil {
+		adminClient.Close()
+		stopEmulator()
+		t.Fatalf(`fail to create spanner client with %s: %v`, dataSource, err)
+	}
+
+	tearDown := func() {
+		client.Close()
+		adminClient.DropDatabase(ctx, &spanner_adminpb.DropDatabaseRequest{Database: dataSource})
+		adminClient.Close()
+		stopEmulator()
+	}
+
+	return client, tearDown
+}
+
+// startEmulator starts the emulator binary listening on a free port and returns the host and port of the emulator and a function to stop it.
+func startEmulator(t *testing.T) (string, func(), error) {
+	t.Helper()
+
+	binary := os.Getenv(EnvTestSpannerEmulator)
+	if binary == "" {
+		var err error
+		binary, err = exec.LookPath("emulator_main")
+		if err != nil {
+			t.Skipf(`neither %s nor %s is set and emulator_main is not found: %v`, EnvSpannerEmulatorHost, EnvTestSpannerEmulator, err)
+		}
+	}
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return "", nil, fmt.Errorf(`fail to find free port: %w`, err)
+	}
+	host := listener.Addr().String()
+	listener.Close()
+
+	cmd := exec.Command(binary, "--host_port", host)
+	if err := cmd.Start(); err != nil {
+		return "", nil, fmt.Errorf(`fail to start %s: %w`, binary, err)
+	}
+	stop := func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}
+
+	deadline := time.Now().Add(emulatorStartTimeout)
+	for {
+		conn, err := net.DialTimeout("tcp", host, time.Second)
+		if err == nil {
+			conn.Close()
+			return host, stop, nil
+		}
+		if time.Now().After(deadline) {
+			stop()
+			return "", nil, fmt.Errorf(`emulator does not listen on %s in %v: %w`, host, emulatorStartTimeout, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func createInstanceIfNotExists(ctx context.Context, env EnvSpanner) error {
+	instanceClient, err := spanner_instance.NewInstanceAdminClient(ctx)
+	if err != nil {
+		return fmt.Errorf(`fail to create spanner instance admin client: %w`, err)
+	}
+	defer instanceClient.Close()
+
+	name := fmt.Sprintf(`projects/%s/instances/%s`, env.Project, env.Instance)
+	if _, err := instanceClient.GetInstance(ctx, &spanner_instancepb.GetInstanceRequest{Name: name}); err == nil {
+		return nil
+	}
+
+	op, err := instanceClient.CreateInstance(ctx, &spanner_instancepb.CreateInstanceRequest{
+		Parent:     fmt.Sprintf(`projects/%s`, env.Project),
+		InstanceId: env.Instance,
+		Instance: &spanner_instancepb.Instance{
+			Name:        name,
+			Config:      fmt.Sprintf(`projects/%s/instanceConfigs/emulator-config`, env.Project),
+			DisplayName: env.Instance,
+			NodeCount:   1,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf(`fail to create instance %s: %w`, name, err)
+	}
+	if _, err := op.Wait(ctx); err != nil {
+		return fmt.Errorf(`fail to wait create instance %s: %w`, name, err)
+	}
+	return nil
+}
+
+// SplitDDL splits DDL statements separated by semicolons, where comments are removed and semicolons in quoted strings and identifiers are ignored.
+func SplitDDL(ddl string) []string {
+	statements := []string{}
+	var current strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			statements = append(statements, s)
+		}
+		current.Reset()
+	}
+
+	runes := []rune(ddl)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ';':
+			flush()
+		case r == '#' || (r == '-' && i+1 < len(runes) && runes[i+1] == '-'):
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			current.WriteRune('\n')
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			for i += 2; i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/'); i++ {
+			}
+			i++
+			current.WriteRune(' ')
+		case r == '\'' || r == '"' || r == '`':
+			current.WriteRune(r)
+			for i++; i < len(runes) && runes[i] != r; i++ {
+				current.WriteRune(runes[i])
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					current.WriteRune(runes[i])
+				}
+			}
+			if i < len(runes) {
+				current.WriteRune(r)
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return statements
+}
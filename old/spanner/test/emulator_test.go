@@ -0,0 +1,48 @@
+package test_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/gotaface/old/spanner/test"
+)
+
+func TestSplitDDL(t *testing.T) {
+	b, err := os.ReadFile(`testdata/ddl.sql`)
+	if err != nil {
+		t.Fatalf(`fail to read DDL: %v`, err)
+	}
+
+	got := test.SplitDDL(string(b))
+	want := []string{
+		"CREATE TABLE User (\n    UserId INT64,\n    Name STRING(MAX) DEFAULT (\"a;b\"),\n) PRIMARY KEY (UserId)",
+		"CREATE TABLE Comment (\n    UserId INT64,\n    CommentId INT64,\n) PRIMARY KEY (UserId, CommentId),\n    INTERLEAVE IN PARENT User",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("statements not match\n  got  = %q\n  want = %q", got, want)
+	}
+}
+
+func TestSetupEmulator(t *testing.T) {
+	client, tearDown := test.SetupEmulator(t, fmt.Sprintf(`emulator_%d`, time.Now().UnixNano()), `testdata/ddl.sql`)
+	defer tearDown()
+
+	test.InitDML(t, client, []spanner.Statement{{SQL: `INSERT INTO User (UserId) VALUES (1)`}})
+
+	var name string
+	row, err := client.Single().ReadRow(context.Background(), `User`, spanner.Key{1}, []string{`Name`})
+	if err != nil {
+		t.Fatalf(`fail to read row: %v`, err)
+	}
+	if err := row.Columns(&name); err != nil {
+		t.Fatalf(`fail to scan row: %v`, err)
+	}
+	if name != `a;b` {
+		t.Errorf("default value not match\n  got  = %v\n  want = %v", name, `a;b`)
+	}
+}
@@ -0,0 +1,33 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	gotaface_spanner "github.com/Jumpaku/gotaface/old/spanner"
+	spanner_schema "github.com/Jumpaku/gotaface/old/spanner/ddl/schema"
+	"github.com/Jumpaku/gotaface/old/test/golden"
+)
+
+// AssertGolden asserts that rows in the tables are equal to those in the golden file at path, e.g., testdata/users.golden.json.
+// The rows are written to the golden file as a JSON object mapping table names to rows ordered by the primary key, and the golden file is regenerated if golden.Update reports true.
+// Since the schema is fetched and the tables are dumped by multiple queries, queryer should be a multi-use transaction such as a read-only transaction.
+func AssertGolden(t testing.TB, queryer gotaface_spanner.Queryer, path string, tables []string) {
+	t.Helper()
+
+	schema, err := spanner_schema.FetchSchema(context.Background(), queryer)
+	if err != nil {
+		t.Fatalf(`fail to fetch schema: %v`, err)
+	}
+
+	dumped := map[string]dml.Rows{}
+	for _, table := range tables {
+		dumped[table], err = dumpTable(queryer, schema, table)
+		if err != nil {
+			t.Fatalf(`fail to dump table %s: %v`, table, err)
+		}
+	}
+
+	golden.AssertJSON(t, path, dumped)
+}
@@ -0,0 +1,12 @@
+-- users
+CREATE TABLE User (
+    UserId INT64,
+    Name STRING(MAX) DEFAULT ("a;b"),
+) PRIMARY KEY (UserId);
+
+/* comments of users */
+CREATE TABLE Comment (
+    UserId INT64,
+    CommentId INT64,
+) PRIMARY KEY (UserId, CommentId),
+    INTERLEAVE IN PARENT User;
@@ -148,6 +148,11 @@ func ToDBValue(columnType string, src any) (any, error) {
 			return spanner.NullFloat64{Valid: true, Float64: dst}, nil
 		case float32:
 			return ToDBValue(columnType, float64(src))
+		case int, int8, int16, int32, int64:
+			// Integers such as those of INTEGER columns in SQLite3 are accepted as FLOAT64.
+			return ToDBValue(columnType, float64(mustInt64(src)))
+		case uint, uint8, uint16, uint32, uint64:
+			return ToDBValue(columnType, float64(mustUint64(src)))
 		default:
 			dst := &spanner.NullFloat64{}
 			if err := dst.Scan(src); err != nil {
@@ -203,6 +208,12 @@ func ToDBValue(columnType string, src any) (any, error) {
 				return nil, fmt.Errorf(`fail to scan %v as NullNumeric`, spew.Sdump(src))
 			}
 			return spanner.NullNumeric{Valid: true, Numeric: *v}, nil
+		case string:
+			v, ok := (&big.Rat{}).SetString(src)
+			if !ok {
+				return nil, fmt.Errorf(`fail to parse %v as NullNumeric`, spew.Sdump(src))
+			}
+			return spanner.NullNumeric{Valid: true, Numeric: *v}, nil
 		default:
 			dst := &spanner.NullNumeric{}
 			if err := dst.Scan(src); err != nil {
@@ -272,3 +283,75 @@ func ToDBValue(columnType string, src any) (any, error) {
 		}
 	}
 }
+
+// FromDBValue converts a value scanned from a column into a plain Go value, which can be encoded into JSON and converted back by ToDBValue.
+// TIMESTAMP, DATE, and NUMERIC values are converted into strings, JSON values are converted into decoded JSON values, and ARRAY values are converted into slices of converted elements.
+func FromDBValue(src any) (any, error) {
+	switch src := src.(type) {
+	case nil:
+		return nil, nil
+	case spanner.NullInt64:
+		if !src.Valid {
+			return nil, nil
+		}
+		return src.Int64, nil
+	case spanner.NullString:
+		if !src.Valid {
+			return nil, nil
+		}
+		return src.StringVal, nil
+	case spanner.NullBool:
+		if !src.Valid {
+			return nil, nil
+		}
+		return src.Bool, nil
+	case spanner.NullFloat64:
+		if !src.Valid {
+			return nil, nil
+		}
+		return src.Float64, nil
+	case spanner.NullTime:
+		if !src.Valid {
+			return nil, nil
+		}
+		return src.Time.UTC().Format(time.RFC3339Nano), nil
+	case spanner.NullDate:
+		if !src.Valid {
+			return nil, nil
+		}
+		return src.Date.String(), nil
+	case spanner.NullNumeric:
+		if !src.Valid {
+			return nil, nil
+		}
+		return spanner.NumericString(&src.Numeric), nil
+	case spanner.NullJSON:
+		if !src.Valid {
+			return nil, nil
+		}
+		return src.Value, nil
+	case []byte:
+		if src == nil {
+			return nil, nil
+		}
+		return src, nil
+	}
+
+	srcRV := reflect.ValueOf(src)
+	if srcRV.Kind() != reflect.Slice {
+		return nil, fmt.Errorf(`unsupported DB value: %v`, spew.Sdump(src))
+	}
+	if srcRV.IsNil() {
+		return nil, nil
+	}
+
+	dst := make([]any, srcRV.Len())
+	for i := 0; i < srcRV.Len(); i++ {
+		dstElm, err := FromDBValue(srcRV.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf(`fail to convert src[%d] in %v: %w`, i, spew.Sdump(src), err)
+		}
+		dst[i] = dstElm
+	}
+	return dst, nil
+}
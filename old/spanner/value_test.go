@@ -388,6 +388,18 @@ func TestToDBValue_FLOAT64(t *testing.T) {
 			typ:  "FLOAT64",
 			src:  (*float64)(nil),
 			want: spanner.NullFloat64{},
+		}, {
+			typ:  "FLOAT64",
+			src:  int64(-2),
+			want: spanner.NullFloat64{Valid: true, Float64: -2},
+		}, {
+			typ:  "FLOAT64",
+			src:  int(3),
+			want: spanner.NullFloat64{Valid: true, Float64: 3},
+		}, {
+			typ:  "FLOAT64",
+			src:  uint32(4),
+			want: spanner.NullFloat64{Valid: true, Float64: 4},
 		},
 	}
 
@@ -674,6 +686,20 @@ func TestToDBValue_NUMERIC(t *testing.T) {
 			src:  (*big.Rat)(nil),
 			want: spanner.NullNumeric{},
 		},
+		// string
+		{
+			typ:  "NUMERIC",
+			src:  "30.75",
+			want: spanner.NullNumeric{Valid: true, Numeric: *big.NewRat(123, 4)},
+		}, {
+			typ:  "NUMERIC",
+			src:  ptr("-30.75"),
+			want: spanner.NullNumeric{Valid: true, Numeric: *big.NewRat(-123, 4)},
+		}, {
+			typ:  "NUMERIC",
+			src:  (*string)(nil),
+			want: spanner.NullNumeric{},
+		},
 	}
 
 	for i, testCase := range testCases {
@@ -803,3 +829,49 @@ func TestToDBValue_STRUCT(t *testing.T) {
 		checkTestCase(t, i, got, err, testCase)
 	}
 }
+
+func TestFromDBValue(t *testing.T) {
+	type testCase struct {
+		src   any
+		want  any
+		isErr bool
+	}
+	testCases := []testCase{
+		{src: nil, want: nil},
+		{src: spanner.NullInt64{}, want: nil},
+		{src: spanner.NullInt64{Valid: true, Int64: 123}, want: int64(123)},
+		{src: spanner.NullString{}, want: nil},
+		{src: spanner.NullString{Valid: true, StringVal: "abc"}, want: "abc"},
+		{src: spanner.NullBool{}, want: nil},
+		{src: spanner.NullBool{Valid: true, Bool: true}, want: true},
+		{src: spanner.NullFloat64{}, want: nil},
+		{src: spanner.NullFloat64{Valid: true, Float64: -1.25}, want: float64(-1.25)},
+		{src: spanner.NullTime{}, want: nil},
+		{src: spanner.NullTime{Valid: true, Time: time.Date(2023, 7, 3, 0, 23, 32, 123000000, time.UTC)}, want: "2023-07-03T00:23:32.123Z"},
+		{src: spanner.NullDate{}, want: nil},
+		{src: spanner.NullDate{Valid: true, Date: civil.Date{Year: 2023, Month: 7, Day: 3}}, want: "2023-07-03"},
+		{src: spanner.NullNumeric{}, want: nil},
+		{src: spanner.NullNumeric{Valid: true, Numeric: *big.NewRat(-123, 4)}, want: "-30.750000000"},
+		{src: spanner.NullJSON{}, want: nil},
+		{src: spanner.NullJSON{Valid: true, Value: "abc"}, want: "abc"},
+		{src: []byte(nil), want: nil},
+		{src: []byte("abc"), want: []byte("abc")},
+		{src: []spanner.NullInt64(nil), want: nil},
+		{src: []spanner.NullInt64{{Valid: true, Int64: 1}, {}}, want: []any{int64(1), nil}},
+		{src: spanner.GenericColumnValue{}, isErr: true},
+	}
+
+	for i, testCase := range testCases {
+		got, err := spanner_impl.FromDBValue(testCase.src)
+		if (err != nil) != testCase.isErr {
+			t.Errorf("%d: unexpected error state: %v", i, err)
+			continue
+		}
+		if testCase.isErr {
+			continue
+		}
+		if !equals(got, testCase.want) {
+			t.Errorf("%d: got != want\n  got  = %#v\n  want = %#v", i, got, testCase.want)
+		}
+	}
+}
@@ -4,8 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
 
+	"github.com/Jumpaku/gotaface/old/dbsql"
+	"github.com/Jumpaku/gotaface/old/dml/delete"
+	sqlite3_schema "github.com/Jumpaku/gotaface/old/sqlite3/ddl/schema"
 	sqlite3_delete "github.com/Jumpaku/gotaface/old/sqlite3/dml/delete"
+	_ "github.com/mattn/go-sqlite3"
 )
 
 type DBDeleteInput = []string
@@ -26,3 +31,102 @@ func DBDeleteFunc(ctx context.Context, driver string, dataSource string, input D
 
 	return nil
 }
+
+// DBDeleteDryRunFunc writes the statements to delete the rows with the row counts per table to output instead of executing them.
+func DBDeleteDryRunFunc(ctx context.Context, driver string, dataSource string, input DBDeleteInput, output io.Writer) error {
+	db, err := sql.Open("sqlite3", dataSource)
+	if err != nil {
+		return fmt.Errorf(`fail to open SQLite3 client %s: %w`, dataSource, err)
+	}
+	defer db.Close()
+
+	deleter := dryRunDeleter{queryer: db, deleter: sqlite3_delete.NewDeleter(dbsql.NewDryRunExecer(output)), output: output}
+	for _, target := range input {
+		if err := deleter.Delete(ctx, target); err != nil {
+			return fmt.Errorf(`fail to delete rows in table %s: %w`, target, err)
+		}
+	}
+
+	return nil
+}
+
+// DBDeleteDeferredFunc deletes the rows in the tables in the order of the references between the tables instead of the order of the input in a transaction.
+// The references in cycles are deferred by setting the nullable foreign key columns to NULL before deleting the rows.
+func DBDeleteDeferredFunc(ctx context.Context, driver string, dataSource string, input DBDeleteInput) error {
+	db, err := sql.Open("sqlite3", dataSource)
+	if err != nil {
+		return fmt.Errorf(`fail to open SQLite3 client %s: %w`, dataSource, err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf(`fail to begin transaction %s: %w`, dataSource, err)
+	}
+	defer tx.Rollback()
+
+	s, err := sqlite3_schema.FetchSchema(ctx, tx)
+	if err != nil {
+		return fmt.Errorf(`fail to fetch schema: %w`, err)
+	}
+	foreignKeys, err := sqlite3_schema.FetchForeignKeys(ctx, tx)
+	if err != nil {
+		return fmt.Errorf(`fail to fetch foreign keys: %w`, err)
+	}
+
+	deleter := sqlite3_delete.NewDeleter(tx)
+	if err := delete.DeleteDeferred(ctx, s, foreignKeys, deleter, deleter, input); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf(`fail to commit transaction: %w`, err)
+	}
+	return nil
+}
+
+// DBDeleteDeferredDryRunFunc writes the statements of DBDeleteDeferredFunc with the row counts per table to output instead of executing them.
+func DBDeleteDeferredDryRunFunc(ctx context.Context, driver string, dataSource string, input DBDeleteInput, output io.Writer) error {
+	db, err := sql.Open("sqlite3", dataSource)
+	if err != nil {
+		return fmt.Errorf(`fail to open SQLite3 client %s: %w`, dataSource, err)
+	}
+	defer db.Close()
+
+	s, err := sqlite3_schema.FetchSchema(ctx, db)
+	if err != nil {
+		return fmt.Errorf(`fail to fetch schema: %w`, err)
+	}
+	foreignKeys, err := sqlite3_schema.FetchForeignKeys(ctx, db)
+	if err != nil {
+		return fmt.Errorf(`fail to fetch foreign keys: %w`, err)
+	}
+
+	deleter := dryRunDeleter{queryer: db, deleter: sqlite3_delete.NewDeleter(dbsql.NewDryRunExecer(output)), output: output}
+	return delete.DeleteDeferred(ctx, s, foreignKeys, deleter, deleter, input)
+}
+
+// dryRunDeleter writes the row count of a table to output before writing the statement to delete the rows.
+type dryRunDeleter struct {
+	queryer dbsql.Queryer
+	deleter interface {
+		delete.Deleter
+		delete.Clearer
+	}
+	output io.Writer
+}
+
+func (d dryRunDeleter) Delete(ctx context.Context, table string) error {
+	var count int64
+	if err := d.queryer.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s`, table)).Scan(&count); err != nil {
+		return fmt.Errorf(`fail to count rows in table %s: %w`, table, err)
+	}
+	if _, err := fmt.Fprintf(d.output, "-- %s: %d rows\n", table, count); err != nil {
+		return fmt.Errorf(`fail to write row count: %w`, err)
+	}
+	return d.deleter.Delete(ctx, table)
+}
+
+func (d dryRunDeleter) Clear(ctx context.Context, table string, columns []string) error {
+	return d.deleter.Clear(ctx, table, columns)
+}
@@ -1,6 +1,7 @@
 package dbdelete_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -104,3 +105,92 @@ func TestDBDeleteFunc_ForeignKey(t *testing.T) {
 		t.Errorf("t6 not deleted")
 	}
 }
+
+func TestDBDeleteDryRunFunc(t *testing.T) {
+	sqliteTestDir := os.Getenv(test.EnvSQLiteTestDir)
+	if sqliteTestDir == "" {
+		t.Skipf(`skipped because environment variable %s is not set`, test.EnvSQLiteTestDir)
+	}
+
+	dbPath := fmt.Sprintf(`%s/cli_dbdelete_%d.db`, sqliteTestDir, time.Now().UnixNano())
+	db, tearDown := test.Setup(t, dbPath, `_foreign_keys=1`)
+	defer tearDown()
+
+	test.Init(t, db, testInitStmt)
+
+	input := []string{`t3`, `t0`}
+	output := bytes.NewBuffer(nil)
+
+	// sut
+	err := dbdelete.DBDeleteDryRunFunc(context.Background(), "sqlite3", dbPath, input, output)
+	if err != nil {
+		t.Errorf(`fail to run: %v`, err)
+	}
+
+	want := "-- t3: 1 rows\nDELETE FROM t3;\n-- params: []\n-- t0: 1 rows\nDELETE FROM t0;\n-- params: []\n"
+	if got := output.String(); got != want {
+		t.Errorf("output not match\n  got  = %q\n  want = %q", got, want)
+	}
+	if r := test.ListRows[struct{}](t, db, `t3`); len(r) != 1 {
+		t.Errorf("t3 deleted")
+	}
+	if r := test.ListRows[struct{}](t, db, `t0`); len(r) != 1 {
+		t.Errorf("t0 deleted")
+	}
+}
+
+func TestDBDeleteDeferredFunc_Cycle(t *testing.T) {
+	sqliteTestDir := os.Getenv(test.EnvSQLiteTestDir)
+	if sqliteTestDir == "" {
+		t.Skipf(`skipped because environment variable %s is not set`, test.EnvSQLiteTestDir)
+	}
+
+	dbPath := fmt.Sprintf(`%s/cli_dbdelete_%d.db`, sqliteTestDir, time.Now().UnixNano())
+	db, tearDown := test.Setup(t, dbPath, `_foreign_keys=1`)
+	defer tearDown()
+
+	test.Init(t, db, []test.Statement{
+		{SQL: `PRAGMA foreign_keys = 1`},
+		{SQL: `
+CREATE TABLE User (
+	id INT NOT NULL,
+	team_id INT,
+	PRIMARY KEY (id),
+	FOREIGN KEY (team_id) REFERENCES Team (id));`},
+		{SQL: `
+CREATE TABLE Team (
+	id INT NOT NULL,
+	owner_id INT NOT NULL,
+	PRIMARY KEY (id),
+	FOREIGN KEY (owner_id) REFERENCES User (id));`},
+		{SQL: `
+CREATE TABLE Comment (
+	id INT NOT NULL,
+	reply_to INT,
+	PRIMARY KEY (id),
+	FOREIGN KEY (reply_to) REFERENCES Comment (id));`},
+		{SQL: `INSERT INTO User (id, team_id) VALUES (1, NULL)`},
+		{SQL: `INSERT INTO Team (id, owner_id) VALUES (10, 1)`},
+		{SQL: `UPDATE User SET team_id = 10 WHERE id = 1`},
+		{SQL: `INSERT INTO Comment (id, reply_to) VALUES (1, NULL), (2, 1)`},
+		{SQL: `UPDATE Comment SET reply_to = 2 WHERE id = 1`},
+	})
+
+	input := []string{`User`, `Team`, `Comment`}
+	dataSource := `file:` + dbPath + `?_foreign_keys=1`
+	if err := dbdelete.DBDeleteFunc(context.Background(), "sqlite3", dataSource, input); err == nil {
+		t.Fatalf(`rows must not be deleted in the order of the input`)
+	}
+
+	// sut
+	err := dbdelete.DBDeleteDeferredFunc(context.Background(), "sqlite3", dataSource, input)
+	if err != nil {
+		t.Fatalf(`fail to run: %v`, err)
+	}
+
+	for _, table := range input {
+		if r := test.ListRows[struct{}](t, db, table); len(r) != 0 {
+			t.Errorf("%s not deleted", table)
+		}
+	}
+}
@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/Jumpaku/gotaface/old/dbsql"
+	"github.com/Jumpaku/gotaface/old/ddl/schema"
 	"github.com/Jumpaku/gotaface/old/dml"
-	gotaface_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3"
+	"github.com/Jumpaku/gotaface/old/dml/insert"
+	"github.com/Jumpaku/gotaface/old/dml/update"
 	sqlite3_schema "github.com/Jumpaku/gotaface/old/sqlite3/ddl/schema"
 	sqlite3_insert "github.com/Jumpaku/gotaface/old/sqlite3/dml/insert"
+	sqlite3_update "github.com/Jumpaku/gotaface/old/sqlite3/dml/update"
 )
 
 type InsertRows = interface {
@@ -39,40 +43,125 @@ func DBInsertFunc(ctx context.Context, driver string, dataSource string, schemaR
 		return fmt.Errorf(`fail to fetch schema or use cache: %w`, err)
 	}
 
-	tableMap := map[string]sqlite3_schema.Table{}
-	for _, table := range schema.TablesVal {
-		tableMap[table.Name()] = table
-	}
-
 	inserter := sqlite3_insert.NewInserter(tx)
 
 	for i := 0; i < input.Len(); i++ {
 		input := input.Get(i)
-		table := tableMap[input.Name()]
-		columnMap := map[string]sqlite3_schema.Column{}
-		for _, column := range table.ColumnsVal {
-			columnMap[column.Name()] = column
+		rows, err := sqlite3_insert.ToDBRows(schema, input.Name(), input.Rows())
+		if err != nil {
+			return err
+		}
+		err = inserter.Insert(ctx, input.Name(), rows)
+		if err != nil {
+			return fmt.Errorf(`fail to insert rows in table %s: %w`, input.Name(), err)
 		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf(`fail to commit transaction: %w`, err)
+	}
+	return nil
+}
+
+// DBInsertDryRunFunc writes the statements to insert the rows with the row counts per table to output instead of executing them.
+func DBInsertDryRunFunc(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer, input DBInsertInput, output io.Writer) error {
+	db, err := sql.Open(driver, dataSource)
+	if err != nil {
+		return fmt.Errorf(`fail to open SQLite3 %s: %w`, dataSource, err)
+	}
+	defer db.Close()
 
-		rows := dml.Rows{}
-		for _, inputRow := range input.Rows() {
-			row := dml.Row{}
-			for column, value := range inputRow {
-				row[column], err = gotaface_sqlite3.ToDBValue(columnMap[column].Type(), value)
-				if err != nil {
-					return fmt.Errorf(`fail to convert value to DB value: %v: %w`, value, err)
-				}
-			}
-			rows = append(rows, row)
+	schema, err := sqlite3_schema.FetchSchemaOrUseCache(ctx, schemaReader, schemaWriter, db)
+	if err != nil {
+		return fmt.Errorf(`fail to fetch schema or use cache: %w`, err)
+	}
+
+	inserter := sqlite3_insert.NewInserter(dbsql.NewDryRunExecer(output))
+
+	for i := 0; i < input.Len(); i++ {
+		input := input.Get(i)
+		rows, err := sqlite3_insert.ToDBRows(schema, input.Name(), input.Rows())
+		if err != nil {
+			return err
 		}
-		err := inserter.Insert(ctx, input.Name(), rows)
+		if _, err := fmt.Fprintf(output, "-- %s: %d rows\n", input.Name(), len(rows)); err != nil {
+			return fmt.Errorf(`fail to write row count: %w`, err)
+		}
+		err = inserter.Insert(ctx, input.Name(), rows)
 		if err != nil {
 			return fmt.Errorf(`fail to insert rows in table %s: %w`, input.Name(), err)
 		}
 	}
 
+	return nil
+}
+
+// DBInsertDeferredFunc inserts the rows into the tables in the order of the references between the tables instead of the order of the input.
+// The references in cycles are deferred by inserting the rows with the nullable foreign key columns set to NULL and then updating the columns.
+func DBInsertDeferredFunc(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer, input DBInsertInput) error {
+	db, err := sql.Open(driver, dataSource)
+	if err != nil {
+		return fmt.Errorf(`fail to open SQLite3 %s: %w`, dataSource, err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf(`fail to begin transaction %s: %w`, dataSource, err)
+	}
+	defer tx.Rollback()
+
+	schema, err := sqlite3_schema.FetchSchemaOrUseCache(ctx, schemaReader, schemaWriter, tx)
+	if err != nil {
+		return fmt.Errorf(`fail to fetch schema or use cache: %w`, err)
+	}
+
+	foreignKeys, err := sqlite3_schema.FetchForeignKeys(ctx, tx)
+	if err != nil {
+		return fmt.Errorf(`fail to fetch foreign keys: %w`, err)
+	}
+
+	if err := insertDeferred(ctx, schema, foreignKeys, sqlite3_insert.NewInserter(tx), sqlite3_update.NewUpdater(tx), input, nil); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf(`fail to commit transaction: %w`, err)
 	}
 	return nil
 }
+
+// DBInsertDeferredDryRunFunc writes the statements of DBInsertDeferredFunc with the row counts per table to output instead of executing them.
+func DBInsertDeferredDryRunFunc(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer, input DBInsertInput, output io.Writer) error {
+	db, err := sql.Open(driver, dataSource)
+	if err != nil {
+		return fmt.Errorf(`fail to open SQLite3 %s: %w`, dataSource, err)
+	}
+	defer db.Close()
+
+	schema, err := sqlite3_schema.FetchSchemaOrUseCache(ctx, schemaReader, schemaWriter, db)
+	if err != nil {
+		return fmt.Errorf(`fail to fetch schema or use cache: %w`, err)
+	}
+
+	foreignKeys, err := sqlite3_schema.FetchForeignKeys(ctx, db)
+	if err != nil {
+		return fmt.Errorf(`fail to fetch foreign keys: %w`, err)
+	}
+
+	execer := dbsql.NewDryRunExecer(output)
+	return insertDeferred(ctx, schema, foreignKeys, sqlite3_insert.NewInserter(execer), sqlite3_update.NewUpdater(execer), input, output)
+}
+
+func insertDeferred(ctx context.Context, s *sqlite3_schema.Schema, foreignKeys []schema.ForeignKey, inserter insert.Inserter, updater update.Updater, input DBInsertInput, output io.Writer) error {
+	rows := map[string]dml.Rows{}
+	for i := 0; i < input.Len(); i++ {
+		input := input.Get(i)
+		rows[input.Name()] = append(rows[input.Name()], input.Rows()...)
+	}
+
+	toDBRows := func(table string, rows dml.Rows) (dml.Rows, error) {
+		return sqlite3_insert.ToDBRows(s, table, rows)
+	}
+	return insert.InsertDeferred(ctx, s, foreignKeys, inserter, updater, toDBRows, rows, output)
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -255,3 +256,91 @@ func TestDBInsertFunc_WithoutSchemaCache(t *testing.T) {
 		}
 	}
 }
+
+func TestDBInsertDryRunFunc(t *testing.T) {
+	sqliteTestDir := getEnvSQLiteTestDirOrSkip(t)
+
+	dbPath := fmt.Sprintf(`%s/cli_dbinsert_%d.db`, sqliteTestDir, time.Now().UnixNano())
+	db, tearDown := test.Setup(t, dbPath, "")
+	defer tearDown()
+
+	test.Init(t, db, testInitStmt)
+
+	var schemaReader io.Reader = nil
+	var schemaWriter *bytes.Buffer = bytes.NewBuffer(nil)
+	output := bytes.NewBuffer(nil)
+
+	// sut
+	err := dbinsert.DBInsertDryRunFunc(context.Background(), "sqlite3", dbPath, schemaReader, schemaWriter, testInput, output)
+	if err != nil {
+		t.Errorf(`fail to run: %v`, err)
+	}
+
+	got := output.String()
+	for _, want := range []string{"-- t0: 4 rows\nINSERT INTO t0 ", "-- t1: 1 rows\nINSERT INTO t1 ", "-- params: "} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q\n  got = %q", want, got)
+		}
+	}
+	if r := test.ListRows[struct{}](t, db, `t0`); len(r) != 0 {
+		t.Errorf("t0 inserted")
+	}
+	if r := test.ListRows[struct{}](t, db, `t1`); len(r) != 0 {
+		t.Errorf("t1 inserted")
+	}
+}
+
+func TestDBInsertDeferredFunc(t *testing.T) {
+	sqliteTestDir := getEnvSQLiteTestDirOrSkip(t)
+
+	dbPath := fmt.Sprintf(`%s/cli_dbinsert_%d.db`, sqliteTestDir, time.Now().UnixNano())
+	db, tearDown := test.Setup(t, dbPath, "")
+	defer tearDown()
+
+	test.Init(t, db, []test.Statement{
+		{SQL: `CREATE TABLE User (
+	id INT NOT NULL,
+	team_id INT,
+	PRIMARY KEY (id),
+	FOREIGN KEY (team_id) REFERENCES Team (id));`},
+		{SQL: `CREATE TABLE Team (
+	id INT NOT NULL,
+	owner_id INT NOT NULL,
+	PRIMARY KEY (id),
+	FOREIGN KEY (owner_id) REFERENCES User (id));`},
+	})
+
+	input := dbInsertInput{
+		{name: `Team`, rows: []dml.Row{{"id": 10, "owner_id": 1}}},
+		{name: `User`, rows: []dml.Row{{"id": 1, "team_id": 10}, {"id": 2, "team_id": nil}}},
+	}
+
+	dataSource := `file:` + dbPath + `?_foreign_keys=on`
+	if err := dbinsert.DBInsertFunc(context.Background(), "sqlite3", dataSource, nil, bytes.NewBuffer(nil), input); err == nil {
+		t.Fatalf(`rows must not be inserted in the order of the input`)
+	}
+
+	// sut
+	err := dbinsert.DBInsertDeferredFunc(context.Background(), "sqlite3", dataSource, nil, bytes.NewBuffer(nil), input)
+	if err != nil {
+		t.Fatalf(`fail to run: %v`, err)
+	}
+
+	type User struct {
+		Id      sql.NullInt64
+		Team_id sql.NullInt64
+	}
+	gotUsers := test.ListRows[User](t, db, `User`)
+	wantUsers := []User{
+		{Id: sql.NullInt64{Valid: true, Int64: 1}, Team_id: sql.NullInt64{Valid: true, Int64: 10}},
+		{Id: sql.NullInt64{Valid: true, Int64: 2}},
+	}
+	if len(gotUsers) != len(wantUsers) {
+		t.Fatalf("row count not match\n  got  = %d\n  want = %d", len(gotUsers), len(wantUsers))
+	}
+	for i, want := range wantUsers {
+		if *gotUsers[i] != want {
+			t.Errorf("i = %d\n  got  = %#v\n  want = %#v", i, *gotUsers[i], want)
+		}
+	}
+}
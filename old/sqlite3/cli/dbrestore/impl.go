@@ -0,0 +1,61 @@
+package dbrestore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/delete"
+	"github.com/Jumpaku/gotaface/old/dml/insert"
+	sqlite3_schema "github.com/Jumpaku/gotaface/old/sqlite3/ddl/schema"
+	sqlite3_delete "github.com/Jumpaku/gotaface/old/sqlite3/dml/delete"
+	sqlite3_insert "github.com/Jumpaku/gotaface/old/sqlite3/dml/insert"
+	sqlite3_update "github.com/Jumpaku/gotaface/old/sqlite3/dml/update"
+	"golang.org/x/exp/maps"
+)
+
+type DBRestoreInput = map[string]dml.Rows
+
+func DBRestoreFunc(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer, input DBRestoreInput) error {
+	db, err := sql.Open(driver, dataSource)
+	if err != nil {
+		return fmt.Errorf(`fail to open SQLite3 %s: %w`, dataSource, err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf(`fail to begin transaction %s: %w`, dataSource, err)
+	}
+	defer tx.Rollback()
+
+	s, err := sqlite3_schema.FetchSchemaOrUseCache(ctx, schemaReader, schemaWriter, tx)
+	if err != nil {
+		return fmt.Errorf(`fail to fetch schema or use cache: %w`, err)
+	}
+
+	foreignKeys, err := sqlite3_schema.FetchForeignKeys(ctx, tx)
+	if err != nil {
+		return fmt.Errorf(`fail to fetch foreign keys: %w`, err)
+	}
+
+	tables := maps.Keys(input)
+	deleter := sqlite3_delete.NewDeleter(tx)
+	if err := delete.DeleteDeferred(ctx, s, foreignKeys, deleter, deleter, tables); err != nil {
+		return fmt.Errorf(`fail to delete rows: %w`, err)
+	}
+
+	toDBRows := func(table string, rows dml.Rows) (dml.Rows, error) {
+		return sqlite3_insert.ToDBRows(s, table, rows)
+	}
+	if err := insert.InsertDeferred(ctx, s, foreignKeys, sqlite3_insert.NewInserter(tx), sqlite3_update.NewUpdater(tx), toDBRows, input, nil); err != nil {
+		return fmt.Errorf(`fail to insert rows: %w`, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf(`fail to commit transaction: %w`, err)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package dbrestore_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/sqlite3/cli/dbrestore"
+	"github.com/Jumpaku/gotaface/old/sqlite3/test"
+)
+
+var testInitStmt = []test.Statement{
+	{SQL: `CREATE TABLE t0 (
+	id INT,
+	name TEXT,
+	PRIMARY KEY (id));`},
+	{SQL: `CREATE TABLE t1 (
+	id INT,
+	t0_id INT,
+	PRIMARY KEY (id),
+	FOREIGN KEY (t0_id) REFERENCES t0 (id));`},
+	{SQL: `CREATE TABLE t2 (
+	id INT,
+	PRIMARY KEY (id));`},
+	{SQL: `INSERT INTO t0 (id, name) VALUES (1, "a"), (2, "b");`},
+	{SQL: `INSERT INTO t1 (id, t0_id) VALUES (1, 1), (2, 2);`},
+	{SQL: `INSERT INTO t2 (id) VALUES (1);`},
+}
+
+var testInput = dbrestore.DBRestoreInput{
+	"t1": []dml.Row{
+		{"id": json.Number("3"), "t0_id": json.Number("3")},
+	},
+	"t0": []dml.Row{
+		{"id": json.Number("3"), "name": "c"},
+		{"id": json.Number("4"), "name": nil},
+	},
+}
+
+func getEnvSQLiteTestDirOrSkip(t *testing.T) string {
+	t.Helper()
+
+	sqliteTestDir := os.Getenv(test.EnvSQLiteTestDir)
+	if sqliteTestDir == "" {
+		t.Skipf(`skipped because environment variable %s is not set`, test.EnvSQLiteTestDir)
+	}
+
+	return sqliteTestDir
+}
+
+func TestDBRestoreFunc(t *testing.T) {
+	sqliteTestDir := getEnvSQLiteTestDirOrSkip(t)
+
+	dbPath := fmt.Sprintf(`%s/cli_dbrestore_%d.db`, sqliteTestDir, time.Now().UnixNano())
+	db, tearDown := test.Setup(t, dbPath, "_foreign_keys=on")
+	defer tearDown()
+
+	test.Init(t, db, testInitStmt)
+
+	var schemaReader io.Reader = nil
+	var schemaWriter io.Writer = io.Discard
+
+	// sut
+	err := dbrestore.DBRestoreFunc(context.Background(), "sqlite3", dbPath+"?_foreign_keys=on", schemaReader, schemaWriter, testInput)
+	if err != nil {
+		t.Errorf(`fail to run: %v`, err)
+	}
+
+	type t0Row struct {
+		Id   int64
+		Name sql.NullString
+	}
+	gotT0 := test.ListRows[t0Row](t, db, `t0`)
+	wantT0 := []t0Row{{Id: 3, Name: sql.NullString{Valid: true, String: "c"}}, {Id: 4}}
+	if len(gotT0) != len(wantT0) {
+		t.Fatalf("row count not match\n  got  = %v\n  want = %v", len(gotT0), len(wantT0))
+	}
+	for i, want := range wantT0 {
+		if *gotT0[i] != want {
+			t.Errorf("t0[%d] not match\n  got  = %v\n  want = %v", i, *gotT0[i], want)
+		}
+	}
+
+	type t1Row struct {
+		Id    int64
+		T0_id int64
+	}
+	gotT1 := test.ListRows[t1Row](t, db, `t1`)
+	wantT1 := []t1Row{{Id: 3, T0_id: 3}}
+	if len(gotT1) != len(wantT1) {
+		t.Fatalf("row count not match\n  got  = %v\n  want = %v", len(gotT1), len(wantT1))
+	}
+	for i, want := range wantT1 {
+		if *gotT1[i] != want {
+			t.Errorf("t1[%d] not match\n  got  = %v\n  want = %v", i, *gotT1[i], want)
+		}
+	}
+
+	type t2Row struct {
+		Id int64
+	}
+	gotT2 := test.ListRows[t2Row](t, db, `t2`)
+	if len(gotT2) != 1 {
+		t.Errorf("rows in table not restored must be kept\n  got  = %v\n  want = %v", len(gotT2), 1)
+	}
+}
+
+func TestDBRestoreFunc_TableNotFound(t *testing.T) {
+	sqliteTestDir := getEnvSQLiteTestDirOrSkip(t)
+
+	dbPath := fmt.Sprintf(`%s/cli_dbrestore_%d.db`, sqliteTestDir, time.Now().UnixNano())
+	db, tearDown := test.Setup(t, dbPath, "")
+	defer tearDown()
+
+	test.Init(t, db, testInitStmt)
+
+	input := dbrestore.DBRestoreInput{"unknown": []dml.Row{}}
+
+	// sut
+	err := dbrestore.DBRestoreFunc(context.Background(), "sqlite3", dbPath, nil, io.Discard, input)
+	if err == nil {
+		t.Errorf(`error not detected`)
+	}
+}
+
+func TestDBRestoreFunc_SelfReference(t *testing.T) {
+	sqliteTestDir := getEnvSQLiteTestDirOrSkip(t)
+
+	dbPath := fmt.Sprintf(`%s/cli_dbrestore_%d.db`, sqliteTestDir, time.Now().UnixNano())
+	db, tearDown := test.Setup(t, dbPath, "_foreign_keys=on")
+	defer tearDown()
+
+	test.Init(t, db, []test.Statement{
+		{SQL: `CREATE TABLE node (
+	id INT,
+	parent_id INT,
+	PRIMARY KEY (id),
+	FOREIGN KEY (parent_id) REFERENCES node (id));`},
+		{SQL: `INSERT INTO node (id, parent_id) VALUES (1, NULL), (2, 1);`},
+	})
+
+	input := dbrestore.DBRestoreInput{
+		"node": []dml.Row{
+			{"id": json.Number("3"), "parent_id": json.Number("4")},
+			{"id": json.Number("4"), "parent_id": nil},
+		},
+	}
+
+	// sut
+	err := dbrestore.DBRestoreFunc(context.Background(), "sqlite3", dbPath+"?_foreign_keys=on", nil, io.Discard, input)
+	if err != nil {
+		t.Fatalf(`fail to run: %v`, err)
+	}
+
+	type nodeRow struct {
+		Id        int64
+		Parent_id sql.NullInt64
+	}
+	got := test.ListRows[nodeRow](t, db, `node`)
+	want := []nodeRow{{Id: 3, Parent_id: sql.NullInt64{Valid: true, Int64: 4}}, {Id: 4}}
+	if len(got) != len(want) {
+		t.Fatalf("row count not match\n  got  = %v\n  want = %v", len(got), len(want))
+	}
+	for i, want := range want {
+		if *got[i] != want {
+			t.Errorf("node[%d] not match\n  got  = %v\n  want = %v", i, *got[i], want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package dbsnapshot
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	gotaface_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3"
+	sqlite3_schema "github.com/Jumpaku/gotaface/old/sqlite3/ddl/schema"
+	sqlite3_dump "github.com/Jumpaku/gotaface/old/sqlite3/dml/dump"
+)
+
+type DBSnapshotOutput = map[string]dml.Rows
+
+func DBSnapshotFunc(ctx context.Context, driver string, dataSource string, schemaReader io.Reader, schemaWriter io.Writer) (DBSnapshotOutput, error) {
+	db, err := sql.Open(driver, dataSource)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to open SQLite3 %s: %w`, dataSource, err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf(`fail to begin transaction %s: %w`, dataSource, err)
+	}
+	defer tx.Rollback()
+
+	s, err := sqlite3_schema.FetchSchemaOrUseCache(ctx, schemaReader, schemaWriter, tx)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to fetch schema or use cache: %w`, err)
+	}
+
+	dumper := sqlite3_dump.NewDumper(tx, s)
+
+	output := DBSnapshotOutput{}
+	for _, table := range s.TablesVal {
+		dumpedRows, err := dumper.Dump(ctx, table.Name())
+		if err != nil {
+			return nil, fmt.Errorf(`fail to dump rows in table %s: %w`, table.Name(), err)
+		}
+
+		rows := dml.Rows{}
+		for _, dumpedRow := range dumpedRows {
+			row := dml.Row{}
+			for column, value := range dumpedRow {
+				row[column], err = gotaface_sqlite3.FromDBValue(value)
+				if err != nil {
+					return nil, fmt.Errorf(`fail to convert DB value: %v: %w`, value, err)
+				}
+			}
+			rows = append(rows, row)
+		}
+
+		output[table.Name()] = rows
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf(`fail to commit transaction: %w`, err)
+	}
+	return output, nil
+}
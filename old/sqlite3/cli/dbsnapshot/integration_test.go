@@ -0,0 +1,139 @@
+package dbsnapshot_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/sqlite3/cli/dbsnapshot"
+	"github.com/Jumpaku/gotaface/old/sqlite3/test"
+	"golang.org/x/exp/slices"
+)
+
+var testInitStmt = []test.Statement{
+	{SQL: `CREATE TABLE t0 (
+	col_int INT,
+	col_text TEXT,
+	col_real REAL,
+	col_blob BLOB,
+	PRIMARY KEY (col_int));`},
+	{SQL: `CREATE TABLE t1 (
+	id INT,
+	t0_id INT,
+	PRIMARY KEY (id),
+	FOREIGN KEY (t0_id) REFERENCES t0 (col_int));`},
+	{SQL: `INSERT INTO t0 (col_int, col_text, col_real, col_blob) VALUES (3, "jkl", 2.00, X'3231316a6b6c'), (4, NULL, NULL, NULL);`},
+	{SQL: `INSERT INTO t1 (id, t0_id) VALUES (2, 3), (1, 4);`},
+}
+
+var wantOutput = dbsnapshot.DBSnapshotOutput{
+	"t0": []dml.Row{
+		{`col_int`: int64(3), `col_text`: `jkl`, `col_real`: float64(2.00), `col_blob`: []byte(`211jkl`)},
+		{`col_int`: int64(4), `col_text`: nil, `col_real`: nil, `col_blob`: []byte(nil)},
+	},
+	"t1": []dml.Row{
+		{"id": int64(1), "t0_id": int64(4)},
+		{"id": int64(2), "t0_id": int64(3)},
+	},
+}
+
+func getEnvSQLiteTestDirOrSkip(t *testing.T) string {
+	t.Helper()
+
+	sqliteTestDir := os.Getenv(test.EnvSQLiteTestDir)
+	if sqliteTestDir == "" {
+		t.Skipf(`skipped because environment variable %s is not set`, test.EnvSQLiteTestDir)
+	}
+
+	return sqliteTestDir
+}
+
+func TestDBSnapshotFunc(t *testing.T) {
+	sqliteTestDir := getEnvSQLiteTestDirOrSkip(t)
+
+	dbPath := fmt.Sprintf(`%s/cli_dbsnapshot_%d.db`, sqliteTestDir, time.Now().UnixNano())
+	db, tearDown := test.Setup(t, dbPath, "")
+	defer tearDown()
+
+	test.Init(t, db, testInitStmt)
+
+	var schemaReader io.Reader = nil
+	var schemaWriter io.Writer = io.Discard
+
+	// sut
+	got, err := dbsnapshot.DBSnapshotFunc(context.Background(), "sqlite3", dbPath, schemaReader, schemaWriter)
+	if err != nil {
+		t.Errorf(`fail to run: %v`, err)
+	}
+
+	want := wantOutput
+
+	if len(got) != len(want) {
+		t.Errorf("table count not match\n  got = %v\n  want = %v", len(got), len(want))
+	}
+	for table, wantRows := range want {
+		gotRows, ok := got[table]
+		if !ok {
+			t.Errorf("table=%s: got value does not have table", table)
+		}
+		if len(gotRows) != len(wantRows) {
+			t.Errorf("row count not match\n  got = %v\n  want = %v", len(gotRows), len(wantRows))
+		}
+		for i, wantRow := range wantRows {
+			gotRow := gotRows[i]
+			if len(gotRow) != len(wantRow) {
+				t.Errorf("table=%s, rows[%d], column count not match\n  got = %v\n  want = %v", table, i, len(gotRow), len(wantRow))
+			}
+			for column, wantColumnValue := range wantRow {
+				gotColumnValue, ok := gotRow[column]
+				if !ok {
+					t.Errorf("table=%s, rows[%d], column=%s: got value does not have column", table, i, column)
+				}
+				if !equals(gotColumnValue, wantColumnValue) {
+					t.Errorf("table=%s, rows[%d], column=%s: not equals:\n  gotVal  = %#v\n  wantVal = %#v", table, i, column, gotColumnValue, wantColumnValue)
+				}
+			}
+		}
+	}
+}
+
+func equals(got any, want any) bool {
+	switch want := want.(type) {
+	default:
+		return got == want
+	case []byte:
+		got, ok := got.([]byte)
+		return ok && (got == nil) == (want == nil) && slices.Equal(got, want)
+	}
+}
+
+func TestDBSnapshotFunc_SelfReference(t *testing.T) {
+	sqliteTestDir := getEnvSQLiteTestDirOrSkip(t)
+
+	dbPath := fmt.Sprintf(`%s/cli_dbsnapshot_%d.db`, sqliteTestDir, time.Now().UnixNano())
+	db, tearDown := test.Setup(t, dbPath, "")
+	defer tearDown()
+
+	test.Init(t, db, []test.Statement{
+		{SQL: `CREATE TABLE node (
+	id INT,
+	parent_id INT,
+	PRIMARY KEY (id),
+	FOREIGN KEY (parent_id) REFERENCES node (id));`},
+		{SQL: `INSERT INTO node (id, parent_id) VALUES (1, NULL), (2, 1);`},
+	})
+
+	// sut
+	got, err := dbsnapshot.DBSnapshotFunc(context.Background(), "sqlite3", dbPath, nil, io.Discard)
+	if err != nil {
+		t.Fatalf(`fail to run: %v`, err)
+	}
+
+	if len(got["node"]) != 2 {
+		t.Errorf("row count not match\n  got = %v\n  want = %v", len(got["node"]), 2)
+	}
+}
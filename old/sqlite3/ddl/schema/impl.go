@@ -267,6 +267,57 @@ ORDER BY m.name, f."table"
 	return references, nil
 }
 
+// FetchForeignKeys fetches the foreign keys of the tables with the nullability of their columns.
+func FetchForeignKeys(ctx context.Context, queryer dbsql.Queryer) ([]schema.ForeignKey, error) {
+	type foreignKeyRow struct {
+		TableName           string
+		ForeignKeyID        int
+		ColumnName          string
+		ReferencedTableName string
+		IsNotNull           int
+	}
+
+	rows, err := queryer.QueryContext(ctx, `
+SELECT
+    m.name AS TableName,
+    f.id AS ForeignKeyID,
+    f."from" AS ColumnName,
+    f."table" AS ReferencedTableName,
+    c."notnull" AS IsNotNull
+FROM sqlite_master AS m
+JOIN pragma_foreign_key_list(m.name) AS f
+JOIN pragma_table_info(m.name) AS c ON c.name = f."from"
+WHERE m.type = 'table'
+ORDER BY m.name, f.id, f.seq
+`)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to get foreign keys: %w`, err)
+	}
+	defer rows.Close()
+
+	scannedRows, err := dbsql.ScanRowsStruct[foreignKeyRow](rows)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to scan rows: %w`, err)
+	}
+
+	foreignKeys := []schema.ForeignKey{}
+	for i, row := range scannedRows {
+		if i == 0 || scannedRows[i-1].TableName != row.TableName || scannedRows[i-1].ForeignKeyID != row.ForeignKeyID {
+			foreignKeys = append(foreignKeys, schema.ForeignKey{
+				Table:           row.TableName,
+				ReferencedTable: row.ReferencedTableName,
+				Nullable:        true,
+			})
+		}
+
+		fk := &foreignKeys[len(foreignKeys)-1]
+		fk.Columns = append(fk.Columns, row.ColumnName)
+		fk.Nullable = fk.Nullable && row.IsNotNull == 0
+	}
+
+	return foreignKeys, nil
+}
+
 func FetchSchemaOrUseCache(ctx context.Context, schemaReader io.Reader, schemaWriter io.Writer, queryer dbsql.Queryer) (*Schema, error) {
 	if schemaReader != nil {
 		schema := new(Schema)
@@ -151,6 +151,55 @@ CREATE TABLE t6 (
 	}
 }
 
+func TestFetchForeignKeys(t *testing.T) {
+	db, tearDown := test.Setup(t, "", "")
+	defer tearDown()
+
+	ctx := context.Background()
+	test.Init(t, db, []test.Statement{{SQL: `
+CREATE TABLE User (
+	id INT NOT NULL,
+	team_id INT,
+	PRIMARY KEY (id),
+	FOREIGN KEY (team_id) REFERENCES Team (id));
+
+CREATE TABLE Team (
+	id INT NOT NULL,
+	owner_id INT NOT NULL,
+	PRIMARY KEY (id),
+	FOREIGN KEY (owner_id) REFERENCES User (id));
+
+CREATE TABLE Node (
+	id1 INT NOT NULL,
+	id2 INT NOT NULL,
+	parent_id1 INT,
+	parent_id2 INT NOT NULL,
+	PRIMARY KEY (id1, id2),
+	FOREIGN KEY (parent_id1, parent_id2) REFERENCES Node (id1, id2));
+`}})
+
+	got, err := schema_impl.FetchForeignKeys(ctx, db)
+	if err != nil {
+		t.Fatalf("fail to fetch foreign keys: %v", err)
+	}
+
+	want := []schema.ForeignKey{
+		{Table: "Node", Columns: []string{"parent_id1", "parent_id2"}, ReferencedTable: "Node", Nullable: false},
+		{Table: "Team", Columns: []string{"owner_id"}, ReferencedTable: "User", Nullable: false},
+		{Table: "User", Columns: []string{"team_id"}, ReferencedTable: "Team", Nullable: true},
+	}
+	if !slices.EqualFunc(got, want, equalsForeignKey) {
+		t.Errorf("ForeignKeys not match\n  got = %v\n  want = %v", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func equalsForeignKey(got schema.ForeignKey, want schema.ForeignKey) bool {
+	return got.Table == want.Table &&
+		slices.Equal(got.Columns, want.Columns) &&
+		got.ReferencedTable == want.ReferencedTable &&
+		got.Nullable == want.Nullable
+}
+
 func equalsReferences(got [][]int, want [][]int) bool {
 	if len(got) != len(want) {
 		return false
@@ -3,6 +3,7 @@ package delete
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/Jumpaku/gotaface/old/dbsql"
 	"github.com/Jumpaku/gotaface/old/dml/delete"
@@ -13,6 +14,7 @@ type deleter struct {
 }
 
 var _ delete.Deleter = deleter{}
+var _ delete.Clearer = deleter{}
 
 func NewDeleter(execer dbsql.Execer) deleter {
 	return deleter{execer: execer}
@@ -25,3 +27,15 @@ func (deleter deleter) Delete(ctx context.Context, table string) error {
 	}
 	return nil
 }
+
+func (deleter deleter) Clear(ctx context.Context, table string, columns []string) error {
+	sets := []string{}
+	for _, column := range columns {
+		sets = append(sets, column+`=NULL`)
+	}
+	_, err := deleter.execer.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET %s`, table, strings.Join(sets, ",")))
+	if err != nil {
+		return fmt.Errorf(`fail to clear columns: %w`, err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package delete_test
 
 import (
 	"context"
+	"database/sql"
 	"os"
 	"testing"
 
@@ -50,3 +51,42 @@ VALUES
 		t.Errorf("rows are remaining: %v", err)
 	}
 }
+
+func TestDeleter_ClearColumns(t *testing.T) {
+	db, tearDown := test.Setup(t, "", "")
+	defer tearDown()
+
+	ctx := context.Background()
+	test.Init(t, db, []test.Statement{{
+		SQL: `
+CREATE TABLE t (
+	id INT,
+	col_integer INTEGER,
+	col_text TEXT,
+	PRIMARY KEY (id));
+
+INSERT INTO t (id, col_integer, col_text) VALUES (1, 2, "abc"), (2, 3, "def");
+`,
+	}})
+
+	sut := delete.NewDeleter(db)
+	err := sut.Clear(ctx, `t`, []string{`col_integer`})
+	if err != nil {
+		t.Errorf("fail to clear columns: %v", err)
+	}
+
+	type Row struct {
+		Id          sql.NullInt64
+		Col_integer sql.NullInt64
+		Col_text    sql.NullString
+	}
+	rows := test.ListRows[Row](t, db, `t`)
+	if len(rows) != 2 {
+		t.Fatalf("rows are not remaining: %v", rows)
+	}
+	for _, row := range rows {
+		if row.Col_integer.Valid || !row.Col_text.Valid {
+			t.Errorf("columns are not cleared: %#v", *row)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/Jumpaku/gotaface/old/dbsql"
 	"github.com/Jumpaku/gotaface/old/dml"
 	"github.com/Jumpaku/gotaface/old/dml/insert"
+	gotaface_sqlite3 "github.com/Jumpaku/gotaface/old/sqlite3"
+	sqlite3_schema "github.com/Jumpaku/gotaface/old/sqlite3/ddl/schema"
 )
 
 type inserter struct {
@@ -54,3 +56,30 @@ func (inserter inserter) Insert(ctx context.Context, table string, rows dml.Rows
 	}
 	return nil
 }
+
+// ToDBRows converts values in rows into values of the column types of the table in the schema.
+func ToDBRows(schema *sqlite3_schema.Schema, table string, rows dml.Rows) (dml.Rows, error) {
+	columnMap := map[string]sqlite3_schema.Column{}
+	for _, t := range schema.TablesVal {
+		if t.Name() != table {
+			continue
+		}
+		for _, column := range t.ColumnsVal {
+			columnMap[column.Name()] = column
+		}
+	}
+
+	dbRows := dml.Rows{}
+	for _, row := range rows {
+		dbRow := dml.Row{}
+		for column, value := range row {
+			var err error
+			dbRow[column], err = gotaface_sqlite3.ToDBValue(columnMap[column].Type(), value)
+			if err != nil {
+				return nil, fmt.Errorf(`fail to convert value to DB value: %v: %w`, value, err)
+			}
+		}
+		dbRows = append(dbRows, dbRow)
+	}
+	return dbRows, nil
+}
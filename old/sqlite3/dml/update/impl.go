@@ -0,0 +1,55 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Jumpaku/gotaface/old/dbsql"
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/update"
+	"golang.org/x/exp/slices"
+)
+
+type updater struct {
+	execer dbsql.Execer
+}
+
+var _ update.Updater = updater{}
+
+func NewUpdater(execer dbsql.Execer) updater {
+	return updater{execer: execer}
+}
+
+func (updater updater) Update(ctx context.Context, table string, key []string, rows dml.Rows) error {
+	for _, row := range rows {
+		columns := []string{}
+		for column := range row {
+			if !slices.Contains(key, column) {
+				columns = append(columns, column)
+			}
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		slices.Sort(columns)
+
+		sets := []string{}
+		params := []any{}
+		for _, column := range columns {
+			sets = append(sets, column+`=?`)
+			params = append(params, row[column])
+		}
+		conditions := []string{}
+		for _, column := range key {
+			conditions = append(conditions, column+`=?`)
+			params = append(params, row[column])
+		}
+
+		stmt := fmt.Sprintf(`UPDATE %s SET %s WHERE %s`, table, strings.Join(sets, ","), strings.Join(conditions, " AND "))
+		if _, err := updater.execer.ExecContext(ctx, stmt, params...); err != nil {
+			return fmt.Errorf(`fail to update row by %#v [%#v]: %w`, stmt, params, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package update_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/sqlite3/dml/update"
+	"github.com/Jumpaku/gotaface/old/sqlite3/test"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+}
+
+func TestUpdater_Update(t *testing.T) {
+	db, tearDown := test.Setup(t, "", "")
+	defer tearDown()
+
+	test.Init(t, db, []test.Statement{{SQL: `
+CREATE TABLE t (
+	id1 INT,
+	id2 INT,
+	col_integer INTEGER,
+	col_text TEXT,
+	PRIMARY KEY (id1, id2));
+INSERT INTO t (id1, id2, col_integer, col_text) VALUES (1, 1, NULL, 'abc'), (1, 2, NULL, 'def');
+`}})
+	input := dml.Rows{
+		{
+			`id1`:         sql.NullInt64{Valid: true, Int64: 1},
+			`id2`:         sql.NullInt64{Valid: true, Int64: 2},
+			`col_integer`: sql.NullInt64{Valid: true, Int64: 3},
+		},
+	}
+
+	sut := update.NewUpdater(db)
+
+	err := sut.Update(context.Background(), `t`, []string{`id1`, `id2`}, input)
+	if err != nil {
+		t.Errorf("fail to update rows: %v", err)
+	}
+
+	type Row struct {
+		Id1         sql.NullInt64
+		Id2         sql.NullInt64
+		Col_integer sql.NullInt64
+		Col_text    sql.NullString
+	}
+	want := []Row{
+		{
+			Id1:         sql.NullInt64{Valid: true, Int64: 1},
+			Id2:         sql.NullInt64{Valid: true, Int64: 1},
+			Col_integer: sql.NullInt64{},
+			Col_text:    sql.NullString{Valid: true, String: `abc`},
+		},
+		{
+			Id1:         sql.NullInt64{Valid: true, Int64: 1},
+			Id2:         sql.NullInt64{Valid: true, Int64: 2},
+			Col_integer: sql.NullInt64{Valid: true, Int64: 3},
+			Col_text:    sql.NullString{Valid: true, String: `def`},
+		},
+	}
+	for i, want := range want {
+		found := test.FindRow[Row](t, db, `t`, map[string]any{"id1": want.Id1.Int64, "id2": want.Id2.Int64})
+		if found == nil {
+			t.Fatalf("row not found")
+		}
+		if *found != want {
+			t.Errorf("i = %d\n found = %#v\n  want = %#v", i, *found, want)
+		}
+	}
+}
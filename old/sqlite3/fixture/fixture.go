@@ -0,0 +1,163 @@
+package fixture
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/dbsql"
+	"github.com/Jumpaku/gotaface/old/ddl/schema"
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/delete"
+	"github.com/Jumpaku/gotaface/old/dml/fixture"
+	"github.com/Jumpaku/gotaface/old/dml/insert"
+	sqlite3_schema "github.com/Jumpaku/gotaface/old/sqlite3/ddl/schema"
+	sqlite3_delete "github.com/Jumpaku/gotaface/old/sqlite3/dml/delete"
+	sqlite3_dump "github.com/Jumpaku/gotaface/old/sqlite3/dml/dump"
+	sqlite3_insert "github.com/Jumpaku/gotaface/old/sqlite3/dml/insert"
+	sqlite3_update "github.com/Jumpaku/gotaface/old/sqlite3/dml/update"
+	"golang.org/x/exp/slices"
+)
+
+type DB interface {
+	dbsql.Execer
+	dbsql.Queryer
+}
+
+// Load replaces rows in the tables of the fixture file with the rows in the file, and registers a cleanup function to restore the original rows after the test.
+// The fixture file must follow the input format of gf-dbinsert in JSON, or in YAML if its extension is .yaml or .yml.
+// Rows are deleted from and inserted into the tables in the order of references between tables regardless of the order in the file, tolerating cycles of references as gf-dbinsert with -order references does.
+// Rows in the tables referencing the tables of the fixture file, directly or indirectly, are also deleted and restored after the test.
+// Rows are deleted and inserted in a transaction if db can begin a transaction.
+func Load(ctx context.Context, t *testing.T, db DB, path string) {
+	t.Helper()
+
+	tables, err := fixture.Read(path)
+	if err != nil {
+		t.Fatalf(`fail to read fixture: %v`, err)
+	}
+	names, rows := fixture.Merge(tables)
+
+	var s *sqlite3_schema.Schema
+	var foreignKeys []schema.ForeignKey
+	var targets []string
+	var snapshot map[string]dml.Rows
+	err = transact(ctx, db, func(tx DB) error {
+		s, err = sqlite3_schema.FetchSchema(ctx, tx)
+		if err != nil {
+			return fmt.Errorf(`fail to fetch schema: %w`, err)
+		}
+		foreignKeys, err = sqlite3_schema.FetchForeignKeys(ctx, tx)
+		if err != nil {
+			return fmt.Errorf(`fail to fetch foreign keys: %w`, err)
+		}
+		targets, err = referencingTables(s, names)
+		if err != nil {
+			return fmt.Errorf(`fail to find tables: %w`, err)
+		}
+
+		dumper := sqlite3_dump.NewDumper(tx, s)
+		snapshot = map[string]dml.Rows{}
+		for _, table := range targets {
+			snapshot[table], err = dumper.Dump(ctx, table)
+			if err != nil {
+				return fmt.Errorf(`fail to dump rows in table %s: %w`, table, err)
+			}
+		}
+
+		dbRows := map[string]dml.Rows{}
+		for table, tableRows := range rows {
+			dbRows[table], err = sqlite3_insert.ToDBRows(s, table, tableRows)
+			if err != nil {
+				return fmt.Errorf(`fail to convert rows in table %s: %w`, table, err)
+			}
+		}
+		return replace(ctx, tx, s, foreignKeys, targets, dbRows)
+	})
+	if err != nil {
+		t.Fatalf(`fail to load fixture: %v`, err)
+	}
+
+	t.Cleanup(func() {
+		ctx := context.Background()
+		err := transact(ctx, db, func(tx DB) error {
+			return replace(ctx, tx, s, foreignKeys, targets, snapshot)
+		})
+		if err != nil {
+			t.Errorf(`fail to restore rows: %v`, err)
+		}
+	})
+}
+
+type beginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// transact calls f with a transaction if db can begin a transaction, or with db otherwise, for example if db is a transaction.
+func transact(ctx context.Context, db DB, f func(tx DB) error) error {
+	b, ok := db.(beginner)
+	if !ok {
+		return f(db)
+	}
+
+	tx, err := b.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf(`fail to begin transaction: %w`, err)
+	}
+	defer tx.Rollback()
+
+	if err := f(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf(`fail to commit transaction: %w`, err)
+	}
+	return nil
+}
+
+// replace deletes rows in the tables and inserts rows, which are already converted into DB values.
+func replace(ctx context.Context, db DB, s *sqlite3_schema.Schema, foreignKeys []schema.ForeignKey, tables []string, rows map[string]dml.Rows) error {
+	deleter := sqlite3_delete.NewDeleter(db)
+	if err := delete.DeleteDeferred(ctx, s, foreignKeys, deleter, deleter, tables); err != nil {
+		return fmt.Errorf(`fail to delete rows: %w`, err)
+	}
+
+	toDBRows := func(table string, rows dml.Rows) (dml.Rows, error) { return rows, nil }
+	if err := insert.InsertDeferred(ctx, s, foreignKeys, sqlite3_insert.NewInserter(db), sqlite3_update.NewUpdater(db), toDBRows, rows, nil); err != nil {
+		return fmt.Errorf(`fail to insert rows: %w`, err)
+	}
+	return nil
+}
+
+// referencingTables returns the names and the names of the tables referencing them directly or indirectly in the order of the tables in the schema.
+func referencingTables(s *sqlite3_schema.Schema, names []string) ([]string, error) {
+	targets := make([]bool, len(s.TablesVal))
+	for _, name := range names {
+		index := slices.IndexFunc(s.TablesVal, func(t sqlite3_schema.Table) bool { return t.Name() == name })
+		if index < 0 {
+			return nil, fmt.Errorf(`table %s not found`, name)
+		}
+		targets[index] = true
+	}
+
+	for found := true; found; {
+		found = false
+		for from, tos := range s.References() {
+			if targets[from] {
+				continue
+			}
+			if slices.ContainsFunc(tos, func(to int) bool { return targets[to] }) {
+				targets[from], found = true, true
+			}
+		}
+	}
+
+	referencing := []string{}
+	for index, table := range s.TablesVal {
+		if targets[index] {
+			referencing = append(referencing, table.Name())
+		}
+	}
+	return referencing, nil
+}
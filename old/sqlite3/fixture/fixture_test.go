@@ -0,0 +1,95 @@
+package fixture_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/sqlite3/fixture"
+	"github.com/Jumpaku/gotaface/old/sqlite3/test"
+)
+
+func TestLoad(t *testing.T) {
+	db, tearDown := test.Setup(t, "", "")
+	defer tearDown()
+
+	test.Init(t, db, []test.Statement{{
+		SQL: `
+PRAGMA foreign_keys = ON;
+
+CREATE TABLE User (
+	id INT PRIMARY KEY,
+	name TEXT);
+
+CREATE TABLE Comment (
+	id INT PRIMARY KEY,
+	userId INT,
+	content TEXT,
+	FOREIGN KEY (userId) REFERENCES User (id));
+
+INSERT INTO User (id, name) VALUES (1, "Jumpaku"), (3, "Carol");
+INSERT INTO Comment (id, userId, content) VALUES (1, 3, "Bye");
+`}})
+
+	t.Run(`load`, func(t *testing.T) {
+		fixture.Load(context.Background(), t, db, `testdata/users.yaml`)
+
+		test.AssertTableRows(t, db, `User`, dml.Rows{
+			{`id`: 1, `name`: `Alice`},
+			{`id`: 2, `name`: `Bob`},
+		})
+		test.AssertTableRows(t, db, `Comment`, dml.Rows{
+			{`id`: 1, `userId`: 2, `content`: `Hello`},
+		})
+	})
+
+	test.AssertTableRows(t, db, `User`, dml.Rows{
+		{`id`: 1, `name`: `Jumpaku`},
+		{`id`: 3, `name`: `Carol`},
+	})
+	test.AssertTableRows(t, db, `Comment`, dml.Rows{
+		{`id`: 1, `userId`: 3, `content`: `Bye`},
+	})
+}
+
+func TestLoad_References(t *testing.T) {
+	db, tearDown := test.Setup(t, "", "")
+	defer tearDown()
+
+	test.Init(t, db, []test.Statement{{
+		SQL: `
+PRAGMA foreign_keys = ON;
+
+CREATE TABLE Comment (
+	id INT PRIMARY KEY,
+	replyTo INT,
+	content TEXT,
+	FOREIGN KEY (replyTo) REFERENCES Comment (id));
+
+CREATE TABLE Like (
+	id INT PRIMARY KEY,
+	commentId INT,
+	FOREIGN KEY (commentId) REFERENCES Comment (id));
+
+INSERT INTO Comment (id, replyTo, content) VALUES (1, NULL, "Bye"), (3, 1, "See you");
+INSERT INTO Like (id, commentId) VALUES (1, 3);
+`}})
+
+	t.Run(`load`, func(t *testing.T) {
+		fixture.Load(context.Background(), t, db, `testdata/comments.yaml`)
+
+		test.AssertTableRows(t, db, `Comment`, dml.Rows{
+			{`id`: 1, `replyTo`: 2, `content`: `Hello`},
+			{`id`: 2, `replyTo`: nil, `content`: `Hi`},
+		})
+		test.AssertTableRows(t, db, `Like`, dml.Rows{})
+	})
+
+	test.AssertTableRows(t, db, `Comment`, dml.Rows{
+		{`id`: 1, `replyTo`: nil, `content`: `Bye`},
+		{`id`: 3, `replyTo`: 1, `content`: `See you`},
+	})
+	test.AssertTableRows(t, db, `Like`, dml.Rows{
+		{`id`: 1, `commentId`: 3},
+	})
+}
@@ -0,0 +1,24 @@
+package sqlite3
+
+import (
+	"strings"
+
+	"github.com/Jumpaku/gotaface/old/json/jsonschema"
+)
+
+// ColumnJSONSchema returns a JSON Schema of values which ToDBValue accepts for a column of the column type.
+func ColumnJSONSchema(columnType string) *jsonschema.Schema {
+	lower := strings.ToLower(columnType)
+	switch {
+	case strings.Contains(lower, "int"):
+		return &jsonschema.Schema{Type: jsonschema.Types{jsonschema.TypeInteger, jsonschema.TypeBoolean, jsonschema.TypeNull}}
+	case strings.Contains(lower, "char"), strings.Contains(lower, "clob"), strings.Contains(lower, "text"):
+		return &jsonschema.Schema{Type: jsonschema.Types{jsonschema.TypeString, jsonschema.TypeObject, jsonschema.TypeArray, jsonschema.TypeNull}}
+	case strings.Contains(lower, "blob"), lower == "":
+		return &jsonschema.Schema{Type: jsonschema.Types{jsonschema.TypeString, jsonschema.TypeNull}, Description: `base64-encoded bytes`}
+	case strings.Contains(lower, "real"), strings.Contains(lower, "floa"), strings.Contains(lower, "doub"):
+		return &jsonschema.Schema{Type: jsonschema.Types{jsonschema.TypeNumber, jsonschema.TypeNull}}
+	default:
+		return &jsonschema.Schema{Type: jsonschema.Types{jsonschema.TypeString, jsonschema.TypeNull}}
+	}
+}
@@ -0,0 +1,42 @@
+package sqlite3_test
+
+import (
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/json/wrap"
+	"github.com/Jumpaku/gotaface/old/sqlite3"
+)
+
+func TestColumnJSONSchema(t *testing.T) {
+	type testCase struct {
+		columnType string
+		value      string
+		isErr      bool
+	}
+	testCases := []testCase{
+		{columnType: `INTEGER`, value: `null`},
+		{columnType: `INTEGER`, value: `-123`},
+		{columnType: `INT`, value: `true`},
+		{columnType: `INTEGER`, value: `1.5`, isErr: true},
+		{columnType: `INTEGER`, value: `"1"`, isErr: true},
+		{columnType: `TEXT`, value: `"abc"`},
+		{columnType: `VARCHAR(10)`, value: `{"a":[1]}`},
+		{columnType: `TEXT`, value: `1`, isErr: true},
+		{columnType: `REAL`, value: `-0.25`},
+		{columnType: `DOUBLE`, value: `"0.25"`, isErr: true},
+		{columnType: `BLOB`, value: `"MjExamts"`},
+		{columnType: `BLOB`, value: `1`, isErr: true},
+		{columnType: `NUMERIC`, value: `"1.5"`},
+	}
+
+	for i, testCase := range testCases {
+		v := wrap.Null()
+		if err := v.UnmarshalJSON([]byte(testCase.value)); err != nil {
+			t.Fatalf("%d: fail to parse value: %v", i, err)
+		}
+		err := sqlite3.ColumnJSONSchema(testCase.columnType).Validate(v)
+		if (err != nil) != testCase.isErr {
+			t.Errorf("%d: unexpected error state for %s %s: %v", i, testCase.columnType, testCase.value, err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package sqlite3
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// ToLiteral returns a SQLite3 literal representing a value scanned from a column.
+func ToLiteral(src any) (string, error) {
+	switch src := src.(type) {
+	default:
+		return "", fmt.Errorf(`unsupported DB value: %v`, spew.Sdump(src))
+	case nil:
+		return `NULL`, nil
+	case sql.NullInt64:
+		if !src.Valid {
+			return `NULL`, nil
+		}
+		return strconv.FormatInt(src.Int64, 10), nil
+	case sql.NullString:
+		if !src.Valid {
+			return `NULL`, nil
+		}
+		return `'` + strings.ReplaceAll(src.String, `'`, `''`) + `'`, nil
+	case sql.NullFloat64:
+		if !src.Valid {
+			return `NULL`, nil
+		}
+		switch f := src.Float64; {
+		case math.IsNaN(f):
+			// SQLite3 stores NaN as NULL.
+			return `NULL`, nil
+		case math.IsInf(f, 1):
+			return `9e999`, nil
+		case math.IsInf(f, -1):
+			return `-9e999`, nil
+		default:
+			s := strconv.FormatFloat(f, 'g', -1, 64)
+			if !strings.ContainsAny(s, `.e`) {
+				s += `.0`
+			}
+			return s, nil
+		}
+	case []byte:
+		if src == nil {
+			return `NULL`, nil
+		}
+		return `X'` + strings.ToUpper(hex.EncodeToString(src)) + `'`, nil
+	}
+}
+
+// QuoteIdentifier returns a SQLite3 quoted identifier representing a name of a table or a column.
+func QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,62 @@
+package sqlite3_test
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/sqlite3"
+)
+
+func TestToLiteral(t *testing.T) {
+	type testCase struct {
+		src   any
+		want  string
+		isErr bool
+	}
+	testCases := []testCase{
+		{src: nil, want: `NULL`},
+		{src: sql.NullInt64{}, want: `NULL`},
+		{src: sql.NullInt64{Valid: true, Int64: -123}, want: `-123`},
+		{src: sql.NullString{}, want: `NULL`},
+		{src: sql.NullString{Valid: true, String: "it's\n\"ok\""}, want: "'it''s\n\"ok\"'"},
+		{src: sql.NullFloat64{}, want: `NULL`},
+		{src: sql.NullFloat64{Valid: true, Float64: -0.25}, want: `-0.25`},
+		{src: sql.NullFloat64{Valid: true, Float64: 2}, want: `2.0`},
+		{src: sql.NullFloat64{Valid: true, Float64: math.NaN()}, want: `NULL`},
+		{src: sql.NullFloat64{Valid: true, Float64: math.Inf(1)}, want: `9e999`},
+		{src: []byte(nil), want: `NULL`},
+		{src: []byte("211jkl"), want: `X'3231316A6B6C'`},
+		{src: int64(1), isErr: true},
+	}
+
+	for i, testCase := range testCases {
+		got, err := sqlite3.ToLiteral(testCase.src)
+		if (err != nil) != testCase.isErr {
+			t.Errorf("%d: unexpected error state: %v", i, err)
+			continue
+		}
+		if got != testCase.want {
+			t.Errorf("%d: got != want\n  got  = %s\n  want = %s", i, got, testCase.want)
+		}
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	type testCase struct {
+		name string
+		want string
+	}
+	testCases := []testCase{
+		{name: `User`, want: `"User"`},
+		{name: `Order Item`, want: `"Order Item"`},
+		{name: `a"b`, want: `"a""b"`},
+	}
+
+	for i, testCase := range testCases {
+		got := sqlite3.QuoteIdentifier(testCase.name)
+		if got != testCase.want {
+			t.Errorf("%d: got != want\n  got  = %s\n  want = %s", i, got, testCase.want)
+		}
+	}
+}
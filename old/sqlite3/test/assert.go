@@ -0,0 +1,53 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/dbsql"
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/sqlite3"
+	sqlite3_schema "github.com/Jumpaku/gotaface/old/sqlite3/ddl/schema"
+	"github.com/Jumpaku/gotaface/old/sqlite3/dml/dump"
+	"github.com/Jumpaku/gotaface/old/test/assert"
+)
+
+// AssertTableRows asserts that the table contains exactly the expected rows, which are matched by the primary key.
+// The expected values are converted by the column types in the schema, e.g., 1 for an INT column and "AQ==" for a BLOB column, and columns missing in the expected rows are expected to be NULL.
+func AssertTableRows(t testing.TB, queryer dbsql.Queryer, table string, expect dml.Rows) {
+	t.Helper()
+
+	schema, err := sqlite3_schema.FetchSchema(context.Background(), queryer)
+	if err != nil {
+		t.Fatalf(`fail to fetch schema: %v`, err)
+		return
+	}
+	actual, err := dumpTable(queryer, schema, table)
+	if err != nil {
+		t.Fatalf(`fail to dump table %s: %v`, table, err)
+		return
+	}
+
+	assert.SchemaTableRows(t, schema, table, sqlite3.ToDBValue, sqlite3.FromDBValue, actual, expect)
+}
+
+// dumpTable returns rows in the table ordered by the primary key, where values are converted into plain Go values.
+func dumpTable(queryer dbsql.Queryer, schema *sqlite3_schema.Schema, table string) (dml.Rows, error) {
+	dumped, err := dump.NewDumper(queryer, schema).Dump(context.Background(), table)
+	if err != nil {
+		return nil, err
+	}
+	rows := dml.Rows{}
+	for _, dumpedRow := range dumped {
+		row := dml.Row{}
+		for column, value := range dumpedRow {
+			row[column], err = sqlite3.FromDBValue(value)
+			if err != nil {
+				return nil, fmt.Errorf(`fail to convert value in column %s: %w`, column, err)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
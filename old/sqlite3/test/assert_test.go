@@ -0,0 +1,111 @@
+package test_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/sqlite3/test"
+)
+
+func TestAssertTableRows(t *testing.T) {
+	db, tearDown := test.Setup(t, "", "")
+	defer tearDown()
+
+	test.Init(t, db, []test.Statement{{
+		SQL: `
+CREATE TABLE t (
+	id1 INT,
+	id2 TEXT,
+	col_integer INTEGER,
+	col_real REAL,
+	col_blob BLOB,
+	PRIMARY KEY (id1, id2));
+
+INSERT INTO t (id1, id2, col_integer, col_real, col_blob)
+VALUES
+	(2, "a", NULL, 1.5, X'00'),
+	(1, "b", 1, NULL, NULL),
+	(1, "a", 2, 2.5, X'0102');
+`}})
+
+	test.AssertTableRows(t, db, `t`, dml.Rows{
+		{`id1`: 1, `id2`: `a`, `col_integer`: 2, `col_real`: 2.5, `col_blob`: []byte{1, 2}},
+		{`id1`: 2, `id2`: `a`, `col_real`: 1.5, `col_blob`: `AA==`},
+		{`id1`: 1, `id2`: `b`, `col_integer`: true},
+	})
+}
+
+type fakeTB struct {
+	testing.TB
+	errors []string
+	fatals []string
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Error(args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprint(args...))
+}
+
+func (tb *fakeTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func (tb *fakeTB) Fatalf(format string, args ...any) {
+	tb.fatals = append(tb.fatals, fmt.Sprintf(format, args...))
+}
+
+func TestAssertTableRows_Mismatch(t *testing.T) {
+	db, tearDown := test.Setup(t, "", "")
+	defer tearDown()
+
+	test.Init(t, db, []test.Statement{{
+		SQL: `
+CREATE TABLE t (
+	id INT,
+	name TEXT,
+	PRIMARY KEY (id));
+
+INSERT INTO t (id, name) VALUES (1, "a"), (2, "b"), (4, "d");
+`}})
+
+	tb := &fakeTB{}
+	test.AssertTableRows(tb, db, `t`, dml.Rows{
+		{`id`: 1, `name`: `a`},
+		{`id`: 2, `name`: `B`},
+		{`id`: 3, `name`: `c`},
+	})
+
+	if len(tb.fatals) != 0 {
+		t.Fatalf(`unexpected fatal: %v`, tb.fatals)
+	}
+	if len(tb.errors) != 1 {
+		t.Fatalf(`mismatch not reported: %v`, tb.errors)
+	}
+	want := strings.Join([]string{
+		`ASSERT TABLE ROWS t`,
+		`  missing row: {"id":3,"name":"c"}`,
+		`  unexpected row: {"id":4,"name":"d"}`,
+		`  changed row: {"id":2}`,
+		`    name: expect "B", actual "b"`,
+	}, "\n")
+	if tb.errors[0] != want {
+		t.Errorf("message not match\n  got  = %s\n  want = %s", tb.errors[0], want)
+	}
+}
+
+func TestAssertTableRows_UnknownColumn(t *testing.T) {
+	db, tearDown := test.Setup(t, "", "")
+	defer tearDown()
+
+	test.Init(t, db, []test.Statement{{SQL: `CREATE TABLE t (id INT, PRIMARY KEY (id));`}})
+
+	tb := &fakeTB{}
+	test.AssertTableRows(tb, db, `t`, dml.Rows{{`id`: 1, `unknown`: 1}})
+
+	if len(tb.fatals) != 1 {
+		t.Errorf(`unknown column not reported: %v`, tb.fatals)
+	}
+}
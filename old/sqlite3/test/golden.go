@@ -0,0 +1,32 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/dbsql"
+	"github.com/Jumpaku/gotaface/old/dml"
+	sqlite3_schema "github.com/Jumpaku/gotaface/old/sqlite3/ddl/schema"
+	"github.com/Jumpaku/gotaface/old/test/golden"
+)
+
+// AssertGolden asserts that rows in the tables are equal to those in the golden file at path, e.g., testdata/users.golden.json.
+// The rows are written to the golden file as a JSON object mapping table names to rows ordered by the primary key, and the golden file is regenerated if golden.Update reports true.
+func AssertGolden(t testing.TB, queryer dbsql.Queryer, path string, tables []string) {
+	t.Helper()
+
+	schema, err := sqlite3_schema.FetchSchema(context.Background(), queryer)
+	if err != nil {
+		t.Fatalf(`fail to fetch schema: %v`, err)
+	}
+
+	dumped := map[string]dml.Rows{}
+	for _, table := range tables {
+		dumped[table], err = dumpTable(queryer, schema, table)
+		if err != nil {
+			t.Fatalf(`fail to dump table %s: %v`, table, err)
+		}
+	}
+
+	golden.AssertJSON(t, path, dumped)
+}
@@ -0,0 +1,33 @@
+package test_test
+
+import (
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/sqlite3/test"
+)
+
+func TestAssertGolden(t *testing.T) {
+	db, tearDown := test.Setup(t, "", "")
+	defer tearDown()
+
+	test.Init(t, db, []test.Statement{{
+		SQL: `
+CREATE TABLE t (
+	id1 INT,
+	id2 TEXT,
+	col_integer INTEGER,
+	col_real REAL,
+	col_blob BLOB,
+	PRIMARY KEY (id1, id2));
+
+CREATE TABLE u (id INT PRIMARY KEY);
+
+INSERT INTO t (id1, id2, col_integer, col_real, col_blob)
+VALUES
+	(2, "a", NULL, 1.5, X'00'),
+	(1, "b", 1, NULL, NULL),
+	(1, "a", 2, 2.5, X'0102');
+`}})
+
+	test.AssertGolden(t, db, `testdata/tables.golden.json`, []string{`t`, `u`})
+}
@@ -2,6 +2,7 @@ package sqlite3
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -82,6 +83,12 @@ func ToDBValue(columnType string, src any) (any, error) {
 			return sql.NullInt64{Valid: true, Int64: dst}, nil
 		case int, int8, int16, int32, uint, uint8, uint16, uint32, uint64:
 			return ToDBValue(columnType, mustInt64(src))
+		case bool:
+			// SQLite3 has no boolean type and stores booleans as integers 0 or 1.
+			if src {
+				return sql.NullInt64{Valid: true, Int64: 1}, nil
+			}
+			return sql.NullInt64{Valid: true, Int64: 0}, nil
 		default:
 			dst := &sql.NullInt64{}
 			if err := dst.Scan(src); err != nil {
@@ -90,6 +97,15 @@ func ToDBValue(columnType string, src any) (any, error) {
 			return *dst, nil
 		}
 	case strings.Contains(lower, "char"), strings.Contains(lower, "clob"), strings.Contains(lower, "text"):
+		switch src.(type) {
+		case map[string]any, []any:
+			// JSON objects and arrays are stored as JSON texts.
+			b, err := json.Marshal(src)
+			if err != nil {
+				return nil, fmt.Errorf(`fail to marshal %v to JSON: %w`, spew.Sdump(src), err)
+			}
+			return sql.NullString{Valid: true, String: string(b)}, nil
+		}
 		dst := &sql.NullString{}
 		if err := dst.Scan(src); err != nil {
 			return nil, fmt.Errorf(`fail to scan %v as NullString: %w`, spew.Sdump(src), err)
@@ -131,3 +147,21 @@ func ToDBValue(columnType string, src any) (any, error) {
 		}
 	}
 }
+
+// FromDBValue converts a value scanned from a column into a plain Go value, which can be encoded into JSON and converted back by ToDBValue.
+func FromDBValue(src any) (any, error) {
+	switch src := src.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return src, nil
+	case driver.Valuer:
+		dst, err := src.Value()
+		if err != nil {
+			return nil, fmt.Errorf(`fail to get value from %v: %w`, spew.Sdump(src), err)
+		}
+		return dst, nil
+	default:
+		return src, nil
+	}
+}
@@ -299,6 +299,14 @@ func TestToDBValue_INT(t *testing.T) {
 			typ:  "INT",
 			src:  (*uint64)(nil),
 			want: sql.NullInt64{},
+		}, {
+			typ:  "INT",
+			src:  true,
+			want: sql.NullInt64{Valid: true, Int64: 1},
+		}, {
+			typ:  "INT",
+			src:  false,
+			want: sql.NullInt64{Valid: true, Int64: 0},
 		},
 	}
 
@@ -415,6 +423,14 @@ func TestToDBValue_STRING(t *testing.T) {
 			typ:  "STRING(MAX)",
 			src:  (*string)(nil),
 			want: sql.NullString{},
+		}, {
+			typ:  "TEXT",
+			src:  map[string]any{"a": 1, "b": []any{"x", nil}},
+			want: sql.NullString{Valid: true, String: `{"a":1,"b":["x",null]}`},
+		}, {
+			typ:  "TEXT",
+			src:  []any{int64(1), int64(2)},
+			want: sql.NullString{Valid: true, String: `[1,2]`},
 		},
 	}
 
@@ -423,3 +439,32 @@ func TestToDBValue_STRING(t *testing.T) {
 		checkTestCase(t, i, got, err, testCase)
 	}
 }
+
+func TestFromDBValue(t *testing.T) {
+	type testCase struct {
+		src  any
+		want any
+	}
+	testCases := []testCase{
+		{src: nil, want: nil},
+		{src: sql.NullInt64{}, want: nil},
+		{src: sql.NullInt64{Valid: true, Int64: 123}, want: int64(123)},
+		{src: sql.NullFloat64{}, want: nil},
+		{src: sql.NullFloat64{Valid: true, Float64: -1.25}, want: float64(-1.25)},
+		{src: sql.NullString{}, want: nil},
+		{src: sql.NullString{Valid: true, String: "abc"}, want: "abc"},
+		{src: []byte(nil), want: []byte(nil)},
+		{src: []byte("abc"), want: []byte("abc")},
+	}
+
+	for i, testCase := range testCases {
+		got, err := sqlite3.FromDBValue(testCase.src)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %v", i, err)
+			continue
+		}
+		if !equals(got, testCase.want) {
+			t.Errorf("%d: got != want\n  got  = %#v\n  want = %#v", i, got, testCase.want)
+		}
+	}
+}
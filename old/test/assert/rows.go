@@ -0,0 +1,113 @@
+package assert
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/ddl/schema"
+	"github.com/Jumpaku/gotaface/old/dml"
+	"github.com/Jumpaku/gotaface/old/dml/diff"
+	"golang.org/x/exp/slices"
+)
+
+// ToDBValueFunc converts src into a value of the column type in a database.
+type ToDBValueFunc func(columnType string, src any) (any, error)
+
+// FromDBValueFunc converts src, which is a value in a database, into a plain Go value.
+type FromDBValueFunc func(src any) (any, error)
+
+// SchemaTableRows asserts that the actual rows in the table in the schema are equal to the expected rows, where rows are matched by the values in the primary key of the table.
+// The actual values must be plain Go values, and the expected values are converted by toDBValue with the column types in the schema and then by fromDBValue.
+// Columns missing in the expected rows are expected to be NULL.
+func SchemaTableRows(t testing.TB, s schema.Schema, table string, toDBValue ToDBValueFunc, fromDBValue FromDBValueFunc, actual dml.Rows, expect dml.Rows) {
+	t.Helper()
+
+	index := slices.IndexFunc(s.Tables(), func(t schema.Table) bool { return t.Name() == table })
+	if index < 0 {
+		t.Fatalf(`table %s not found`, table)
+		return
+	}
+	tableSchema := s.Tables()[index]
+	columns := tableSchema.Columns()
+
+	normalized := dml.Rows{}
+	for i, expectRow := range expect {
+		row := dml.Row{}
+		for _, column := range columns {
+			value, err := toDBValue(column.Type(), expectRow[column.Name()])
+			if err != nil {
+				t.Fatalf(`fail to convert expected value in column %s of row %d: %v`, column.Name(), i, err)
+				return
+			}
+			row[column.Name()], err = fromDBValue(value)
+			if err != nil {
+				t.Fatalf(`fail to convert expected value in column %s of row %d: %v`, column.Name(), i, err)
+				return
+			}
+		}
+		for column := range expectRow {
+			if _, ok := row[column]; !ok {
+				t.Fatalf(`column %s in expected row %d not found in table %s`, column, i, table)
+				return
+			}
+		}
+		normalized = append(normalized, row)
+	}
+
+	primaryKey := []string{}
+	for _, index := range tableSchema.PrimaryKey() {
+		primaryKey = append(primaryKey, columns[index].Name())
+	}
+
+	TableRows(t, table, primaryKey, actual, normalized)
+}
+
+// TableRows asserts that the rows in the table are equal to the expected rows regardless of the order, where rows are matched by the values in the primary key.
+// The values must be plain Go values such as those returned by FromDBValue of each driver.
+func TableRows(t testing.TB, table string, primaryKey []string, actual dml.Rows, expect dml.Rows) {
+	t.Helper()
+
+	expect, err := diff.SortRows(primaryKey, expect)
+	if err != nil {
+		t.Errorf("ASSERT TABLE ROWS %s\n  fail to sort expected rows: %v", table, err)
+		return
+	}
+	actual, err = diff.SortRows(primaryKey, actual)
+	if err != nil {
+		t.Errorf("ASSERT TABLE ROWS %s\n  fail to sort actual rows: %v", table, err)
+		return
+	}
+	d, err := diff.Diff(primaryKey, expect, actual)
+	if err != nil {
+		t.Errorf("ASSERT TABLE ROWS %s\n  fail to compare rows: %v", table, err)
+		return
+	}
+	if d.Empty() {
+		return
+	}
+
+	message := []string{fmt.Sprintf(`ASSERT TABLE ROWS %s`, table)}
+	for _, row := range d.Deleted {
+		message = append(message, fmt.Sprintf(`  missing row: %s`, jsonString(row)))
+	}
+	for _, row := range d.Inserted {
+		message = append(message, fmt.Sprintf(`  unexpected row: %s`, jsonString(row)))
+	}
+	for _, changed := range d.Changed {
+		message = append(message, fmt.Sprintf(`  changed row: %s`, jsonString(changed.Key)))
+		for _, column := range changed.Columns {
+			message = append(message, fmt.Sprintf(`    %s: expect %s, actual %s`, column.Column, jsonString(column.Left), jsonString(column.Right)))
+		}
+	}
+	t.Error(strings.Join(message, "\n"))
+}
+
+func jsonString(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf(`%v`, v)
+	}
+	return string(b)
+}
@@ -0,0 +1,107 @@
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// EnvUpdateGolden is the name of the environment variable to update golden files, e.g., `GOTAFACE_UPDATE_GOLDEN=true go test ./...`.
+const EnvUpdateGolden = "GOTAFACE_UPDATE_GOLDEN"
+
+// Update reports whether golden files should be updated.
+// It is true if the environment variable EnvUpdateGolden is true, or if the test binary defines an -update flag which is set to true.
+// This package does not define the -update flag so that test packages can define their own.
+func Update() bool {
+	if update, err := strconv.ParseBool(os.Getenv(EnvUpdateGolden)); err == nil && update {
+		return true
+	}
+	if f := flag.Lookup(`update`); f != nil {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			update, ok := getter.Get().(bool)
+			return ok && update
+		}
+	}
+	return false
+}
+
+// AssertJSON asserts that v encoded into pretty-printed JSON is equal to the content of the golden file at path.
+// If Update reports true, the golden file is created or overwritten with the JSON instead.
+// Keys of maps are sorted in the JSON, so v is encoded deterministically if the order of elements in its slices is deterministic.
+func AssertJSON(t testing.TB, path string, v any) {
+	t.Helper()
+
+	b, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		t.Fatalf(`fail to marshal JSON: %v`, err)
+		return
+	}
+	actual := append(b, '\n')
+
+	if Update() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf(`fail to create directory of golden file %s: %v`, path, err)
+			return
+		}
+		if err := os.WriteFile(path, actual, 0644); err != nil {
+			t.Fatalf(`fail to write golden file %s: %v`, path, err)
+		}
+		return
+	}
+
+	expect, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf(`fail to read golden file %s, which can be created with %s=true: %v`, path, EnvUpdateGolden, err)
+		return
+	}
+
+	if !bytes.Equal(actual, expect) {
+		t.Errorf("ASSERT GOLDEN %s\n%s", path, diffLines(string(expect), string(actual)))
+	}
+}
+
+// diffLines returns the lines removed from expect with prefix `-` and the lines added in actual with prefix `+` based on a longest common subsequence of the lines.
+func diffLines(expect string, actual string) string {
+	e := strings.Split(expect, "\n")
+	a := strings.Split(actual, "\n")
+
+	// lcs[i][j] is the length of a longest common subsequence of e[i:] and a[j:]
+	lcs := make([][]int, len(e)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(a)+1)
+	}
+	for i := len(e) - 1; i >= 0; i-- {
+		for j := len(a) - 1; j >= 0; j-- {
+			switch {
+			case e[i] == a[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	message := []string{}
+	i, j := 0, 0
+	for i < len(e) || j < len(a) {
+		switch {
+		case i < len(e) && j < len(a) && e[i] == a[j]:
+			i, j = i+1, j+1
+		case j == len(a) || (i < len(e) && lcs[i+1][j] >= lcs[i][j+1]):
+			message = append(message, fmt.Sprintf("  - line %d: %s", i+1, e[i]))
+			i++
+		default:
+			message = append(message, fmt.Sprintf("  + line %d: %s", j+1, a[j]))
+			j++
+		}
+	}
+	return strings.Join(message, "\n")
+}
@@ -0,0 +1,103 @@
+package golden_test
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/test/golden"
+)
+
+func TestAssertJSON(t *testing.T) {
+	golden.AssertJSON(t, `testdata/example.golden.json`, map[string]any{
+		"User": []map[string]any{
+			{"name": "Jumpaku", "id": 1, "icon": []byte{0, 1}},
+			{"name": nil, "id": 2, "icon": nil},
+		},
+		"Comment": []map[string]any{},
+	})
+}
+
+type fakeTB struct {
+	testing.TB
+	errors []string
+	fatals []string
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func (tb *fakeTB) Fatalf(format string, args ...any) {
+	tb.fatals = append(tb.fatals, fmt.Sprintf(format, args...))
+}
+
+func TestAssertJSON_Mismatch(t *testing.T) {
+	tb := &fakeTB{}
+	golden.AssertJSON(tb, `testdata/example.golden.json`, map[string]any{
+		"User": []map[string]any{
+			{"name": "Jumpaku", "id": 1, "icon": []byte{0, 1}},
+			{"name": "Inserted", "id": 3, "icon": nil},
+			{"name": nil, "id": 2, "icon": nil},
+		},
+		"Comment": []map[string]any{},
+	})
+
+	if len(tb.fatals) != 0 {
+		t.Fatalf(`unexpected fatal: %v`, tb.fatals)
+	}
+	if len(tb.errors) != 1 {
+		t.Fatalf(`mismatch not reported: %v`, tb.errors)
+	}
+	want := strings.Join([]string{
+		`ASSERT GOLDEN testdata/example.golden.json`,
+		`  + line 11:             "id": 3,`,
+		`  + line 12:             "name": "Inserted"`,
+		`  + line 13:         },`,
+		`  + line 14:         {`,
+		`  + line 15:             "icon": null,`,
+	}, "\n")
+	if tb.errors[0] != want {
+		t.Errorf("got != want\n  got  = %s\n  want = %s", tb.errors[0], want)
+	}
+}
+
+func TestAssertJSON_Missing(t *testing.T) {
+	tb := &fakeTB{}
+	golden.AssertJSON(tb, `testdata/missing.golden.json`, map[string]any{})
+
+	if len(tb.fatals) != 1 || !strings.Contains(tb.fatals[0], golden.EnvUpdateGolden) {
+		t.Errorf(`missing golden file not reported: %v`, tb.fatals)
+	}
+}
+
+// update is defined by the test package itself, which must not conflict with the golden package.
+var update = flag.Bool(`update`, false, `update golden files`)
+
+func TestUpdate(t *testing.T) {
+	defer flag.Set(`update`, strconv.FormatBool(*update))
+	t.Setenv(golden.EnvUpdateGolden, ``)
+
+	if err := flag.Set(`update`, `true`); err != nil {
+		t.Fatalf(`fail to set flag: %v`, err)
+	}
+	if !golden.Update() {
+		t.Errorf(`-update flag of the test package is not respected`)
+	}
+
+	if err := flag.Set(`update`, `false`); err != nil {
+		t.Fatalf(`fail to set flag: %v`, err)
+	}
+	if golden.Update() {
+		t.Errorf(`golden files must not be updated without -update flag`)
+	}
+
+	t.Setenv(golden.EnvUpdateGolden, `true`)
+	if !golden.Update() {
+		t.Errorf(`environment variable %s is not respected`, golden.EnvUpdateGolden)
+	}
+}
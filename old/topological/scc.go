@@ -0,0 +1,135 @@
+package topological
+
+import (
+	"github.com/Jumpaku/gotaface/old/errors"
+	"golang.org/x/exp/slices"
+)
+
+// StronglyConnectedComponents returns the strongly connected components of a directed graph represented by the adjacency list using Tarjan's algorithm.
+// Each component is a sorted list of vertices, and the components are ordered such that each component precedes the components which reach it.
+//
+// Example:
+// components := topological.StronglyConnectedComponents([][]int{{1}, {0, 2}, {}})
+// println(components) // [][]int{{2}, {0, 1}}
+func StronglyConnectedComponents(graph [][]int) [][]int {
+	n := len(graph)
+	index := make([]int, n)
+	lowLink := make([]int, n)
+	onStack := make([]bool, n)
+	for u := range index {
+		index[u] = -1
+	}
+
+	components := [][]int{}
+	stack := []int{}
+	next := 0
+
+	var strongConnect func(u int)
+	strongConnect = func(u int) {
+		index[u] = next
+		lowLink[u] = next
+		next++
+		stack = append(stack, u)
+		onStack[u] = true
+
+		for _, v := range graph[u] {
+			if index[v] < 0 {
+				strongConnect(v)
+				lowLink[u] = min(lowLink[u], lowLink[v])
+			} else if onStack[v] {
+				lowLink[u] = min(lowLink[u], index[v])
+			}
+		}
+
+		if lowLink[u] == index[u] {
+			component := []int{}
+			for {
+				v := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[v] = false
+				component = append(component, v)
+				if v == u {
+					break
+				}
+			}
+			slices.Sort(component)
+			components = append(components, component)
+		}
+	}
+
+	for u := 0; u < n; u++ {
+		if index[u] < 0 {
+			strongConnect(u)
+		}
+	}
+
+	return components
+}
+
+// FindCycle returns a cycle in a directed graph represented by the adjacency list as a path of vertices starting and ending at the same vertex.
+// The returned cycle is a shortest cycle through the smallest vertex which is on any cycle.
+// If the graph has no cycles, this function returns (nil, false).
+//
+// Example:
+// cycle, ok := topological.FindCycle([][]int{{1}, {2}, {0}, {}})
+// println(cycle, ok) // []int{0, 1, 2, 0} true
+func FindCycle(graph [][]int) ([]int, bool) {
+	cyclic := CyclicComponents(graph)
+	if len(cyclic) == 0 {
+		return nil, false
+	}
+
+	// breadth first search from start back to start within the component
+	start := cyclic[0][0]
+	inComponent := make([]bool, len(graph))
+	for _, u := range cyclic[0] {
+		inComponent[u] = true
+	}
+	parent := make([]int, len(graph))
+	for u := range parent {
+		parent[u] = -1
+	}
+	queue := []int{start}
+	for queueHead := 0; queueHead < len(queue); queueHead++ {
+		u := queue[queueHead]
+		for _, v := range graph[u] {
+			if v == start {
+				reversed := []int{}
+				for w := u; w != start; w = parent[w] {
+					reversed = append(reversed, w)
+				}
+				cycle := []int{start}
+				for i := len(reversed) - 1; i >= 0; i-- {
+					cycle = append(cycle, reversed[i])
+				}
+				return append(cycle, start), true
+			}
+			if inComponent[v] && parent[v] < 0 {
+				parent[v] = u
+				queue = append(queue, v)
+			}
+		}
+	}
+
+	return errors.Unexpected2[[]int, bool](`cycle not found in strongly connected component %v`, cyclic[0])
+}
+
+// CyclicComponents returns the strongly connected components having cycles, which are the components with multiple vertices or with self-loops, ordered by their smallest vertices.
+func CyclicComponents(graph [][]int) [][]int {
+	cyclic := [][]int{}
+	for _, component := range StronglyConnectedComponents(graph) {
+		u := component[0]
+		if len(component) > 1 || slices.Contains(graph[u], u) {
+			cyclic = append(cyclic, component)
+		}
+	}
+	slices.SortFunc(cyclic, func(a, b []int) bool { return a[0] < b[0] })
+	return cyclic
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,91 @@
+package topological_test
+
+import (
+	"testing"
+
+	"github.com/Jumpaku/gotaface/old/test/assert"
+	"github.com/Jumpaku/gotaface/old/topological"
+	"golang.org/x/exp/slices"
+)
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	type TestCase struct {
+		graph [][]int
+		want  [][]int
+	}
+	testCases := []TestCase{
+		{
+			graph: [][]int{},
+			want:  [][]int{},
+		},
+		{
+			graph: [][]int{{5}, {3, 6}, {5, 7}, {0, 7}, {1, 2, 6}, {}, {7}, {0}},
+			want:  [][]int{{5}, {0}, {7}, {3}, {6}, {1}, {2}, {4}},
+		},
+		{
+			graph: [][]int{{1}, {0, 2}, {}},
+			want:  [][]int{{2}, {0, 1}},
+		},
+		{
+			graph: [][]int{{1}, {2}, {0, 3}, {4}, {3, 5}, {5}},
+			want:  [][]int{{5}, {3, 4}, {0, 1, 2}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		got := topological.StronglyConnectedComponents(testCase.graph)
+		if !slices.EqualFunc(got, testCase.want, slices.Equal[int]) {
+			t.Errorf("got != want\n  got  = %#v\n  want = %#v", got, testCase.want)
+		}
+	}
+}
+
+func TestCyclicComponents(t *testing.T) {
+	got := topological.CyclicComponents([][]int{{1}, {2}, {0, 3}, {4}, {3, 5}, {5}, {}})
+	want := [][]int{{0, 1, 2}, {3, 4}, {5}}
+	if !slices.EqualFunc(got, want, slices.Equal[int]) {
+		t.Errorf("got != want\n  got  = %#v\n  want = %#v", got, want)
+	}
+}
+
+func TestFindCycle(t *testing.T) {
+	type TestCase struct {
+		graph [][]int
+		want  []int
+		ok    bool
+	}
+	testCases := []TestCase{
+		{
+			graph: [][]int{{5}, {3, 6}, {5, 7}, {0, 7}, {1, 2, 6}, {}, {7}, {0}},
+			ok:    false,
+		},
+		{
+			graph: [][]int{{1}, {0}},
+			want:  []int{0, 1, 0},
+			ok:    true,
+		},
+		{
+			graph: [][]int{{}, {1}},
+			want:  []int{1, 1},
+			ok:    true,
+		},
+		{
+			graph: [][]int{{}, {2}, {3, 4}, {4}, {1}},
+			want:  []int{1, 2, 4, 1},
+			ok:    true,
+		},
+		{
+			graph: [][]int{{1}, {2}, {3}, {1}},
+			want:  []int{1, 2, 3, 1},
+			ok:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		got, ok := topological.FindCycle(testCase.graph)
+		assert.Equal(t, ok, testCase.ok)
+		if !slices.Equal(got, testCase.want) {
+			t.Errorf("got != want\n  got  = %#v\n  want = %#v", got, testCase.want)
+		}
+	}
+}
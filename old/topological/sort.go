@@ -9,6 +9,7 @@ import "golang.org/x/exp/slices"
 //
 // If a valid topological order exists, this function returns (orders for each vertex, true).
 // If a cycle is detected in the graph, this function returns (nil, false).
+// FindCycle and CyclicComponents report the vertices on the cycles in such a case.
 //
 // Example:
 // order, ok := topological.Sort([][]int{{5}, {3, 6}, {5, 7}, {0, 7}, {1, 2, 6}, {}, {7}, {0}})
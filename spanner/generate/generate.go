@@ -0,0 +1,417 @@
+package generate
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	spanner_schema "github.com/Jumpaku/gotaface/spanner/schema"
+	"github.com/samber/lo"
+)
+
+// maxAttempts is the maximum number of attempts to generate a row satisfying the constraints.
+const maxAttempts = 100
+
+// nullRatio is the probability of NULL in nullable columns.
+const nullRatio = 0.1
+
+// TableRows is rows in a table in the input format of gf-dbinsert.
+type TableRows struct {
+	Name string           `json:"name"`
+	Rows []map[string]any `json:"rows"`
+}
+
+type generator struct {
+	random *rand.Rand
+}
+
+func NewGenerator(random *rand.Rand) generator {
+	return generator{random: random}
+}
+
+// Generate returns n rows of random values for each table.
+// The values in the primary key are unique in each table, NOT NULL columns have non-NULL values, and the values in the foreign keys and the primary keys of interleaved tables reference generated rows in the parent and referenced tables.
+// If the primary key of a table consists of referencing columns, the referenced rows are chosen in the generated order so that the primary keys are not duplicated.
+// An error is returned if the number of possible primary keys of a table, e.g., 2 for a BOOL primary key, is less than n.
+// The returned tables are ordered so that every table follows its parent and referenced tables, therefore they can be inserted in that order.
+// The parent and referenced tables of every table must be included in tables.
+func (g generator) Generate(tables []spanner_schema.SchemaTable, n int) ([]TableRows, error) {
+	ordered, err := order(tables)
+	if err != nil {
+		return nil, fmt.Errorf(`fail to order tables: %w`, err)
+	}
+
+	tableMap := lo.KeyBy(tables, func(t spanner_schema.SchemaTable) string { return t.Name })
+
+	generated := map[string][]map[string]any{}
+	output := []TableRows{}
+	for _, table := range ordered {
+		if space := keySpace(table, tableMap, generated); space < n {
+			return nil, fmt.Errorf(`table %s can have only %d primary keys for %d rows`, table.Name, space, n)
+		}
+
+		rows := []map[string]any{}
+		keys := map[string]bool{}
+		for len(rows) < n {
+			row, err := g.generateUniqueRow(table, tableMap, generated, keys)
+			if err != nil {
+				return nil, fmt.Errorf(`fail to generate row %d in table %s: %w`, len(rows), table.Name, err)
+			}
+			rows = append(rows, row)
+			// rows generated so far can be referenced from the subsequent rows by self-referencing foreign keys.
+			generated[table.Name] = rows
+		}
+		output = append(output, TableRows{Name: table.Name, Rows: rows})
+	}
+
+	return output, nil
+}
+
+func (g generator) generateUniqueRow(table spanner_schema.SchemaTable, tableMap map[string]spanner_schema.SchemaTable, generated map[string][]map[string]any, keys map[string]bool) (map[string]any, error) {
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		row, err := g.generateRow(table, tableMap, generated, keys)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		key, err := primaryKeyOf(table, row)
+		if err != nil {
+			return nil, err
+		}
+		if keys[key] {
+			lastErr = fmt.Errorf(`primary key %s is duplicated`, key)
+			continue
+		}
+		keys[key] = true
+
+		return row, nil
+	}
+	return nil, fmt.Errorf(`fail to satisfy constraints in %d attempts: %w`, maxAttempts, lastErr)
+}
+
+func (g generator) generateRow(table spanner_schema.SchemaTable, tableMap map[string]spanner_schema.SchemaTable, generated map[string][]map[string]any, keys map[string]bool) (map[string]any, error) {
+	row := map[string]any{}
+	assigned := map[string]bool{}
+	refs := references(table, tableMap)
+	completing := completingReference(table, refs)
+	for index, ref := range refs {
+		candidates := lo.Filter(generated[ref.referencedTable], func(referencedRow map[string]any, _ int) bool {
+			for i, column := range ref.referencingKey {
+				if assigned[column] && row[column] != referencedRow[ref.referencedKey[i]] {
+					return false
+				}
+			}
+			return true
+		})
+		if index == completing {
+			// the primary key is determined by this reference, so that the first row resulting in an unused primary key is referenced.
+			var err error
+			candidates, err = firstUnusedCandidate(table, ref, row, candidates, keys)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(candidates) == 0 {
+			nullable := lo.EveryBy(ref.referencingKey, func(column string) bool {
+				return !assigned[column] && isNullable(table, column)
+			})
+			if !nullable {
+				return nil, fmt.Errorf(`no rows in %s can be referenced`, ref.referencedTable)
+			}
+			for _, column := range ref.referencingKey {
+				row[column] = nil
+				assigned[column] = true
+			}
+			continue
+		}
+
+		referencedRow := candidates[g.random.Intn(len(candidates))]
+		for i, column := range ref.referencingKey {
+			row[column] = referencedRow[ref.referencedKey[i]]
+			assigned[column] = true
+		}
+	}
+
+	for _, column := range table.Columns {
+		if assigned[column.Name] {
+			continue
+		}
+		if column.Nullable && !lo.Contains(table.PrimaryKey, column.Name) && g.random.Float64() < nullRatio {
+			row[column.Name] = nil
+			continue
+		}
+		value, err := g.generateValue(column.Type)
+		if err != nil {
+			return nil, fmt.Errorf(`fail to generate value of column %s: %w`, column.Name, err)
+		}
+		row[column.Name] = value
+	}
+
+	return row, nil
+}
+
+func (g generator) generateValue(columnType string) (any, error) {
+	upper := strings.ToUpper(columnType)
+	switch {
+	default:
+		return nil, fmt.Errorf(`unsupported column type: %s`, columnType)
+	case strings.HasPrefix(upper, "ARRAY<"):
+		inner := strings.TrimSuffix(strings.TrimPrefix(upper, "ARRAY<"), ">")
+		array := []any{}
+		for i := g.random.Intn(4); i > 0; i-- {
+			value, err := g.generateValue(inner)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		return array, nil
+	case upper == "INT64":
+		return g.random.Int63(), nil
+	case upper == "FLOAT64":
+		return g.random.NormFloat64() * 1000, nil
+	case upper == "BOOL":
+		return g.random.Intn(2) == 1, nil
+	case upper == "NUMERIC":
+		return (&big.Rat{}).SetFrac64(g.random.Int63n(2_000_000_000_000)-1_000_000_000_000, 1_000_000_000).FloatString(9), nil
+	case upper == "TIMESTAMP":
+		return time.Unix(g.random.Int63n(4_102_444_800), g.random.Int63n(1_000_000_000)).UTC().Format(time.RFC3339Nano), nil
+	case upper == "DATE":
+		return time.Unix(g.random.Int63n(4_102_444_800), 0).UTC().Format(time.DateOnly), nil
+	case upper == "JSON":
+		return map[string]any{"value": g.randomString(8)}, nil
+	case strings.HasPrefix(upper, "STRING"):
+		length, err := maxLength(upper, 16)
+		if err != nil {
+			return nil, err
+		}
+		return g.randomString(length), nil
+	case strings.HasPrefix(upper, "BYTES"):
+		length, err := maxLength(upper, 16)
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, length)
+		g.random.Read(b)
+		return base64.StdEncoding.EncodeToString(b), nil
+	}
+}
+
+const alphanumeric = `abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789`
+
+func (g generator) randomString(length int) string {
+	b := make([]byte, length)
+	for i := range b {
+		b[i] = alphanumeric[g.random.Intn(len(alphanumeric))]
+	}
+	return string(b)
+}
+
+// maxLength returns the length in STRING(length) or BYTES(length) not greater than limit.
+func maxLength(columnType string, limit int) (int, error) {
+	_, after, ok := strings.Cut(columnType, "(")
+	if !ok {
+		return limit, nil
+	}
+	length := strings.TrimSuffix(after, ")")
+	if length == "MAX" {
+		return limit, nil
+	}
+	n, err := strconv.Atoi(length)
+	if err != nil {
+		return 0, fmt.Errorf(`fail to parse length in %s: %w`, columnType, err)
+	}
+	return lo.Min([]int{n, limit}), nil
+}
+
+func primaryKeyOf(table spanner_schema.SchemaTable, row map[string]any) (string, error) {
+	b, err := json.Marshal(lo.Map(table.PrimaryKey, func(column string, _ int) any { return row[column] }))
+	if err != nil {
+		return "", fmt.Errorf(`fail to marshal primary key: %w`, err)
+	}
+	return string(b), nil
+}
+
+// completingReference returns the index of the reference which assigns the last unassigned primary key columns if all the primary key columns are assigned by references, otherwise -1.
+func completingReference(table spanner_schema.SchemaTable, refs []reference) int {
+	assigned := map[string]bool{}
+	for index, ref := range refs {
+		for _, column := range ref.referencingKey {
+			assigned[column] = true
+		}
+		if lo.EveryBy(table.PrimaryKey, func(column string) bool { return assigned[column] }) {
+			return index
+		}
+	}
+	return -1
+}
+
+// firstUnusedCandidate returns the first one of the candidates of referenced rows which results in a primary key not in keys when referenced from row, or no candidates if there is not such one.
+func firstUnusedCandidate(table spanner_schema.SchemaTable, ref reference, row map[string]any, candidates []map[string]any, keys map[string]bool) ([]map[string]any, error) {
+	for _, referencedRow := range candidates {
+		referencingRow := lo.Assign(row)
+		for i, column := range ref.referencingKey {
+			referencingRow[column] = referencedRow[ref.referencedKey[i]]
+		}
+		key, err := primaryKeyOf(table, referencingRow)
+		if err != nil {
+			return nil, err
+		}
+		if !keys[key] {
+			return []map[string]any{referencedRow}, nil
+		}
+	}
+	return nil, nil
+}
+
+// keySpace returns the upper bound of the number of primary keys which can be generated in the table, where math.MaxInt means unlimited.
+// The primary key columns assigned by a reference can take at most as many values as the generated rows in the referenced table.
+func keySpace(table spanner_schema.SchemaTable, tableMap map[string]spanner_schema.SchemaTable, generated map[string][]map[string]any) int {
+	space := 1
+	assigned := map[string]bool{}
+	for _, ref := range references(table, tableMap) {
+		columns := lo.Filter(ref.referencingKey, func(column string, _ int) bool {
+			return lo.Contains(table.PrimaryKey, column) && !assigned[column]
+		})
+		if len(columns) == 0 || ref.referencedTable == table.Name {
+			continue
+		}
+		for _, column := range columns {
+			assigned[column] = true
+		}
+		space = multiplyCapped(space, len(generated[ref.referencedTable]))
+	}
+
+	for _, column := range table.Columns {
+		if lo.Contains(table.PrimaryKey, column.Name) && !assigned[column.Name] {
+			space = multiplyCapped(space, valueSpace(column.Type))
+		}
+	}
+	return space
+}
+
+// valueSpace returns the number of values which can be generated for the column type, where math.MaxInt means unlimited.
+func valueSpace(columnType string) int {
+	upper := strings.ToUpper(columnType)
+	switch {
+	default:
+		return math.MaxInt
+	case upper == "BOOL":
+		return 2
+	case upper == "DATE":
+		return 4_102_444_800 / 86_400
+	case strings.HasPrefix(upper, "STRING"):
+		length, err := maxLength(upper, 16)
+		if err != nil {
+			return math.MaxInt
+		}
+		return powerCapped(len(alphanumeric), length)
+	case strings.HasPrefix(upper, "BYTES"):
+		length, err := maxLength(upper, 16)
+		if err != nil {
+			return math.MaxInt
+		}
+		return powerCapped(256, length)
+	}
+}
+
+func multiplyCapped(a, b int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	if a > math.MaxInt/b {
+		return math.MaxInt
+	}
+	return a * b
+}
+
+func powerCapped(base, exponent int) int {
+	power := 1
+	for i := 0; i < exponent; i++ {
+		power = multiplyCapped(power, base)
+	}
+	return power
+}
+
+func isNullable(table spanner_schema.SchemaTable, column string) bool {
+	c, ok := lo.Find(table.Columns, func(c spanner_schema.SchemaColumn) bool { return c.Name == column })
+	return ok && c.Nullable
+}
+
+type reference struct {
+	referencedTable string
+	referencedKey   []string
+	referencingKey  []string
+}
+
+func references(table spanner_schema.SchemaTable, tableMap map[string]spanner_schema.SchemaTable) []reference {
+	references := []reference{}
+	if table.Parent != "" {
+		// primary key of an interleaved table is prefixed with the primary key columns of the parent table.
+		parentKey := tableMap[table.Parent].PrimaryKey
+		references = append(references, reference{referencedTable: table.Parent, referencedKey: parentKey, referencingKey: parentKey})
+	}
+	for _, fk := range table.ForeignKeys {
+		references = append(references, reference{referencedTable: fk.ReferencedTable, referencedKey: fk.ReferencedKey, referencingKey: fk.ReferencingKey})
+	}
+	return references
+}
+
+// order returns tables ordered so that every table follows its parent and referenced tables except itself.
+func order(tables []spanner_schema.SchemaTable) ([]spanner_schema.SchemaTable, error) {
+	tableMap := lo.KeyBy(tables, func(t spanner_schema.SchemaTable) string { return t.Name })
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	ordered := []spanner_schema.SchemaTable{}
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf(`references between tables have a cycle including %s`, name)
+		}
+		state[name] = visiting
+
+		table := tableMap[name]
+		referenced := lo.Map(table.ForeignKeys, func(fk spanner_schema.SchemaForeignKey, _ int) string { return fk.ReferencedTable })
+		if table.Parent != "" {
+			referenced = append(referenced, table.Parent)
+		}
+		for _, r := range referenced {
+			if r == name {
+				continue
+			}
+			if _, ok := tableMap[r]; !ok {
+				return fmt.Errorf(`table %s referenced from %s is not included`, r, name)
+			}
+			if err := visit(r); err != nil {
+				return err
+			}
+		}
+
+		state[name] = visited
+		ordered = append(ordered, table)
+		return nil
+	}
+
+	for _, table := range tables {
+		if err := visit(table.Name); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
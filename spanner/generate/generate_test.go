@@ -0,0 +1,256 @@
+package generate_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/Jumpaku/gotaface/spanner/generate"
+	spanner_schema "github.com/Jumpaku/gotaface/spanner/schema"
+)
+
+var testTables = []spanner_schema.SchemaTable{
+	{
+		Name: "Comment",
+		Columns: []spanner_schema.SchemaColumn{
+			{Name: "CommentId", Type: "INT64"},
+			{Name: "UserId", Type: "INT64"},
+			{Name: "PostUserId", Type: "INT64"},
+			{Name: "PostId", Type: "STRING(8)"},
+			{Name: "ReplyTo", Type: "INT64", Nullable: true},
+			{Name: "Content", Type: "STRING(MAX)", Nullable: true},
+		},
+		PrimaryKey: []string{"CommentId"},
+		ForeignKeys: []spanner_schema.SchemaForeignKey{
+			{Name: "FK_Comment_User", ReferencedTable: "User", ReferencedKey: []string{"UserId"}, ReferencingKey: []string{"UserId"}},
+			{Name: "FK_Comment_Post", ReferencedTable: "Post", ReferencedKey: []string{"UserId", "PostId"}, ReferencingKey: []string{"PostUserId", "PostId"}},
+			{Name: "FK_Comment_Comment", ReferencedTable: "Comment", ReferencedKey: []string{"CommentId"}, ReferencingKey: []string{"ReplyTo"}},
+		},
+	},
+	{
+		Name: "Post",
+		Columns: []spanner_schema.SchemaColumn{
+			{Name: "UserId", Type: "INT64"},
+			{Name: "PostId", Type: "STRING(8)"},
+			{Name: "PostedAt", Type: "TIMESTAMP"},
+			{Name: "Tags", Type: "ARRAY<STRING(MAX)>", Nullable: true},
+		},
+		PrimaryKey: []string{"UserId", "PostId"},
+		Parent:     "User",
+	},
+	{
+		Name: "User",
+		Columns: []spanner_schema.SchemaColumn{
+			{Name: "UserId", Type: "INT64"},
+			{Name: "Name", Type: "STRING(16)"},
+			{Name: "Active", Type: "BOOL"},
+			{Name: "Score", Type: "FLOAT64"},
+			{Name: "Balance", Type: "NUMERIC"},
+			{Name: "Birthday", Type: "DATE"},
+			{Name: "Icon", Type: "BYTES(MAX)"},
+			{Name: "Profile", Type: "JSON"},
+		},
+		PrimaryKey: []string{"UserId"},
+	},
+}
+
+func keyOf(row map[string]any, columns ...string) string {
+	key := []any{}
+	for _, column := range columns {
+		key = append(key, row[column])
+	}
+	return fmt.Sprint(key...)
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	n := 20
+	sut := generate.NewGenerator(rand.New(rand.NewSource(1)))
+
+	got, err := sut.Generate(testTables, n)
+	if err != nil {
+		t.Fatalf(`fail to generate: %v`, err)
+	}
+
+	if len(got) != 3 || got[0].Name != "User" || got[1].Name != "Post" || got[2].Name != "Comment" {
+		t.Fatalf(`tables are not ordered: %v`, got)
+	}
+
+	keys := map[string]map[string]bool{}
+	primaryKeys := map[string][]string{"User": {"UserId"}, "Post": {"UserId", "PostId"}, "Comment": {"CommentId"}}
+	for _, table := range got {
+		if len(table.Rows) != n {
+			t.Errorf("row count in %s not match\n  got  = %v\n  want = %v", table.Name, len(table.Rows), n)
+		}
+		keys[table.Name] = map[string]bool{}
+		for _, row := range table.Rows {
+			key := keyOf(row, primaryKeys[table.Name]...)
+			if keys[table.Name][key] {
+				t.Errorf(`primary key %s is duplicated in %s`, key, table.Name)
+			}
+			keys[table.Name][key] = true
+		}
+	}
+
+	for _, row := range got[0].Rows {
+		for _, column := range []string{"UserId", "Name", "Active", "Score", "Balance", "Birthday", "Icon", "Profile"} {
+			if row[column] == nil {
+				t.Errorf(`NOT NULL column %s in User is null: %v`, column, row)
+			}
+		}
+		if name := row["Name"].(string); len(name) > 16 {
+			t.Errorf(`Name is too long: %v`, name)
+		}
+	}
+	for _, row := range got[1].Rows {
+		if !keys["User"][keyOf(row, "UserId")] {
+			t.Errorf(`Post does not reference parent User: %v`, row)
+		}
+	}
+	for _, row := range got[2].Rows {
+		if !keys["User"][keyOf(row, "UserId")] {
+			t.Errorf(`Comment does not reference User: %v`, row)
+		}
+		if !keys["Post"][keyOf(row, "PostUserId", "PostId")] {
+			t.Errorf(`Comment does not reference Post: %v`, row)
+		}
+		if row["ReplyTo"] != nil && !keys["Comment"][keyOf(row, "ReplyTo")] {
+			t.Errorf(`Comment does not reference Comment: %v`, row)
+		}
+	}
+
+	if _, err := json.Marshal(got); err != nil {
+		t.Errorf(`fail to marshal generated rows: %v`, err)
+	}
+}
+
+func TestGenerator_Generate_MissingTable(t *testing.T) {
+	sut := generate.NewGenerator(rand.New(rand.NewSource(1)))
+
+	_, err := sut.Generate(testTables[:2], 1)
+	if err == nil {
+		t.Errorf(`missing referenced table is not detected`)
+	}
+}
+
+func TestGenerator_Generate_Cycle(t *testing.T) {
+	sut := generate.NewGenerator(rand.New(rand.NewSource(1)))
+
+	_, err := sut.Generate([]spanner_schema.SchemaTable{
+		{
+			Name:        "A",
+			Columns:     []spanner_schema.SchemaColumn{{Name: "Id", Type: "INT64"}, {Name: "BId", Type: "INT64"}},
+			PrimaryKey:  []string{"Id"},
+			ForeignKeys: []spanner_schema.SchemaForeignKey{{ReferencedTable: "B", ReferencedKey: []string{"Id"}, ReferencingKey: []string{"BId"}}},
+		},
+		{
+			Name:        "B",
+			Columns:     []spanner_schema.SchemaColumn{{Name: "Id", Type: "INT64"}, {Name: "AId", Type: "INT64"}},
+			PrimaryKey:  []string{"Id"},
+			ForeignKeys: []spanner_schema.SchemaForeignKey{{ReferencedTable: "A", ReferencedKey: []string{"Id"}, ReferencingKey: []string{"AId"}}},
+		},
+	}, 1)
+	if err == nil {
+		t.Errorf(`cycle is not detected`)
+	}
+}
+
+func TestGenerator_Generate_OneToOne(t *testing.T) {
+	n := 500
+	sut := generate.NewGenerator(rand.New(rand.NewSource(1)))
+
+	got, err := sut.Generate([]spanner_schema.SchemaTable{
+		{
+			Name:       "User",
+			Columns:    []spanner_schema.SchemaColumn{{Name: "UserId", Type: "INT64"}},
+			PrimaryKey: []string{"UserId"},
+		},
+		{
+			Name:       "Profile",
+			Columns:    []spanner_schema.SchemaColumn{{Name: "UserId", Type: "INT64"}, {Name: "Bio", Type: "STRING(MAX)"}},
+			PrimaryKey: []string{"UserId"},
+			Parent:     "User",
+		},
+	}, n)
+	if err != nil {
+		t.Fatalf(`fail to generate: %v`, err)
+	}
+
+	users := map[string]bool{}
+	for _, row := range got[0].Rows {
+		users[keyOf(row, "UserId")] = true
+	}
+	profiles := map[string]bool{}
+	for _, row := range got[1].Rows {
+		key := keyOf(row, "UserId")
+		if !users[key] {
+			t.Errorf(`Profile does not reference parent User: %v`, row)
+		}
+		if profiles[key] {
+			t.Errorf(`primary key %s is duplicated in Profile`, key)
+		}
+		profiles[key] = true
+	}
+	if len(profiles) != n {
+		t.Errorf("row count in Profile not match\n  got  = %v\n  want = %v", len(profiles), n)
+	}
+}
+
+func TestGenerator_Generate_KeySpace(t *testing.T) {
+	testCases := []struct {
+		name   string
+		tables []spanner_schema.SchemaTable
+		n      int
+		isErr  bool
+	}{
+		{
+			name:   "BOOL",
+			tables: []spanner_schema.SchemaTable{{Name: "A", Columns: []spanner_schema.SchemaColumn{{Name: "Id", Type: "BOOL"}}, PrimaryKey: []string{"Id"}}},
+			n:      2,
+		},
+		{
+			name:   "BOOL exceeded",
+			tables: []spanner_schema.SchemaTable{{Name: "A", Columns: []spanner_schema.SchemaColumn{{Name: "Id", Type: "BOOL"}}, PrimaryKey: []string{"Id"}}},
+			n:      3,
+			isErr:  true,
+		},
+		{
+			name: "BOOL and STRING(1)",
+			tables: []spanner_schema.SchemaTable{{
+				Name:       "A",
+				Columns:    []spanner_schema.SchemaColumn{{Name: "Id1", Type: "BOOL"}, {Name: "Id2", Type: "STRING(1)"}},
+				PrimaryKey: []string{"Id1", "Id2"},
+			}},
+			n: 124,
+		},
+		{
+			name: "BOOL and STRING(1) exceeded",
+			tables: []spanner_schema.SchemaTable{{
+				Name:       "A",
+				Columns:    []spanner_schema.SchemaColumn{{Name: "Id1", Type: "BOOL"}, {Name: "Id2", Type: "STRING(1)"}},
+				PrimaryKey: []string{"Id1", "Id2"},
+			}},
+			n:     125,
+			isErr: true,
+		},
+		{
+			name: "parent key and BOOL",
+			tables: []spanner_schema.SchemaTable{
+				{Name: "A", Columns: []spanner_schema.SchemaColumn{{Name: "Id", Type: "INT64"}}, PrimaryKey: []string{"Id"}},
+				{Name: "B", Columns: []spanner_schema.SchemaColumn{{Name: "Id", Type: "INT64"}, {Name: "Flag", Type: "BOOL"}}, PrimaryKey: []string{"Id", "Flag"}, Parent: "A"},
+			},
+			n: 10,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			sut := generate.NewGenerator(rand.New(rand.NewSource(1)))
+
+			_, err := sut.Generate(testCase.tables, testCase.n)
+			if (err != nil) != testCase.isErr {
+				t.Errorf(`unexpected error state: %v`, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package subset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/go-assert"
+	"github.com/Jumpaku/gotaface/schema"
+	spanner_schema "github.com/Jumpaku/gotaface/spanner/schema"
+	"github.com/samber/lo"
+	sppb "google.golang.org/genproto/googleapis/spanner/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Seed specifies rows from which a subset is extracted.
+// Where is a boolean SQL expression to filter rows in Table, which may use query parameters given by Params.
+type Seed struct {
+	Table  string
+	Where  string
+	Params map[string]any
+}
+
+// Row is a row in Table, whose primary key columns are PrimaryKey.
+// Deferred is the columns referencing rows in a cycle, which must be NULL when the row is inserted and updated with their values after all the rows are inserted.
+type Row struct {
+	Table      string
+	PrimaryKey []string
+	Row        *spanner.Row
+	Deferred   []string
+}
+
+type Queryer interface {
+	Query(ctx context.Context, statement spanner.Statement) *spanner.RowIterator
+}
+
+// RowsQueryer returns rows in table queried by stmt.
+type RowsQueryer interface {
+	QueryRows(ctx context.Context, table string, stmt spanner.Statement) ([]*spanner.Row, error)
+}
+
+type rowsQueryer struct {
+	queryer Queryer
+}
+
+var _ RowsQueryer = rowsQueryer{}
+
+func (q rowsQueryer) QueryRows(ctx context.Context, table string, stmt spanner.Statement) ([]*spanner.Row, error) {
+	var rows []*spanner.Row
+	err := q.queryer.Query(ctx, stmt).Do(func(r *spanner.Row) error {
+		rows = append(rows, r)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(`fail to query rows in %s: %w`, table, err)
+	}
+	return rows, nil
+}
+
+type extractor struct {
+	queryer RowsQueryer
+	fetcher schema.Fetcher[spanner_schema.SchemaTable]
+}
+
+func NewExtractor(queryer Queryer) extractor {
+	return NewExtractorWith(rowsQueryer{queryer: queryer}, spanner_schema.NewFetcher(queryer))
+}
+
+// NewExtractorWith returns an extractor which queries rows by queryer and fetches schemas of tables by fetcher.
+func NewExtractorWith(queryer RowsQueryer, fetcher schema.Fetcher[spanner_schema.SchemaTable]) extractor {
+	return extractor{queryer: queryer, fetcher: fetcher}
+}
+
+// Extract returns the rows matching the seeds and all the rows referenced by them through interleaving parents and foreign keys transitively.
+// The returned rows are ordered so that every row follows the rows it references, therefore they can be inserted into an empty database in that order.
+// If rows reference each other in a cycle, the nullable foreign key columns of the row closing the cycle are deferred, and an error is returned if the cycle has no such columns.
+func (extractor extractor) Extract(ctx context.Context, seeds []Seed) ([]Row, error) {
+	e := &extraction{
+		queryer: extractor.queryer,
+		fetcher: extractor.fetcher,
+		tables:  map[string]spanner_schema.SchemaTable{},
+		state:   map[string]visitState{},
+	}
+	for _, seed := range seeds {
+		where := seed.Where
+		if where == "" {
+			where = "TRUE"
+		}
+		rows, err := e.queryer.QueryRows(ctx, seed.Table, spanner.Statement{
+			SQL:    fmt.Sprintf(`SELECT * FROM %s WHERE %s`, seed.Table, where),
+			Params: seed.Params,
+		})
+		if err != nil {
+			return nil, fmt.Errorf(`fail to query seed rows in %s: %w`, seed.Table, err)
+		}
+		for _, row := range rows {
+			if err := e.visit(ctx, seed.Table, row); err != nil {
+				return nil, fmt.Errorf(`fail to extract rows referenced from %s: %w`, seed.Table, err)
+			}
+		}
+	}
+	return e.extracted, nil
+}
+
+type visitState int
+
+const (
+	unvisited visitState = iota
+	// visiting is the state of a row whose referenced rows are being visited.
+	visiting
+	// visited is the state of a row which has been extracted.
+	visited
+)
+
+type extraction struct {
+	queryer   RowsQueryer
+	fetcher   schema.Fetcher[spanner_schema.SchemaTable]
+	tables    map[string]spanner_schema.SchemaTable
+	state     map[string]visitState
+	extracted []Row
+}
+
+// reference represents that referencingKey columns in a row reference referencedKey columns in referencedTable.
+// nullable is true if all the referencingKey columns are nullable foreign key columns, which can be deferred.
+type reference struct {
+	referencedTable string
+	referencedKey   []string
+	referencingKey  []string
+	nullable        bool
+}
+
+func (e *extraction) table(ctx context.Context, table string) (spanner_schema.SchemaTable, error) {
+	if schemaTable, ok := e.tables[table]; ok {
+		return schemaTable, nil
+	}
+	schemaTable, err := e.fetcher.Fetch(ctx, table)
+	if err != nil {
+		return spanner_schema.SchemaTable{}, fmt.Errorf(`fail to fetch schema of %s: %w`, table, err)
+	}
+	e.tables[table] = schemaTable
+	return schemaTable, nil
+}
+
+func (e *extraction) references(ctx context.Context, schemaTable spanner_schema.SchemaTable) ([]reference, error) {
+	nullable := map[string]bool{}
+	for _, column := range schemaTable.Columns {
+		nullable[column.Name] = column.Nullable
+	}
+	references := lo.Map(schemaTable.ForeignKeys, func(fk spanner_schema.SchemaForeignKey, _ int) reference {
+		return reference{
+			referencedTable: fk.ReferencedTable,
+			referencedKey:   fk.ReferencedKey,
+			referencingKey:  fk.ReferencingKey,
+			nullable:        lo.EveryBy(fk.ReferencingKey, func(column string) bool { return nullable[column] }),
+		}
+	})
+	if schemaTable.Parent != "" {
+		parent, err := e.table(ctx, schemaTable.Parent)
+		if err != nil {
+			return nil, err
+		}
+		// primary key of an interleaved table is prefixed with the primary key columns of the parent table.
+		references = append(references, reference{referencedTable: parent.Name, referencedKey: parent.PrimaryKey, referencingKey: parent.PrimaryKey})
+	}
+	return references, nil
+}
+
+func (e *extraction) visit(ctx context.Context, table string, row *spanner.Row) error {
+	schemaTable, err := e.table(ctx, table)
+	if err != nil {
+		return err
+	}
+
+	primaryKey, err := columnValues(row, schemaTable.PrimaryKey)
+	if err != nil {
+		return fmt.Errorf(`fail to get primary key of row in %s: %w`, table, err)
+	}
+	rowKey, err := keyString(table, primaryKey)
+	if err != nil {
+		return fmt.Errorf(`fail to identify row in %s: %w`, table, err)
+	}
+	if e.state[rowKey] != unvisited {
+		return nil
+	}
+	e.state[rowKey] = visiting
+
+	references, err := e.references(ctx, schemaTable)
+	if err != nil {
+		return err
+	}
+	deferred := []string{}
+	for _, ref := range references {
+		assert.State(len(ref.referencedKey) == len(ref.referencingKey), "referenced key and referencing key must have the same length")
+
+		values, err := columnValues(row, ref.referencingKey)
+		if err != nil {
+			return fmt.Errorf(`fail to get referencing key of row in %s: %w`, table, err)
+		}
+		if lo.SomeBy(values, isNull) {
+			continue
+		}
+
+		conditions := []string{}
+		params := map[string]any{}
+		for i, column := range ref.referencedKey {
+			conditions = append(conditions, fmt.Sprintf(`%s = @p%d`, column, i))
+			params[fmt.Sprintf(`p%d`, i)] = values[i]
+		}
+		referencedRows, err := e.queryer.QueryRows(ctx, ref.referencedTable, spanner.Statement{
+			SQL:    fmt.Sprintf(`SELECT * FROM %s WHERE %s`, ref.referencedTable, strings.Join(conditions, ` AND `)),
+			Params: params,
+		})
+		if err != nil {
+			return fmt.Errorf(`fail to query rows referenced from %s: %w`, table, err)
+		}
+		for _, referencedRow := range referencedRows {
+			cycle, err := e.visiting(ctx, ref.referencedTable, referencedRow)
+			if err != nil {
+				return err
+			}
+			if cycle {
+				if !ref.nullable {
+					return fmt.Errorf(`rows reference each other in a cycle through columns %v of row %s which cannot be deferred`, ref.referencingKey, rowKey)
+				}
+				deferred = append(deferred, lo.Without(ref.referencingKey, deferred...)...)
+				continue
+			}
+			if err := e.visit(ctx, ref.referencedTable, referencedRow); err != nil {
+				return err
+			}
+		}
+	}
+
+	e.state[rowKey] = visited
+	e.extracted = append(e.extracted, Row{Table: table, PrimaryKey: schemaTable.PrimaryKey, Row: row, Deferred: deferred})
+	return nil
+}
+
+// visiting returns true if the row is being visited, which means that the row is referenced through a cycle.
+func (e *extraction) visiting(ctx context.Context, table string, row *spanner.Row) (bool, error) {
+	schemaTable, err := e.table(ctx, table)
+	if err != nil {
+		return false, err
+	}
+	primaryKey, err := columnValues(row, schemaTable.PrimaryKey)
+	if err != nil {
+		return false, fmt.Errorf(`fail to get primary key of row in %s: %w`, table, err)
+	}
+	rowKey, err := keyString(table, primaryKey)
+	if err != nil {
+		return false, fmt.Errorf(`fail to identify row in %s: %w`, table, err)
+	}
+	return e.state[rowKey] == visiting, nil
+}
+
+func columnValues(row *spanner.Row, columns []string) ([]spanner.GenericColumnValue, error) {
+	values := []spanner.GenericColumnValue{}
+	for _, column := range columns {
+		var value spanner.GenericColumnValue
+		if err := row.ColumnByName(column, &value); err != nil {
+			return nil, fmt.Errorf(`fail to get value of column %s: %w`, column, err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func isNull(value spanner.GenericColumnValue) bool {
+	_, ok := value.Value.GetKind().(*structpb.Value_NullValue)
+	return ok
+}
+
+func keyString(table string, key []spanner.GenericColumnValue) (string, error) {
+	b, err := json.Marshal(lo.Map(key, func(v spanner.GenericColumnValue, _ int) any { return v.Value.AsInterface() }))
+	if err != nil {
+		return "", fmt.Errorf(`fail to marshal key: %w`, err)
+	}
+	return table + string(b), nil
+}
+
+// Mutations returns mutations to insert the rows in the order, followed by mutations to update the deferred columns of the rows.
+func Mutations(rows []Row) ([]*spanner.Mutation, error) {
+	mutations := []*spanner.Mutation{}
+	updates := []*spanner.Mutation{}
+	for _, row := range rows {
+		columns := row.Row.ColumnNames()
+		values, err := columnValues(row.Row, columns)
+		if err != nil {
+			return nil, fmt.Errorf(`fail to get values of row in %s: %w`, row.Table, err)
+		}
+		if len(row.Deferred) == 0 {
+			mutations = append(mutations, spanner.Insert(row.Table, columns, lo.ToAnySlice(values)))
+			continue
+		}
+
+		inserted := []any{}
+		for i, column := range columns {
+			value := values[i]
+			if lo.Contains(row.Deferred, column) {
+				value = spanner.GenericColumnValue{Type: value.Type, Value: structpb.NewNullValue()}
+			}
+			inserted = append(inserted, value)
+		}
+		mutations = append(mutations, spanner.Insert(row.Table, columns, inserted))
+
+		updatedColumns := append(append([]string{}, row.PrimaryKey...), row.Deferred...)
+		updated, err := columnValues(row.Row, updatedColumns)
+		if err != nil {
+			return nil, fmt.Errorf(`fail to get values of row in %s: %w`, row.Table, err)
+		}
+		updates = append(updates, spanner.Update(row.Table, updatedColumns, lo.ToAnySlice(updated)))
+	}
+	return append(mutations, updates...), nil
+}
+
+// Table is rows in a table in the input format of gf-dbinsert.
+type Table struct {
+	Name string           `json:"name"`
+	Rows []map[string]any `json:"rows"`
+}
+
+// Tables returns the rows grouped by table in the order of the first rows of the tables, which can be encoded into JSON in the input format of gf-dbinsert.
+// Values are converted into JSON values in the same way as the Spanner client library encodes them, except that INT64 values are converted into numbers.
+// The rows can be inserted into an empty database by gf-dbinsert with the option -order references, which defers foreign key columns referencing rows in a cycle.
+func Tables(rows []Row) ([]Table, error) {
+	tables := []Table{}
+	indices := map[string]int{}
+	for _, row := range rows {
+		index, ok := indices[row.Table]
+		if !ok {
+			index = len(tables)
+			indices[row.Table] = index
+			tables = append(tables, Table{Name: row.Table, Rows: []map[string]any{}})
+		}
+
+		columns := row.Row.ColumnNames()
+		values, err := columnValues(row.Row, columns)
+		if err != nil {
+			return nil, fmt.Errorf(`fail to get values of row in %s: %w`, row.Table, err)
+		}
+		jsonRow := map[string]any{}
+		for i, column := range columns {
+			jsonRow[column] = jsonValue(values[i].Type, values[i].Value)
+		}
+		tables[index].Rows = append(tables[index].Rows, jsonRow)
+	}
+	return tables, nil
+}
+
+func jsonValue(t *sppb.Type, v *structpb.Value) any {
+	switch kind := v.GetKind().(type) {
+	case *structpb.Value_StringValue:
+		if t.GetCode() == sppb.TypeCode_INT64 {
+			return json.Number(kind.StringValue)
+		}
+	case *structpb.Value_ListValue:
+		values := []any{}
+		for _, elm := range kind.ListValue.GetValues() {
+			values = append(values, jsonValue(t.GetArrayElementType(), elm))
+		}
+		return values
+	}
+	return v.AsInterface()
+}
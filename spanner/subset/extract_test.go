@@ -0,0 +1,120 @@
+package subset_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	spanner_schema "github.com/Jumpaku/gotaface/spanner/schema"
+	"github.com/Jumpaku/gotaface/spanner/subset"
+)
+
+type fakeFetcher map[string]spanner_schema.SchemaTable
+
+func (f fakeFetcher) Fetch(ctx context.Context, table string) (spanner_schema.SchemaTable, error) {
+	schemaTable, ok := f[table]
+	if !ok {
+		return spanner_schema.SchemaTable{}, fmt.Errorf(`table %s not found`, table)
+	}
+	return schemaTable, nil
+}
+
+// fakeQueryer returns rows in which the columns are equal to the parameters in conditions such as `A = @p0 AND B = @p1`.
+type fakeQueryer map[string][]*spanner.Row
+
+var conditionRegexp = regexp.MustCompile(`(\w+) = @(\w+)`)
+
+func (f fakeQueryer) QueryRows(ctx context.Context, table string, stmt spanner.Statement) ([]*spanner.Row, error) {
+	rows := []*spanner.Row{}
+	for _, row := range f[table] {
+		match := true
+		for _, condition := range conditionRegexp.FindAllStringSubmatch(stmt.SQL, -1) {
+			var value spanner.GenericColumnValue
+			if err := row.ColumnByName(condition[1], &value); err != nil {
+				return nil, err
+			}
+			param := stmt.Params[condition[2]]
+			if p, ok := param.(spanner.GenericColumnValue); ok {
+				param = p.Value.AsInterface()
+			}
+			match = match && fmt.Sprint(value.Value.AsInterface()) == fmt.Sprint(param)
+		}
+		if match {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func commentRow(id int64, replyTo spanner.NullInt64) *spanner.Row {
+	row, err := spanner.NewRow([]string{`CommentId`, `ReplyTo`}, []any{id, replyTo})
+	if err != nil {
+		panic(err)
+	}
+	return row
+}
+
+func commentSchema(nullable bool) fakeFetcher {
+	return fakeFetcher{`Comment`: {
+		Name:       `Comment`,
+		Columns:    []spanner_schema.SchemaColumn{{Name: `CommentId`, Type: `INT64`}, {Name: `ReplyTo`, Type: `INT64`, Nullable: nullable}},
+		PrimaryKey: []string{`CommentId`},
+		ForeignKeys: []spanner_schema.SchemaForeignKey{
+			{Name: `FK_Comment_Comment`, ReferencedTable: `Comment`, ReferencedKey: []string{`CommentId`}, ReferencingKey: []string{`ReplyTo`}},
+		},
+	}}
+}
+
+func TestExtractor_Extract_Cycle(t *testing.T) {
+	queryer := fakeQueryer{`Comment`: {
+		commentRow(1, spanner.NullInt64{Int64: 2, Valid: true}),
+		commentRow(2, spanner.NullInt64{Int64: 1, Valid: true}),
+		commentRow(3, spanner.NullInt64{}),
+	}}
+
+	// sut
+	got, err := subset.NewExtractorWith(queryer, commentSchema(true)).Extract(context.Background(), []subset.Seed{
+		{Table: `Comment`, Where: `CommentId = @id`, Params: map[string]any{`id`: 1}},
+	})
+	if err != nil {
+		t.Fatalf(`fail to extract subset: %v`, err)
+	}
+
+	tables, err := subset.Tables(got)
+	if err != nil {
+		t.Fatalf(`fail to convert rows: %v`, err)
+	}
+	gotJSON, _ := json.Marshal(tables)
+	wantJSON := `[{"name":"Comment","rows":[{"CommentId":2,"ReplyTo":1},{"CommentId":1,"ReplyTo":2}]}]`
+	if string(gotJSON) != wantJSON {
+		t.Errorf("tables not match\n  got  = %s\n  want = %s", gotJSON, wantJSON)
+	}
+	if len(got) != 2 || len(got[0].Deferred) != 1 || got[0].Deferred[0] != `ReplyTo` || len(got[1].Deferred) != 0 {
+		t.Errorf("only ReplyTo of the row closing the cycle must be deferred: %#v", got)
+	}
+
+	mutations, err := subset.Mutations(got)
+	if err != nil {
+		t.Fatalf(`fail to create mutations: %v`, err)
+	}
+	if len(mutations) != 3 {
+		t.Errorf("mutations must be two insertions followed by an update of the deferred column\n  got  = %v\n  want = %v", len(mutations), 3)
+	}
+}
+
+func TestExtractor_Extract_CycleNotNullable(t *testing.T) {
+	queryer := fakeQueryer{`Comment`: {
+		commentRow(1, spanner.NullInt64{Int64: 1, Valid: true}),
+	}}
+
+	// sut
+	_, err := subset.NewExtractorWith(queryer, commentSchema(false)).Extract(context.Background(), []subset.Seed{
+		{Table: `Comment`},
+	})
+	if err == nil {
+		t.Errorf(`cycle through columns which cannot be deferred is not detected`)
+	}
+}
@@ -0,0 +1,110 @@
+package subset_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/Jumpaku/gotaface/old/spanner/test"
+	"github.com/Jumpaku/gotaface/spanner/subset"
+)
+
+func TestExtractor_Extract(t *testing.T) {
+	env := test.GetEnvSpanner()
+	database := fmt.Sprintf(`subset_%d`, time.Now().UnixNano())
+	fullDatabase := fmt.Sprintf(`projects/%s/instances/%s/databases/%s`, env.Project, env.Instance, database)
+
+	adminClient, client, tearDown := test.Setup(t, database)
+	defer tearDown()
+
+	test.InitDDL(t, adminClient, fullDatabase, []string{`
+CREATE TABLE User (
+	UserId INT64,
+	Name STRING(MAX),
+) PRIMARY KEY (UserId)`, `
+CREATE TABLE Post (
+	UserId INT64,
+	PostId INT64,
+) PRIMARY KEY (UserId, PostId),
+	INTERLEAVE IN PARENT User`, `
+CREATE TABLE Comment (
+	CommentId INT64,
+	UserId INT64,
+	PostUserId INT64,
+	PostId INT64,
+	ReplyTo INT64,
+	CONSTRAINT FK_Comment_User FOREIGN KEY (UserId) REFERENCES User (UserId),
+	CONSTRAINT FK_Comment_Post FOREIGN KEY (PostUserId, PostId) REFERENCES Post (UserId, PostId),
+	CONSTRAINT FK_Comment_Comment FOREIGN KEY (ReplyTo) REFERENCES Comment (CommentId),
+) PRIMARY KEY (CommentId)`,
+	})
+	test.InitDML(t, client, []spanner.Statement{
+		{SQL: `INSERT INTO User (UserId, Name) VALUES (1, 'a'), (2, 'b'), (3, 'c'), (4, 'd')`},
+		{SQL: `INSERT INTO Post (UserId, PostId) VALUES (1, 1), (1, 2), (2, 1)`},
+		{SQL: `INSERT INTO Comment (CommentId, UserId, PostUserId, PostId, ReplyTo) VALUES (1, 3, 1, 2, NULL), (2, 2, 1, 2, 1), (3, 4, 2, 1, NULL)`},
+	})
+
+	tx := client.ReadOnlyTransaction()
+	defer tx.Close()
+
+	// sut
+	got, err := subset.NewExtractor(tx).Extract(context.Background(), []subset.Seed{
+		{Table: `Comment`, Where: `CommentId = @id`, Params: map[string]any{`id`: 2}},
+	})
+	if err != nil {
+		t.Fatalf(`fail to extract subset: %v`, err)
+	}
+
+	want := map[string]bool{
+		`Comment[1]`: true, `Comment[2]`: true,
+		`User[1]`: true, `User[2]`: true, `User[3]`: true,
+		`Post[1 2]`: true,
+	}
+	if len(got) != len(want) {
+		t.Errorf("row count not match\n  got  = %v\n  want = %v", len(got), len(want))
+	}
+
+	position := map[string]int{}
+	for i, row := range got {
+		var key []int64
+		switch row.Table {
+		case `User`, `Comment`:
+			var id int64
+			if err := row.Row.Column(0, &id); err != nil {
+				t.Fatalf(`fail to get column: %v`, err)
+			}
+			key = []int64{id}
+		case `Post`:
+			var userId, postId int64
+			if err := row.Row.Columns(&userId, &postId); err != nil {
+				t.Fatalf(`fail to get column: %v`, err)
+			}
+			key = []int64{userId, postId}
+		}
+		rowKey := fmt.Sprintf(`%s%v`, row.Table, key)
+		if !want[rowKey] {
+			t.Errorf("unexpected row %s", rowKey)
+		}
+		position[rowKey] = i
+	}
+
+	mustPrecede := [][2]string{
+		{`User[1]`, `Post[1 2]`},
+		{`User[3]`, `Comment[1]`},
+		{`Post[1 2]`, `Comment[1]`},
+		{`User[2]`, `Comment[2]`},
+		{`Post[1 2]`, `Comment[2]`},
+		{`Comment[1]`, `Comment[2]`},
+	}
+	for _, p := range mustPrecede {
+		if position[p[0]] >= position[p[1]] {
+			t.Errorf("%s must precede %s: %v", p[0], p[1], position)
+		}
+	}
+
+	if _, err := subset.Mutations(got); err != nil {
+		t.Errorf(`fail to create mutations: %v`, err)
+	}
+}